@@ -1,6 +1,9 @@
 package main
 
 import (
+	"bufio"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/analogj/go-util/utils"
 	"github.com/packagrio/bumpr/pkg"
@@ -9,6 +12,9 @@ import (
 	"github.com/urfave/cli"
 	"log"
 	"os"
+	"path"
+	"strings"
+	"text/tabwriter"
 	"time"
 )
 
@@ -35,7 +41,8 @@ func main() {
 
 			subtitle := packagrUrl + utils.LeftPad2Len(versionInfo, " ", 53-len(packagrUrl))
 
-			fmt.Fprintf(c.App.Writer, fmt.Sprintf(utils.StripIndent(
+			// the banner is written to stderr, so that stdout only contains the result (eg. `--output json`)
+			fmt.Fprintf(os.Stderr, fmt.Sprintf(utils.StripIndent(
 				`
 			 ____   __    ___  __ _   __    ___  ____ 
 			(  _ \ / _\  / __)(  / ) / _\  / __)(  _ \
@@ -61,17 +68,40 @@ func main() {
 						configuration.Set(config.PACKAGR_PACKAGE_TYPE, c.String("package_type"))
 					}
 
-					//config.Set("dry_run", c.String("dry_run"))
+					if c.IsSet("dry_run") {
+						configuration.Set(config.PACKAGR_DRY_RUN, c.Bool("dry_run"))
+					}
+					if c.IsSet("sync") {
+						configuration.Set(config.PACKAGR_PACKAGES_SYNC, c.Bool("sync"))
+					}
+					if c.IsSet("git_commit") {
+						configuration.Set(config.PACKAGR_GIT_COMMIT, c.Bool("git_commit"))
+					}
+					if c.IsSet("git_tag") {
+						configuration.Set(config.PACKAGR_GIT_TAG, c.Bool("git_tag"))
+					}
 
-					fmt.Println("package type:", configuration.GetString(config.PACKAGR_PACKAGE_TYPE))
-					fmt.Println("scm:", configuration.GetString(config.PACKAGR_SCM))
-					fmt.Println("bump type:", configuration.GetString(config.PACKAGR_VERSION_BUMP_TYPE))
+					if c.IsSet("report") {
+						configuration.Set(config.PACKAGR_REPORT_PATH, c.String("report"))
+					}
+					if c.IsSet("output") {
+						configuration.Set(config.PACKAGR_OUTPUT, c.String("output"))
+					}
+
+					// in json mode stdout only contains the JSON report
+					infoWriter := os.Stdout
+					if configuration.GetString(config.PACKAGR_OUTPUT) == "json" {
+						infoWriter = os.Stderr
+					}
+					fmt.Fprintln(infoWriter, "package type:", configuration.GetString(config.PACKAGR_PACKAGE_TYPE))
+					fmt.Fprintln(infoWriter, "scm:", configuration.GetString(config.PACKAGR_SCM))
+					fmt.Fprintln(infoWriter, "bump type:", configuration.GetString(config.PACKAGR_VERSION_BUMP_TYPE))
 
 					pipeline := pkg.Pipeline{}
 					err := pipeline.Start(configuration)
 					if err != nil {
-						fmt.Printf("FATAL: %+v\n", err)
-						os.Exit(1)
+						fmt.Fprintf(infoWriter, "FATAL: %+v\n", err)
+						os.Exit(exitCode(err))
 					}
 
 					return nil
@@ -94,15 +124,125 @@ func main() {
 					&cli.StringFlag{
 						Name:  "package_type",
 						Value: "generic",
-						Usage: "The type of package being built.",
+						Usage: "The type of package being built, or `auto` to detect it from the repository contents.",
 					},
 
 					&cli.BoolFlag{
 						Name:  "dry_run",
 						Usage: "When dry run is enabled, no data is written to file system",
 					},
+
+					&cli.BoolFlag{
+						Name:  "sync",
+						Usage: "In lockstep mode, realign packages with different versions to the highest version",
+					},
+
+					&cli.BoolFlag{
+						Name:  "git_commit",
+						Usage: "Commit the modified version files",
+					},
+
+					&cli.BoolFlag{
+						Name:  "git_tag",
+						Usage: "Create an annotated tag for the released version",
+					},
+
+					&cli.StringFlag{
+						Name:  "report",
+						Usage: "Write a JSON report of the run to the specified file",
+					},
+
+					&cli.StringFlag{
+						Name:  "output",
+						Value: "text",
+						Usage: "The output format, `text` or `json` (prints the JSON report to stdout)",
+					},
+				},
+			},
+			{
+				Name:  "current",
+				Usage: "Print the current version, without modifying any files",
+				Action: func(c *cli.Context) error {
+					return versionAction(c, false)
+				},
+				Flags: versionFlags(),
+			},
+			{
+				Name:  "next",
+				Usage: "Print the version that `start` would bump to, without modifying any files",
+				Action: func(c *cli.Context) error {
+					return versionAction(c, true)
+				},
+				Flags: append(versionFlags(),
+					&cli.StringFlag{
+						Name:  "bump_type",
+						Usage: "The bump type, eg. `major`, `minor`, `patch` or `auto` (defaults to version_bump_type)",
+					},
+				),
+			},
+			{
+				Name:   "check",
+				Usage:  "Check that a pull request bumped the version (compares the head & base commits)",
+				Action: checkAction,
+				Flags: append(versionFlags(),
+					&cli.StringFlag{
+						Name:  "scm",
+						Value: "default",
+						Usage: "The scm for the code, used to retrieve the base & head commits of the pull request",
+					},
+					&cli.BoolFlag{
+						Name:  "check_bump_type",
+						Usage: "Fail if the bump is smaller than required by the commit messages, pull request title or labels",
+					},
+					&cli.StringSliceFlag{
+						Name:  "label",
+						Usage: "A pull request label, eg. `semver:minor` (can be specified multiple times)",
+					},
+				),
+			},
+			{
+				Name:   "init",
+				Usage:  "Create a packagr.yml file for the repository in the current working directory",
+				Action: initAction,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "package_type",
+						Value: "auto",
+						Usage: "The type of package, or `auto` to detect it from the repository contents",
+					},
+					&cli.StringFlag{
+						Name:  "version_metadata_path",
+						Usage: "The file that stores the version (defaults to the default of the package type)",
+					},
+					&cli.BoolTFlag{
+						Name:  "addl_version_metadata_paths",
+						Usage: "Add the other files that contain the current version to addl_version_metadata_paths (non-interactive mode)",
+					},
+					&cli.BoolFlag{
+						Name:  "non_interactive",
+						Usage: "Write the proposed settings without prompting",
+					},
+					&cli.BoolFlag{
+						Name:  "force",
+						Usage: "Overwrite an existing packagr.yml file",
+					},
+					&cli.BoolFlag{
+						Name:  "dry_run",
+						Usage: "Print the packagr.yml file instead of writing it",
+					},
 				},
 			},
+			{
+				Name:   "verify",
+				Usage:  "Verify that every version file (addl_version_metadata_paths) matches the primary version",
+				Action: verifyAction,
+				Flags: append(versionFlags(),
+					&cli.BoolFlag{
+						Name:  "fix",
+						Usage: "Rewrite the version files that do not match to the primary version",
+					},
+				),
+			},
 		},
 	}
 
@@ -111,3 +251,321 @@ func main() {
 		log.Fatalf("ERROR: %v", err)
 	}
 }
+
+// versionAction prints the current (or next) version of the package in the current working directory. No SCM payload is
+// required, the versions are read from the local files & git repository.
+func versionAction(c *cli.Context, next bool) error {
+	configuration, _ := config.Create()
+	if c.IsSet("package_type") {
+		configuration.Set(config.PACKAGR_PACKAGE_TYPE, c.String("package_type"))
+	}
+	if c.IsSet("bump_type") {
+		configuration.Set(config.PACKAGR_VERSION_BUMP_TYPE, c.String("bump_type"))
+	}
+	outputMode := c.String("output")
+	if outputMode != "text" && outputMode != "json" {
+		fmt.Fprintf(os.Stderr, "FATAL: unknown output mode %q, must be text or json\n", outputMode)
+		os.Exit(2)
+	}
+
+	cwdPath, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	pipeline := pkg.Pipeline{}
+	var result *pkg.VersionResult
+	if next {
+		result, err = pipeline.NextVersion(cwdPath, configuration)
+	} else {
+		result, err = pipeline.CurrentVersion(cwdPath, configuration)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "FATAL: %+v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if outputMode == "json" {
+		report, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(report))
+		return nil
+	}
+
+	version := func(versionResult *pkg.VersionResult) string {
+		if next {
+			return versionResult.NextVersion
+		}
+		return versionResult.CurrentVersion
+	}
+	if len(result.Packages) == 0 {
+		fmt.Println(version(result))
+	}
+	for _, packageResult := range result.Packages {
+		fmt.Printf("%s %s\n", packageResult.Name, version(packageResult))
+	}
+	return nil
+}
+
+// verifyAction prints the version of every version file in a table, and exits with a non-zero code if any version file
+// does not match the primary version of its package.
+func verifyAction(c *cli.Context) error {
+	configuration, _ := config.Create()
+	if c.IsSet("package_type") {
+		configuration.Set(config.PACKAGR_PACKAGE_TYPE, c.String("package_type"))
+	}
+	outputMode := c.String("output")
+	if outputMode != "text" && outputMode != "json" {
+		fmt.Fprintf(os.Stderr, "FATAL: unknown output mode %q, must be text or json\n", outputMode)
+		os.Exit(2)
+	}
+
+	cwdPath, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	pipeline := pkg.Pipeline{}
+	result, err := pipeline.Verify(cwdPath, configuration, c.Bool("fix"))
+	if result != nil {
+		if outputMode == "json" {
+			report, jerr := json.MarshalIndent(result, "", "  ")
+			if jerr != nil {
+				return jerr
+			}
+			fmt.Println(string(report))
+		} else {
+			table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(table, "PACKAGE\tFILE\tENGINE\tVERSION\tSTATUS")
+			for _, fileResult := range result.Files {
+				status := "ok"
+				switch {
+				case fileResult.Primary:
+					status = "primary"
+				case fileResult.Fixed:
+					status = fmt.Sprintf("fixed (updated to %s)", fileResult.ExpectedVersion)
+				case fileResult.ExpectedVersion != "":
+					status = fmt.Sprintf("mismatch (expected %s)", fileResult.ExpectedVersion)
+				}
+				packageName := fileResult.Package
+				if packageName == "" {
+					packageName = "-"
+				}
+				fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\n", packageName, fileResult.Path, fileResult.Engine, fileResult.Version, status)
+			}
+			table.Flush()
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "FATAL: %+v\n", err)
+		os.Exit(exitCode(err))
+	}
+	return nil
+}
+
+// initAction proposes the settings of a new packagr.yml file, prompts to confirm (or change) each setting unless
+// non-interactive mode is enabled, and writes the file.
+func initAction(c *cli.Context) error {
+	configuration, _ := config.Create()
+	configuration.Set(config.PACKAGR_PACKAGE_TYPE, c.String("package_type"))
+	if c.IsSet("version_metadata_path") {
+		configuration.Set(config.PACKAGR_VERSION_METADATA_PATH, c.String("version_metadata_path"))
+	}
+
+	cwdPath, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	configPath := path.Join(cwdPath, configuration.GetString(config.PACKAGR_ENGINE_REPO_CONFIG_PATH))
+	if utils.FileExists(configPath) && !c.Bool("force") && !c.Bool("dry_run") {
+		fmt.Fprintf(os.Stderr, "FATAL: %s already exists, use --force to overwrite it\n", configPath)
+		os.Exit(1)
+	}
+
+	pipeline := pkg.Pipeline{}
+	result, err := pipeline.Init(cwdPath, configuration)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "FATAL: %+v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if c.Bool("non_interactive") {
+		if !c.BoolT("addl_version_metadata_paths") {
+			for _, versionFile := range result.VersionFiles {
+				versionFile.Selected = false
+			}
+		}
+	} else {
+		// the prompts are written to stderr, so that stdout only contains the file during a dry run
+		input := bufio.NewReader(os.Stdin)
+		for {
+			packageType := prompt(input, fmt.Sprintf("package_type (%s)", result.PackageTypeReason), result.PackageType)
+			versionMetadataPath := result.VersionMetadataPath
+			if packageType == result.PackageType && versionMetadataPath != "" {
+				versionMetadataPath = prompt(input, fmt.Sprintf("version_metadata_path (current version: %s)", result.CurrentVersion), versionMetadataPath)
+			}
+			if packageType == result.PackageType && versionMetadataPath == result.VersionMetadataPath {
+				break
+			}
+			// the proposal depends on the package type & version file
+			configuration.Set(config.PACKAGR_PACKAGE_TYPE, packageType)
+			if versionMetadataPath != result.VersionMetadataPath {
+				configuration.Set(config.PACKAGR_VERSION_METADATA_PATH, versionMetadataPath)
+			}
+			if result, err = pipeline.Init(cwdPath, configuration); err != nil {
+				fmt.Fprintf(os.Stderr, "FATAL: %+v\n", err)
+				os.Exit(exitCode(err))
+			}
+		}
+		for _, versionFile := range result.VersionFiles {
+			if versionFile.Engine == "" {
+				fmt.Fprintf(os.Stderr, "%s contains the version, but can't be updated by any engine\n", versionFile.Path)
+				continue
+			}
+			versionFile.Selected = confirm(input, fmt.Sprintf("add %s (%s) to addl_version_metadata_paths?", versionFile.Path, versionFile.Engine), true)
+		}
+	}
+
+	if c.Bool("dry_run") {
+		fmt.Print(pkg.RenderRepoConfig(result))
+		return nil
+	}
+	if err := pkg.WriteRepoConfig(cwdPath, result, c.Bool("force")); err != nil {
+		fmt.Fprintf(os.Stderr, "FATAL: %+v\n", err)
+		os.Exit(exitCode(err))
+	}
+	fmt.Printf("wrote %s\n", result.ConfigPath)
+	return nil
+}
+
+// prompt reads a value from the input, returns the default value if the answer is empty (or the input is closed).
+func prompt(input *bufio.Reader, question string, defaultValue string) string {
+	fmt.Fprintf(os.Stderr, "%s [%s]: ", question, defaultValue)
+	answer, _ := input.ReadString('\n')
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return defaultValue
+	}
+	return answer
+}
+
+// confirm reads a yes/no answer from the input, returns the default value if the answer is empty (or the input is
+// closed).
+func confirm(input *bufio.Reader, question string, defaultValue bool) bool {
+	options := "y/N"
+	if defaultValue {
+		options = "Y/n"
+	}
+	for {
+		fmt.Fprintf(os.Stderr, "%s [%s]: ", question, options)
+		answer, err := input.ReadString('\n')
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+			return true
+		case "n", "no":
+			return false
+		case "":
+			return defaultValue
+		}
+		if err != nil {
+			return defaultValue
+		}
+	}
+}
+
+// checkAction compares the version of the head & base commits of a pull request, and exits with a non-zero code if the
+// version was not bumped.
+func checkAction(c *cli.Context) error {
+	configuration, _ := config.Create()
+	if c.IsSet("scm") {
+		configuration.Set(config.PACKAGR_SCM, c.String("scm"))
+	}
+	if c.IsSet("package_type") {
+		configuration.Set(config.PACKAGR_PACKAGE_TYPE, c.String("package_type"))
+	}
+	if c.IsSet("check_bump_type") {
+		configuration.Set(config.PACKAGR_CHECK_BUMP_TYPE, c.Bool("check_bump_type"))
+	}
+	if c.IsSet("label") {
+		configuration.Set(config.PACKAGR_CHECK_LABELS, c.StringSlice("label"))
+	}
+	outputMode := c.String("output")
+	if outputMode != "text" && outputMode != "json" {
+		fmt.Fprintf(os.Stderr, "FATAL: unknown output mode %q, must be text or json\n", outputMode)
+		os.Exit(2)
+	}
+
+	cwdPath, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	pipeline := pkg.Pipeline{}
+	result, err := pipeline.Check(cwdPath, configuration, nil)
+	if result != nil {
+		if outputMode == "json" {
+			report, jerr := json.MarshalIndent(result, "", "  ")
+			if jerr != nil {
+				return jerr
+			}
+			fmt.Println(string(report))
+		} else {
+			printCheckResult := func(prefix string, checkResult *pkg.CheckResult) {
+				if checkResult.Skipped {
+					fmt.Printf("%sno package files changed, skipping\n", prefix)
+					return
+				}
+				if checkResult.NewPackage {
+					fmt.Printf("%snew package, version %s\n", prefix, checkResult.NextVersion)
+					return
+				}
+				fmt.Printf("%sversion changed from %s to %s (%s)\n", prefix, checkResult.PreviousVersion, checkResult.NextVersion, checkResult.BumpType)
+				if checkResult.RequiredBumpType != "" {
+					fmt.Printf("%srequired bump type: %s (%s)\n", prefix, checkResult.RequiredBumpType, checkResult.RequiredBumpTypeReason)
+				}
+			}
+			if len(result.Packages) == 0 {
+				printCheckResult("", result)
+			}
+			for _, packageResult := range result.Packages {
+				printCheckResult(packageResult.Name+": ", packageResult)
+			}
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "FATAL: %+v\n", err)
+		os.Exit(exitCode(err))
+	}
+	return nil
+}
+
+func versionFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "package_type",
+			Usage: "The type of package, or `auto` to detect it from the repository contents (defaults to package_type)",
+		},
+
+		&cli.StringFlag{
+			Name:  "output",
+			Value: "text",
+			Usage: "The output format, `text` (only the version) or `json`",
+		},
+	}
+}
+
+// exitCode maps pipeline errors to process exit codes:
+// 2 - invalid configuration (repo config, scm or package type), 3 - required tools are missing, 1 - all other failures
+func exitCode(err error) int {
+	var pipelineErr *pkg.PipelineError
+	if !errors.As(err, &pipelineErr) {
+		return 1
+	}
+	switch pipelineErr.Step {
+	case pkg.PIPELINE_STEP_PARSE_REPO_CONFIG, pkg.PIPELINE_STEP_CREATE_SCM, pkg.PIPELINE_STEP_DETECT_PACKAGE_TYPE, pkg.PIPELINE_STEP_CREATE_ENGINE:
+		return 2
+	case pkg.PIPELINE_STEP_VALIDATE_TOOLS:
+		return 3
+	default:
+		return 1
+	}
+}
@@ -5,8 +5,9 @@ go 1.18
 require (
 	github.com/Masterminds/semver v1.5.0
 	github.com/analogj/go-util v0.0.0-20200905200945-3b93d31215ae
+	github.com/go-git/go-git/v5 v5.6.0
 	github.com/golang/mock v1.4.4
-	github.com/packagrio/go-common v0.0.10
+	github.com/packagrio/go-common v0.0.12
 	github.com/spf13/viper v1.7.1
 	github.com/stretchr/testify v1.7.0
 	github.com/urfave/cli v1.22.4
@@ -25,7 +26,6 @@ require (
 	github.com/fsnotify/fsnotify v1.4.7 // indirect
 	github.com/go-git/gcfg v1.5.0 // indirect
 	github.com/go-git/go-billy/v5 v5.4.0 // indirect
-	github.com/go-git/go-git/v5 v5.6.0 // indirect
 	github.com/gofrs/uuid v4.0.0+incompatible // indirect
 	github.com/golang/protobuf v1.3.2 // indirect
 	github.com/google/go-github/v50 v50.1.0 // indirect
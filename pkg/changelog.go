@@ -0,0 +1,69 @@
+package pkg
+
+import (
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/packagrio/bumpr/pkg/changelog"
+	"github.com/packagrio/bumpr/pkg/changeset"
+	"github.com/packagrio/bumpr/pkg/config"
+	"github.com/packagrio/bumpr/pkg/conventional"
+	"github.com/packagrio/bumpr/pkg/git"
+	"github.com/packagrio/go-common/pipeline"
+	"log"
+	"os"
+	"path"
+	"time"
+)
+
+// writeChangelog stages a changelog section for the released version, listing the Conventional Commits since the
+// latest release tag (the changelog is created if it doesn't exist). The commits of a monorepo package are limited to
+// the files of the package.
+func (p *Pipeline) writeChangelog(packageName string, packageData *pipeline.Data, packageConfig config.Interface, changeSet *changeset.ChangeSet, version string) error {
+	changelogPath := path.Join(packageData.GitLocalPath, packageConfig.GetString(config.PACKAGR_CHANGELOG_PATH))
+	content, err := changeSet.ReadFile(changelogPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if changelog.HasVersion(string(content), version) {
+		log.Printf("%s already contains version %s, skipping", changelogPath, version)
+		return nil
+	}
+
+	var headSha string
+	if packageData.GitHeadInfo != nil {
+		headSha = packageData.GitHeadInfo.Sha
+	}
+	// the previous release is found with the prefix of the tags created by gitRelease
+	latestTag, err := git.GitFindLatestSemverTag(packageData.GitLocalPath, headSha, p.releaseTagPrefix(packageName), "")
+	if err != nil {
+		return err
+	}
+	var baseSha string
+	if latestTag != nil {
+		baseSha = latestTag.CommitSha
+	}
+	var gitCommits []*object.Commit
+	if packageData != p.Data {
+		gitCommits, err = git.GitPathCommitsBetween(packageData.GitLocalPath, baseSha, headSha)
+	} else {
+		gitCommits, err = git.GitCommitsBetween(packageData.GitLocalPath, baseSha, headSha)
+	}
+	if err != nil {
+		return err
+	}
+	commits := []*conventional.Commit{}
+	for _, gitCommit := range gitCommits {
+		commits = append(commits, conventional.Parse(gitCommit.Hash.String(), gitCommit.Message))
+	}
+
+	templateText := packageConfig.GetString(config.PACKAGR_CHANGELOG_TEMPLATE)
+	if templateText == "" {
+		templateText = changelog.DefaultTemplate
+	}
+	section, err := changelog.Render(templateText, changelog.NewEntry(version, time.Now(), commits))
+	if err != nil {
+		return err
+	}
+	updated, _ := changelog.Insert(string(content), version, section)
+	log.Printf("Adding version %s to %s (%d commit(s))", version, changelogPath, len(commits))
+	return changeSet.WriteFile(changelogPath, []byte(updated), 0644)
+}
@@ -0,0 +1,120 @@
+package changelog
+
+import (
+	"github.com/packagrio/bumpr/pkg/conventional"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultTemplate renders a Keep a Changelog (https://keepachangelog.com) release section.
+const DefaultTemplate = `## [{{.Version}}] - {{.Date}}
+{{range .Groups}}
+### {{.Title}}
+
+{{range .Commits}}- {{if .Scope}}**{{.Scope}}:** {{end}}{{.Subject}} ({{printf "%.7s" .Sha}})
+{{end}}{{end}}`
+
+// the commit types included in the changelog, in display order. Other types (eg. chore, ci, test) are omitted.
+var groupTitles = []struct {
+	commitType string
+	title      string
+}{
+	{"feat", "Features"},
+	{"fix", "Bug Fixes"},
+	{"perf", "Performance Improvements"},
+	{"revert", "Reverts"},
+	{"refactor", "Code Refactoring"},
+	{"docs", "Documentation"},
+}
+
+// Group is a set of commits with the same Conventional Commit type. Breaking changes are grouped separately (with the
+// `breaking` type), regardless of their commit type.
+type Group struct {
+	Type    string
+	Title   string
+	Commits []*conventional.Commit
+}
+
+// Entry is the data available to the changelog template.
+type Entry struct {
+	Version string
+	// the release date, formatted as YYYY-MM-DD
+	Date   string
+	Groups []*Group
+}
+
+// NewEntry groups the commits by type. Commits that are not Conventional Commits are omitted.
+func NewEntry(version string, date time.Time, commits []*conventional.Commit) *Entry {
+	entry := &Entry{Version: version, Date: date.Format("2006-01-02"), Groups: []*Group{}}
+
+	breaking := &Group{Type: "breaking", Title: "BREAKING CHANGES"}
+	byType := map[string]*Group{}
+	for _, groupTitle := range groupTitles {
+		byType[groupTitle.commitType] = &Group{Type: groupTitle.commitType, Title: groupTitle.title}
+	}
+	for _, commit := range commits {
+		if commit.Breaking {
+			breaking.Commits = append(breaking.Commits, commit)
+		} else if group, ok := byType[commit.Type]; ok {
+			group.Commits = append(group.Commits, commit)
+		}
+	}
+
+	if len(breaking.Commits) > 0 {
+		entry.Groups = append(entry.Groups, breaking)
+	}
+	for _, groupTitle := range groupTitles {
+		if group := byType[groupTitle.commitType]; len(group.Commits) > 0 {
+			entry.Groups = append(entry.Groups, group)
+		}
+	}
+	return entry
+}
+
+// Render the entry using the (Go text/template) changelog template.
+func Render(templateText string, entry *Entry) (string, error) {
+	changelogTemplate, err := template.New("changelog").Parse(templateText)
+	if err != nil {
+		return "", err
+	}
+	var section strings.Builder
+	if err := changelogTemplate.Execute(&section, entry); err != nil {
+		return "", err
+	}
+	return section.String(), nil
+}
+
+// a release heading, eg. `## [1.2.3] - 2020-01-01`, `# v1.2.3` or `## 1.2.3 (2020-01-01)`
+var releaseHeadingRegex = regexp.MustCompile(`(?m)^#{1,3}[ \t]+\[?v?(\d+\.\d+\.\d+[^\]\s]*)\]?`)
+
+// Insert adds the rendered release section above the latest release (ie. below the title, introduction and
+// `Unreleased` section), or creates the changelog if content is empty. Returns false (and the unchanged content) if the
+// changelog already contains a section for the version, so that re-running a release is idempotent.
+func Insert(content string, version string, section string) (string, bool) {
+	if HasVersion(content, version) {
+		return content, false
+	}
+	section = strings.TrimRight(section, "\n") + "\n"
+
+	if strings.TrimSpace(content) == "" {
+		return "# Changelog\n\n" + section, true
+	}
+
+	location := releaseHeadingRegex.FindStringIndex(content)
+	if location == nil {
+		return strings.TrimRight(content, "\n") + "\n\n" + section, true
+	}
+	return content[:location[0]] + section + "\n" + content[location[0]:], true
+}
+
+// HasVersion returns true if the changelog contains a release heading for the version.
+func HasVersion(content string, version string) bool {
+	for _, match := range releaseHeadingRegex.FindAllStringSubmatch(content, -1) {
+		if match[1] == strings.TrimPrefix(version, "v") {
+			return true
+		}
+	}
+	return false
+}
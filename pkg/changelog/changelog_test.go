@@ -0,0 +1,124 @@
+package changelog_test
+
+import (
+	"github.com/packagrio/bumpr/pkg/changelog"
+	"github.com/packagrio/bumpr/pkg/conventional"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func testEntry() *changelog.Entry {
+	return changelog.NewEntry("1.3.0", time.Date(2020, 5, 17, 0, 0, 0, 0, time.UTC), []*conventional.Commit{
+		conventional.Parse("1111111aaaa", "fix(api): handle empty responses"),
+		conventional.Parse("2222222bbbb", "feat: add retries"),
+		conventional.Parse("3333333cccc", "chore: update dependencies"),
+		conventional.Parse("4444444dddd", "feat(cli)!: remove the --legacy flag"),
+		conventional.Parse("5555555eeee", "Merge pull request #12"),
+	})
+}
+
+func TestNewEntry(t *testing.T) {
+	//test
+	entry := testEntry()
+
+	//assert
+	require.Equal(t, "2020-05-17", entry.Date)
+	require.Len(t, entry.Groups, 3, "should omit chore & non conventional commits")
+	require.Equal(t, "breaking", entry.Groups[0].Type)
+	require.Equal(t, "remove the --legacy flag", entry.Groups[0].Commits[0].Subject)
+	require.Equal(t, "feat", entry.Groups[1].Type)
+	require.Len(t, entry.Groups[1].Commits, 1, "should not list breaking changes twice")
+	require.Equal(t, "fix", entry.Groups[2].Type)
+}
+
+func TestRender(t *testing.T) {
+	//test
+	section, err := changelog.Render(changelog.DefaultTemplate, testEntry())
+	require.NoError(t, err)
+
+	//assert
+	require.Equal(t, `## [1.3.0] - 2020-05-17
+
+### BREAKING CHANGES
+
+- **cli:** remove the --legacy flag (4444444)
+
+### Features
+
+- add retries (2222222)
+
+### Bug Fixes
+
+- **api:** handle empty responses (1111111)
+`, section)
+}
+
+func TestRender_InvalidTemplate(t *testing.T) {
+	//test
+	_, err := changelog.Render("{{.Version", testEntry())
+
+	//assert
+	require.Error(t, err)
+}
+
+func TestInsert(t *testing.T) {
+	//setup
+	content := `# Changelog
+All notable changes to this project will be documented in this file.
+
+## [Unreleased]
+
+## [1.2.0] - 2020-01-01
+### Added
+- initial release
+`
+
+	//test
+	updated, inserted := changelog.Insert(content, "1.3.0", "## [1.3.0] - 2020-05-17\n\n### Features\n\n- add retries\n")
+	rerun, reinserted := changelog.Insert(updated, "1.3.0", "## [1.3.0] - 2020-05-18\n")
+
+	//assert
+	require.True(t, inserted)
+	require.Equal(t, `# Changelog
+All notable changes to this project will be documented in this file.
+
+## [Unreleased]
+
+## [1.3.0] - 2020-05-17
+
+### Features
+
+- add retries
+
+## [1.2.0] - 2020-01-01
+### Added
+- initial release
+`, updated, "should insert the section below the unreleased section")
+	require.False(t, reinserted, "should not insert the same version twice")
+	require.Equal(t, updated, rerun)
+}
+
+func TestInsert_NewFile(t *testing.T) {
+	//test
+	updated, inserted := changelog.Insert("", "1.0.0", "## [1.0.0] - 2020-05-17\n")
+
+	//assert
+	require.True(t, inserted)
+	require.Equal(t, "# Changelog\n\n## [1.0.0] - 2020-05-17\n", updated)
+}
+
+func TestInsert_WithoutReleases(t *testing.T) {
+	//test
+	updated, _ := changelog.Insert("# Changelog\nNotes", "1.0.0", "## [1.0.0] - 2020-05-17\n")
+
+	//assert
+	require.Equal(t, "# Changelog\nNotes\n\n## [1.0.0] - 2020-05-17\n", updated)
+}
+
+func TestHasVersion(t *testing.T) {
+	require.True(t, changelog.HasVersion("# 0.1.0\n\nInitial release of test_cookbook\n", "0.1.0"), "should match unbracketed headings")
+	require.True(t, changelog.HasVersion("## [v1.2.3] - 2020-01-01\n", "1.2.3"))
+	require.False(t, changelog.HasVersion("## [1.2.30] - 2020-01-01\n", "1.2.3"))
+	require.False(t, changelog.HasVersion("- fixed crash in 1.2.3\n", "1.2.3"))
+}
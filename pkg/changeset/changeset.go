@@ -0,0 +1,209 @@
+package changeset
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// FileChange is a pending modification to a single file.
+type FileChange struct {
+	Path    string
+	Before  []byte
+	After   []byte
+	Mode    os.FileMode
+	Existed bool
+}
+
+// ChangeSet holds file modifications in memory until they are committed, so that every change made during a run can
+// be previewed (dry run) before anything is written to the filesystem.
+type ChangeSet struct {
+	changes map[string]*FileChange
+
+	// the change set this change set is staged on top of (see NewOverlay)
+	base *ChangeSet
+}
+
+func New() *ChangeSet {
+	return &ChangeSet{changes: map[string]*FileChange{}}
+}
+
+// NewOverlay returns a change set staged on top of the pending changes of base, eg. to preview the changes that follow
+// the commit of base during a dry run. Files are read from base before the filesystem, and the changes are relative to
+// the content staged in base. An overlay must not be committed before its base.
+func NewOverlay(base *ChangeSet) *ChangeSet {
+	return &ChangeSet{changes: map[string]*FileChange{}, base: base}
+}
+
+// ReadFile returns the pending content of the file if it has been modified, otherwise the content on disk.
+func (c *ChangeSet) ReadFile(filePath string) ([]byte, error) {
+	if change, ok := c.changes[filepath.Clean(filePath)]; ok {
+		return change.After, nil
+	}
+	if c.base != nil {
+		return c.base.ReadFile(filePath)
+	}
+	return os.ReadFile(filePath)
+}
+
+// WriteFile stages the new content of the file. The original content & mode are recorded the first time a file is
+// modified, perm is only used if the file does not exist yet.
+func (c *ChangeSet) WriteFile(filePath string, data []byte, perm os.FileMode) error {
+	filePath = filepath.Clean(filePath)
+	if change, ok := c.changes[filePath]; ok {
+		change.After = data
+		return nil
+	}
+
+	change := &FileChange{Path: filePath, After: data, Mode: perm}
+	if baseChange := c.baseChange(filePath); baseChange != nil {
+		change.Before = baseChange.After
+		change.Mode = baseChange.Mode
+		change.Existed = true
+	} else if info, err := os.Stat(filePath); err == nil {
+		before, rerr := os.ReadFile(filePath)
+		if rerr != nil {
+			return rerr
+		}
+		change.Before = before
+		change.Mode = info.Mode().Perm()
+		change.Existed = true
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	c.changes[filePath] = change
+	return nil
+}
+
+// baseChange returns the change staged for the file in the base change set(s), if any.
+func (c *ChangeSet) baseChange(filePath string) *FileChange {
+	for base := c.base; base != nil; base = base.base {
+		if change, ok := base.changes[filePath]; ok {
+			return change
+		}
+	}
+	return nil
+}
+
+// Changes returns the files with modified content, sorted by path.
+func (c *ChangeSet) Changes() []*FileChange {
+	changes := []*FileChange{}
+	for _, change := range c.changes {
+		if change.Existed && string(change.Before) == string(change.After) {
+			continue
+		}
+		changes = append(changes, change)
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+// Commit writes all pending changes to the filesystem. Every change is first written to a temporary file next to its
+// destination, then all temporary files are renamed over their destinations. If any change can't be applied, the files
+// that were already replaced are restored to their original content & mode (and new files are removed), so that the
+// filesystem is never left partially modified. Nothing is written if any file was modified on disk since its change was
+// staged (eg. by a pre_bump hook), as the staged content would silently discard that modification.
+func (c *ChangeSet) Commit() error {
+	changes := c.Changes()
+	for _, change := range changes {
+		if err := checkUnmodified(change); err != nil {
+			return err
+		}
+	}
+
+	tempPaths := map[string]string{}
+	removeTempFiles := func() {
+		for _, tempPath := range tempPaths {
+			os.Remove(tempPath)
+		}
+	}
+	for _, change := range changes {
+		tempPath, err := writeTempFile(change.Path, change.After, change.Mode)
+		if err != nil {
+			removeTempFiles()
+			return err
+		}
+		tempPaths[change.Path] = tempPath
+	}
+
+	applied := []*FileChange{}
+	for _, change := range changes {
+		if err := os.Rename(tempPaths[change.Path], change.Path); err != nil {
+			removeTempFiles()
+			if rerr := rollback(applied); rerr != nil {
+				return fmt.Errorf("%s (rollback failed: %s)", err, rerr)
+			}
+			return err
+		}
+		delete(tempPaths, change.Path)
+		applied = append(applied, change)
+	}
+	return nil
+}
+
+// Revert restores the original content & mode of every committed change (and removes the files that were created),
+// eg. when a step after the commit fails.
+func (c *ChangeSet) Revert() error {
+	return rollback(c.Changes())
+}
+
+// checkUnmodified returns an error if the file on disk no longer matches the content recorded when the change was staged.
+func checkUnmodified(change *FileChange) error {
+	current, err := os.ReadFile(change.Path)
+	if os.IsNotExist(err) {
+		if !change.Existed {
+			return nil
+		}
+	} else if err != nil {
+		return err
+	} else if change.Existed && string(current) == string(change.Before) {
+		return nil
+	}
+	return fmt.Errorf("%s was modified after its version changes were staged", change.Path)
+}
+
+// rollback restores the original content & mode of the applied changes, in reverse order.
+func rollback(applied []*FileChange) error {
+	var rollbackErr error
+	for ndx := len(applied) - 1; ndx >= 0; ndx-- {
+		change := applied[ndx]
+		var err error
+		if change.Existed {
+			var tempPath string
+			if tempPath, err = writeTempFile(change.Path, change.Before, change.Mode); err == nil {
+				err = os.Rename(tempPath, change.Path)
+			}
+		} else {
+			err = os.Remove(change.Path)
+		}
+		// continue restoring the remaining files, only the first error is returned
+		if err != nil && rollbackErr == nil {
+			rollbackErr = err
+		}
+	}
+	return rollbackErr
+}
+
+// writeTempFile writes data to a new temporary file in the same directory as filePath (so that it can be renamed
+// atomically), with the given mode.
+func writeTempFile(filePath string, data []byte, mode os.FileMode) (string, error) {
+	tempFile, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".bumpr-*")
+	if err != nil {
+		return "", err
+	}
+	_, err = tempFile.Write(data)
+	if err == nil {
+		err = tempFile.Chmod(mode)
+	}
+	if cerr := tempFile.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tempFile.Name())
+		return "", err
+	}
+	return tempFile.Name(), nil
+}
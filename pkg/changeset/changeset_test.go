@@ -0,0 +1,206 @@
+package changeset_test
+
+import (
+	"github.com/packagrio/bumpr/pkg/changeset"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestChangeSet_WriteFile_DoesNotModifyDisk(t *testing.T) {
+	//setup
+	dirPath := t.TempDir()
+	filePath := path.Join(dirPath, "VERSION")
+	require.NoError(t, os.WriteFile(filePath, []byte("1.0.0"), 0600))
+	changeSet := changeset.New()
+
+	//test
+	werr := changeSet.WriteFile(filePath, []byte("1.0.1"), 0644)
+	require.NoError(t, werr)
+
+	//assert
+	onDisk, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	require.Equal(t, "1.0.0", string(onDisk), "should not write to disk before commit")
+	staged, err := changeSet.ReadFile(filePath)
+	require.NoError(t, err)
+	require.Equal(t, "1.0.1", string(staged), "should read the pending content")
+
+	changes := changeSet.Changes()
+	require.Len(t, changes, 1)
+	require.Equal(t, "1.0.0", string(changes[0].Before))
+	require.Equal(t, os.FileMode(0600), changes[0].Mode, "should keep the original file mode")
+	require.True(t, changes[0].Existed)
+}
+
+func TestChangeSet_Changes_SkipsUnmodified(t *testing.T) {
+	//setup
+	dirPath := t.TempDir()
+	filePath := path.Join(dirPath, "VERSION")
+	require.NoError(t, os.WriteFile(filePath, []byte("1.0.0"), 0644))
+	changeSet := changeset.New()
+
+	//test
+	require.NoError(t, changeSet.WriteFile(filePath, []byte("1.0.1"), 0644))
+	require.NoError(t, changeSet.WriteFile(filePath, []byte("1.0.0"), 0644))
+
+	//assert
+	require.Empty(t, changeSet.Changes(), "should ignore files that end up with their original content")
+}
+
+func TestChangeSet_NewOverlay(t *testing.T) {
+	//setup
+	dirPath := t.TempDir()
+	filePath := path.Join(dirPath, "pom.xml")
+	require.NoError(t, os.WriteFile(filePath, []byte("1.0.0-SNAPSHOT"), 0600))
+	base := changeset.New()
+	require.NoError(t, base.WriteFile(filePath, []byte("1.0.0"), 0644))
+	overlay := changeset.NewOverlay(base)
+
+	//test
+	staged, rerr := overlay.ReadFile(filePath)
+	werr := overlay.WriteFile(filePath, []byte("1.0.1-SNAPSHOT"), 0644)
+
+	//assert
+	require.NoError(t, rerr)
+	require.Equal(t, "1.0.0", string(staged), "should read the content staged in the base change set")
+	require.NoError(t, werr)
+	changes := overlay.Changes()
+	require.Len(t, changes, 1)
+	require.Equal(t, "1.0.0", string(changes[0].Before), "should be relative to the base change set")
+	require.Equal(t, "1.0.1-SNAPSHOT", string(changes[0].After))
+	require.Equal(t, os.FileMode(0600), changes[0].Mode)
+	require.Equal(t, "1.0.0", string(base.Changes()[0].After), "should not modify the base change set")
+}
+
+func TestChangeSet_Commit(t *testing.T) {
+	//setup
+	dirPath := t.TempDir()
+	existingPath := path.Join(dirPath, "VERSION")
+	newPath := path.Join(dirPath, "version.txt")
+	require.NoError(t, os.WriteFile(existingPath, []byte("1.0.0"), 0600))
+	changeSet := changeset.New()
+	require.NoError(t, changeSet.WriteFile(existingPath, []byte("1.0.1"), 0644))
+	require.NoError(t, changeSet.WriteFile(newPath, []byte("1.0.1"), 0644))
+
+	//test
+	cerr := changeSet.Commit()
+	require.NoError(t, cerr)
+
+	//assert
+	existing, err := os.ReadFile(existingPath)
+	require.NoError(t, err)
+	require.Equal(t, "1.0.1", string(existing))
+	info, err := os.Stat(existingPath)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	created, err := os.ReadFile(newPath)
+	require.NoError(t, err)
+	require.Equal(t, "1.0.1", string(created))
+}
+
+func TestChangeSet_Commit_RollbackOnFailure(t *testing.T) {
+	//setup
+	dirPath := t.TempDir()
+	firstPath := path.Join(dirPath, "a_VERSION")
+	newPath := path.Join(dirPath, "b_version.txt")
+	lastPath := path.Join(dirPath, "c_VERSION")
+	require.NoError(t, os.WriteFile(firstPath, []byte("1.0.0"), 0600))
+	require.NoError(t, os.WriteFile(lastPath, []byte("1.0.0"), 0644))
+
+	changeSet := changeset.New()
+	require.NoError(t, changeSet.WriteFile(firstPath, []byte("1.0.1"), 0644))
+	require.NoError(t, changeSet.WriteFile(newPath, []byte("1.0.1"), 0644))
+	require.NoError(t, changeSet.WriteFile(lastPath, []byte("1.0.1"), 0644))
+
+	//replace the last file with a (non-empty) directory, so that it can't be renamed over.
+	require.NoError(t, os.Remove(lastPath))
+	require.NoError(t, os.MkdirAll(path.Join(lastPath, "nested"), 0755))
+
+	//test
+	cerr := changeSet.Commit()
+
+	//assert
+	require.Error(t, cerr)
+	first, err := os.ReadFile(firstPath)
+	require.NoError(t, err)
+	require.Equal(t, "1.0.0", string(first), "should restore the original content")
+	info, err := os.Stat(firstPath)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm(), "should restore the original mode")
+	require.NoFileExists(t, newPath, "should remove files created by the change set")
+
+	entries, err := os.ReadDir(dirPath)
+	require.NoError(t, err)
+	require.Len(t, entries, 2, "should remove all temporary files")
+}
+
+func TestChangeSet_Commit_MissingDirectory(t *testing.T) {
+	//setup
+	dirPath := t.TempDir()
+	existingPath := path.Join(dirPath, "VERSION")
+	require.NoError(t, os.WriteFile(existingPath, []byte("1.0.0"), 0644))
+
+	changeSet := changeset.New()
+	require.NoError(t, changeSet.WriteFile(existingPath, []byte("1.0.1"), 0644))
+	require.NoError(t, changeSet.WriteFile(path.Join(dirPath, "missing", "version.txt"), []byte("1.0.1"), 0644))
+
+	//test
+	cerr := changeSet.Commit()
+
+	//assert
+	require.Error(t, cerr)
+	existing, err := os.ReadFile(existingPath)
+	require.NoError(t, err)
+	require.Equal(t, "1.0.0", string(existing), "should not modify any file if a change can't be staged")
+}
+
+func TestChangeSet_Commit_ModifiedOnDisk(t *testing.T) {
+	//setup
+	dirPath := t.TempDir()
+	existingPath := path.Join(dirPath, "VERSION")
+	createdPath := path.Join(dirPath, "CHANGELOG.md")
+	require.NoError(t, os.WriteFile(existingPath, []byte("1.0.0"), 0644))
+	changeSet := changeset.New()
+	require.NoError(t, changeSet.WriteFile(createdPath, []byte("# Changelog"), 0644))
+	require.NoError(t, changeSet.WriteFile(existingPath, []byte("1.0.1"), 0644))
+	require.NoError(t, os.WriteFile(existingPath, []byte("1.0.0-regenerated"), 0644))
+
+	//test
+	err := changeSet.Commit()
+
+	//assert
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "VERSION was modified")
+	content, rerr := os.ReadFile(existingPath)
+	require.NoError(t, rerr)
+	require.Equal(t, "1.0.0-regenerated", string(content), "should not overwrite the modification")
+	require.NoFileExists(t, createdPath, "should not write any file")
+}
+
+func TestChangeSet_Revert(t *testing.T) {
+	//setup
+	dirPath := t.TempDir()
+	existingPath := path.Join(dirPath, "VERSION")
+	createdPath := path.Join(dirPath, "CHANGELOG.md")
+	require.NoError(t, os.WriteFile(existingPath, []byte("1.0.0"), 0600))
+	changeSet := changeset.New()
+	require.NoError(t, changeSet.WriteFile(existingPath, []byte("1.0.1"), 0644))
+	require.NoError(t, changeSet.WriteFile(createdPath, []byte("# Changelog"), 0644))
+	require.NoError(t, changeSet.Commit())
+
+	//test
+	err := changeSet.Revert()
+	require.NoError(t, err)
+
+	//assert
+	content, rerr := os.ReadFile(existingPath)
+	require.NoError(t, rerr)
+	require.Equal(t, "1.0.0", string(content))
+	info, serr := os.Stat(existingPath)
+	require.NoError(t, serr)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm(), "should restore the original file mode")
+	require.NoFileExists(t, createdPath, "should remove created files")
+}
@@ -0,0 +1,193 @@
+package changeset
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// number of unchanged lines shown around each change
+const diffContextLines = 3
+
+type diffLine struct {
+	kind byte // ' ', '-' or '+'
+	text string
+}
+
+// UnifiedDiff renders all pending changes as a unified diff, paths are shown relative to basePath.
+func (c *ChangeSet) UnifiedDiff(basePath string) string {
+	var diff strings.Builder
+	for _, change := range c.Changes() {
+		relPath, err := filepath.Rel(basePath, change.Path)
+		if err != nil {
+			relPath = change.Path
+		}
+		fromName := "a/" + filepath.ToSlash(relPath)
+		if !change.Existed {
+			fromName = "/dev/null"
+		}
+		diff.WriteString(UnifiedDiff(fromName, "b/"+filepath.ToSlash(relPath), change.Before, change.After))
+	}
+	return diff.String()
+}
+
+// UnifiedDiff returns the unified diff between the before and after content, or an empty string if they are identical.
+func UnifiedDiff(fromName string, toName string, before []byte, after []byte) string {
+	lines := diffLines(splitLines(string(before)), splitLines(string(after)))
+
+	var diff strings.Builder
+	for _, hunk := range diffHunks(lines) {
+		if diff.Len() == 0 {
+			diff.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", fromName, toName))
+		}
+		diff.WriteString(hunk)
+	}
+	return diff.String()
+}
+
+// split content into lines, keeping the line terminators so that a missing newline at the end of file is detected.
+func splitLines(content string) []string {
+	if content == "" {
+		return []string{}
+	}
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines computes the line edit script between a and b. The common prefix & suffix are trimmed first, so that the
+// longest common subsequence table only covers the changed lines (version changes are usually a few adjacent lines of
+// a large file, eg. a lock file).
+func diffLines(a []string, b []string) []diffLine {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	lines := []diffLine{}
+	for _, line := range a[:prefix] {
+		lines = append(lines, diffLine{' ', line})
+	}
+	lines = append(lines, diffLinesLCS(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		lines = append(lines, diffLine{' ', line})
+	}
+	return lines
+}
+
+// diffLinesLCS computes the line edit script between a and b using the longest common subsequence.
+func diffLinesLCS(a []string, b []string) []diffLine {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	lines := []diffLine{}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i++
+			j++
+		case j >= len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	return lines
+}
+
+// group the edit script into hunks, each surrounded by (at most) diffContextLines unchanged lines.
+func diffHunks(lines []diffLine) []string {
+	hunks := []string{}
+	for start := 0; start < len(lines); {
+		// find the next change
+		for start < len(lines) && lines[start].kind == ' ' {
+			start++
+		}
+		if start == len(lines) {
+			break
+		}
+
+		// extend the hunk until there are more than 2*context unchanged lines between changes
+		end := start
+		for next := start; next < len(lines); next++ {
+			if lines[next].kind != ' ' {
+				end = next
+			} else if next-end > 2*diffContextLines {
+				break
+			}
+		}
+
+		hunkStart := start - diffContextLines
+		if hunkStart < 0 {
+			hunkStart = 0
+		}
+		hunkEnd := end + diffContextLines + 1
+		if hunkEnd > len(lines) {
+			hunkEnd = len(lines)
+		}
+		hunks = append(hunks, renderHunk(lines, hunkStart, hunkEnd))
+		start = hunkEnd
+	}
+	return hunks
+}
+
+func renderHunk(lines []diffLine, hunkStart int, hunkEnd int) string {
+	// line numbers are 1-based, and count the lines preceding the hunk in each file.
+	fromLine, toLine := 1, 1
+	for _, line := range lines[:hunkStart] {
+		if line.kind != '+' {
+			fromLine++
+		}
+		if line.kind != '-' {
+			toLine++
+		}
+	}
+
+	var body strings.Builder
+	fromCount, toCount := 0, 0
+	for _, line := range lines[hunkStart:hunkEnd] {
+		if line.kind != '+' {
+			fromCount++
+		}
+		if line.kind != '-' {
+			toCount++
+		}
+		body.WriteByte(line.kind)
+		body.WriteString(line.text)
+		if !strings.HasSuffix(line.text, "\n") {
+			body.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+
+	// an empty range starts at the line before the hunk
+	if fromCount == 0 {
+		fromLine--
+	}
+	if toCount == 0 {
+		toLine--
+	}
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@\n%s", fromLine, fromCount, toLine, toCount, body.String())
+}
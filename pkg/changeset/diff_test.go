@@ -0,0 +1,131 @@
+package changeset_test
+
+import (
+	"fmt"
+	"github.com/packagrio/bumpr/pkg/changeset"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	//setup
+	before := "name: test\nversion: 1.0.0\ndescription: test package\n"
+	after := "name: test\nversion: 1.0.1\ndescription: test package\n"
+
+	//test
+	diff := changeset.UnifiedDiff("a/package.yml", "b/package.yml", []byte(before), []byte(after))
+
+	//assert
+	require.Equal(t, `--- a/package.yml
++++ b/package.yml
+@@ -1,3 +1,3 @@
+ name: test
+-version: 1.0.0
++version: 1.0.1
+ description: test package
+`, diff)
+}
+
+func TestUnifiedDiff_Identical(t *testing.T) {
+	//test
+	diff := changeset.UnifiedDiff("a/VERSION", "b/VERSION", []byte("1.0.0\n"), []byte("1.0.0\n"))
+
+	//assert
+	require.Empty(t, diff)
+}
+
+func TestUnifiedDiff_NoNewlineAtEndOfFile(t *testing.T) {
+	//test
+	diff := changeset.UnifiedDiff("a/VERSION", "b/VERSION", []byte("1.0.0"), []byte("1.0.1"))
+
+	//assert
+	require.Equal(t, `--- a/VERSION
++++ b/VERSION
+@@ -1,1 +1,1 @@
+-1.0.0
+\ No newline at end of file
++1.0.1
+\ No newline at end of file
+`, diff)
+}
+
+func TestUnifiedDiff_SeparateHunks(t *testing.T) {
+	//setup
+	before := "version 1\na\nb\nc\nd\ne\nf\ng\nh\nversion 1\n"
+	after := "version 2\na\nb\nc\nd\ne\nf\ng\nh\nversion 2\n"
+
+	//test
+	diff := changeset.UnifiedDiff("a/file", "b/file", []byte(before), []byte(after))
+
+	//assert
+	require.Equal(t, `--- a/file
++++ b/file
+@@ -1,4 +1,4 @@
+-version 1
++version 2
+ a
+ b
+ c
+@@ -7,4 +7,4 @@
+ f
+ g
+ h
+-version 1
++version 2
+`, diff)
+}
+
+func TestUnifiedDiff_LargeFile(t *testing.T) {
+	//setup
+	lines := make([]string, 100000)
+	for ndx := range lines {
+		lines[ndx] = fmt.Sprintf("line %d\n", ndx)
+	}
+	before := strings.Join(lines, "")
+	lines[50000] = "changed\n"
+	after := strings.Join(lines, "")
+
+	//test
+	diff := changeset.UnifiedDiff("a/file", "b/file", []byte(before), []byte(after))
+
+	//assert
+	require.Equal(t, `--- a/file
++++ b/file
+@@ -49998,7 +49998,7 @@
+ line 49997
+ line 49998
+ line 49999
+-line 50000
++changed
+ line 50001
+ line 50002
+ line 50003
+`, diff, "should only compare the changed lines of a large file")
+}
+
+func TestChangeSet_UnifiedDiff(t *testing.T) {
+	//setup
+	dirPath := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(dirPath, "VERSION"), []byte("1.0.0\n"), 0644))
+	changeSet := changeset.New()
+	require.NoError(t, changeSet.WriteFile(path.Join(dirPath, "VERSION"), []byte("1.0.1\n"), 0644))
+	require.NoError(t, changeSet.WriteFile(path.Join(dirPath, "pkg", "version.txt"), []byte("1.0.1\n"), 0644))
+
+	//test
+	diff := changeSet.UnifiedDiff(dirPath)
+
+	//assert
+	require.Equal(t, `--- a/VERSION
++++ b/VERSION
+@@ -1,1 +1,1 @@
+-1.0.0
++1.0.1
+--- /dev/null
++++ b/pkg/version.txt
+@@ -0,0 +1,1 @@
++1.0.1
+`, diff)
+}
@@ -0,0 +1,234 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Masterminds/semver"
+	"github.com/packagrio/bumpr/pkg/config"
+	"github.com/packagrio/bumpr/pkg/conventional"
+	"github.com/packagrio/bumpr/pkg/git"
+	"github.com/packagrio/go-common/pipeline"
+	"github.com/packagrio/go-common/scm"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// Check compares the version of the head commit of a pull request with the version of its base commit (both retrieved
+// via the SCM payload), the version files of both commits are read straight from the git object database. Returns a
+// *VersionNotBumpedError if the version is unchanged or went backwards. When `check_bump_type` is enabled, an
+// *InsufficientBumpError is returned if the bump is smaller than required by the commits, the pull request title or
+// the labels (`check_labels`). Check failures are wrapped in a *PipelineError, and returned along with the result.
+// If sourceScm is nil, it is created using the `scm` setting.
+func (p *Pipeline) Check(workingDir string, configData config.Interface, sourceScm scm.Interface) (*CheckResult, error) {
+	p.init(workingDir, configData)
+	if err := p.ParseRepoConfig(); err != nil {
+		return nil, newPipelineError(PIPELINE_STEP_PARSE_REPO_CONFIG, err)
+	}
+	if sourceScm == nil {
+		var err error
+		if sourceScm, err = scm.Create(p.Config.GetString(config.PACKAGR_SCM), p.Data, p.Config, &http.Client{}); err != nil {
+			return nil, newPipelineError(PIPELINE_STEP_CREATE_SCM, err)
+		}
+	}
+	p.Scm = sourceScm
+
+	payload, err := p.Scm.RetrievePayload()
+	if err != nil {
+		return nil, newPipelineError(PIPELINE_STEP_RETRIEVE_PAYLOAD, err)
+	}
+	if payload.Base == nil || payload.Base.Sha == "" || payload.Head == nil || payload.Head.Sha == "" {
+		return nil, newPipelineError(PIPELINE_STEP_CHECK, errors.New("check requires the base & head commits of a pull request"))
+	}
+	p.Data.GitHeadInfo = payload.Head
+	p.Data.GitBaseInfo = payload.Base
+
+	packages, err := p.LoadPackages()
+	if err != nil {
+		return nil, newPipelineError(PIPELINE_STEP_PARSE_REPO_CONFIG, err)
+	}
+	if len(packages) == 0 {
+		return p.checkPackage("", p.Data, p.Config, payload.Title)
+	}
+
+	//monorepo mode, every changed package must be bumped
+	changedPackages, err := p.changedPackages(packages)
+	if err != nil {
+		return nil, newPipelineError(PIPELINE_STEP_DETECT_CHANGED_PACKAGES, err)
+	}
+	packagesData, packagesSettings, err := p.packagesSettings(packages)
+	if err != nil {
+		return nil, err
+	}
+	result := &CheckResult{BaseSha: payload.Base.Sha, HeadSha: payload.Head.Sha}
+	var checkErr error
+	for ndx, packageConfig := range packages {
+		if changedPackages != nil && !changedPackages[packageConfig.Name] {
+			log.Printf("No files changed in package %s, skipping", packageConfig.Name)
+			result.Packages = append(result.Packages, &CheckResult{Name: packageConfig.Name, Path: packageConfig.Path, Skipped: true})
+			continue
+		}
+
+		log.Printf("Checking package %s (%s)", packageConfig.Name, packageConfig.Path)
+		packageResult, err := p.checkPackage(packageConfig.Name, packagesData[ndx], packagesSettings[ndx], payload.Title)
+		if packageResult == nil {
+			return nil, err
+		} else if err != nil && checkErr == nil {
+			checkErr = err
+		}
+		packageResult.Name = packageConfig.Name
+		packageResult.Path = packageConfig.Path
+		result.Packages = append(result.Packages, packageResult)
+	}
+	return result, checkErr
+}
+
+// checkPackage compares the version of a package at the base & head commits. The result is nil if the versions could
+// not be read.
+func (p *Pipeline) checkPackage(packageName string, packageData *pipeline.Data, packageConfig config.Interface, title string) (*CheckResult, error) {
+	result := &CheckResult{
+		PackageType: packageConfig.GetString(config.PACKAGR_PACKAGE_TYPE),
+		BaseSha:     packageData.GitBaseInfo.Sha,
+		HeadSha:     packageData.GitHeadInfo.Sha,
+	}
+
+	// a package added by the pull request has no previous version to compare with
+	baseExists, err := git.GitPathExists(packageData.GitLocalPath, result.BaseSha)
+	if err != nil {
+		return nil, newPipelineError(PIPELINE_STEP_CHECK, fmt.Errorf("could not read the files of commit %s: %s", result.BaseSha, err))
+	}
+	if result.NextVersion, err = p.revisionVersion(packageData, packageConfig, result.HeadSha); err != nil {
+		return nil, err
+	}
+	if !baseExists {
+		log.Printf("The package does not exist at the base commit, skipping the version check of the new package (%s)", result.NextVersion)
+		result.NewPackage = true
+		return result, nil
+	}
+	if result.PreviousVersion, err = p.revisionVersion(packageData, packageConfig, result.BaseSha); err != nil {
+		return nil, err
+	}
+	previousVersion, err := semver.NewVersion(result.PreviousVersion)
+	if err != nil {
+		return nil, newPipelineError(PIPELINE_STEP_CHECK, fmt.Errorf("invalid version (%s) at the base commit: %s", result.PreviousVersion, err))
+	}
+	nextVersion, err := semver.NewVersion(result.NextVersion)
+	if err != nil {
+		return nil, newPipelineError(PIPELINE_STEP_CHECK, fmt.Errorf("invalid version (%s) at the head commit: %s", result.NextVersion, err))
+	}
+	result.BumpType = versionBumpType(previousVersion, nextVersion)
+	log.Printf("Version changed from %s to %s (%s)", result.PreviousVersion, result.NextVersion, result.BumpType)
+
+	if !nextVersion.GreaterThan(previousVersion) {
+		return result, newPipelineError(PIPELINE_STEP_CHECK, &VersionNotBumpedError{
+			Package:         packageName,
+			PreviousVersion: result.PreviousVersion,
+			NextVersion:     result.NextVersion,
+		})
+	}
+	if !packageConfig.GetBool(config.PACKAGR_CHECK_BUMP_TYPE) {
+		return result, nil
+	}
+
+	if result.RequiredBumpType, result.RequiredBumpTypeReason, err = p.requiredBumpType(packageData, packageConfig, title); err != nil {
+		return nil, err
+	}
+	if previousVersion.Prerelease() != "" {
+		// the prerelease version was already bumped when it was created, eg. 1.4.0-rc.1 -> 1.4.0
+		log.Printf("The base version (%s) is a prerelease, skipping the bump type check", result.PreviousVersion)
+		return result, nil
+	}
+	if conventional.CompareBumpTypes(result.BumpType, result.RequiredBumpType) < 0 {
+		return result, newPipelineError(PIPELINE_STEP_CHECK, &InsufficientBumpError{
+			Package:          packageName,
+			BumpType:         result.BumpType,
+			RequiredBumpType: result.RequiredBumpType,
+			Reason:           result.RequiredBumpTypeReason,
+		})
+	}
+	return result, nil
+}
+
+// revisionVersion reads the version of a package at a revision. The package files are exported from the git object
+// database to a temporary directory, so that the worktree is not modified.
+func (p *Pipeline) revisionVersion(packageData *pipeline.Data, packageConfig config.Interface, revision string) (string, error) {
+	if packageConfig.GetString(config.PACKAGR_PACKAGE_TYPE) == "tag" || packageConfig.GetString(config.PACKAGR_VERSION_SOURCE) != "file" {
+		return "", newPipelineError(PIPELINE_STEP_CHECK, errors.New("check requires the version to be stored in the package files (version_source: file)"))
+	}
+
+	tempPath, err := os.MkdirTemp("", "bumpr-check")
+	if err != nil {
+		return "", newPipelineError(PIPELINE_STEP_CHECK, err)
+	}
+	defer os.RemoveAll(tempPath)
+
+	// engines may create directories next to the package (eg. the golang GOPATH), they are kept in the temp directory
+	readData := *packageData
+	readData.GitLocalPath = filepath.Join(tempPath, "package")
+	readData.GitParentPath = tempPath
+	if err := git.GitExportTree(packageData.GitLocalPath, revision, readData.GitLocalPath); err != nil {
+		return "", newPipelineError(PIPELINE_STEP_CHECK, fmt.Errorf("could not read the files of commit %s: %s", revision, err))
+	}
+
+	readConfig, err := config.Clone(packageConfig, map[string]interface{}{
+		config.PACKAGR_VERSION_BUMP_TYPE: conventional.BUMP_TYPE_NONE,
+	})
+	if err != nil {
+		return "", newPipelineError(PIPELINE_STEP_PARSE_REPO_CONFIG, err)
+	}
+	versionResult, err := p.readVersion(&readData, readConfig, false)
+	if err != nil {
+		return "", err
+	}
+	return versionResult.CurrentVersion, nil
+}
+
+// requiredBumpType returns the highest bump type required by the commits of the pull request, its title and labels.
+func (p *Pipeline) requiredBumpType(packageData *pipeline.Data, packageConfig config.Interface, title string) (string, string, error) {
+	bumpTypeResult, err := p.determineBumpType(packageData, packageConfig)
+	if err != nil {
+		return "", "", newPipelineError(PIPELINE_STEP_DETERMINE_BUMP_TYPE, err)
+	}
+	requiredBumpType := bumpTypeResult.BumpType
+	reason := fmt.Sprintf("%d commit(s) require a %s bump", len(bumpTypeResult.Commits), requiredBumpType)
+
+	classifier, err := conventional.NewClassifier(packageConfig.GetStringMapString(config.PACKAGR_VERSION_BUMP_RULES))
+	if err != nil {
+		return "", "", newPipelineError(PIPELINE_STEP_DETERMINE_BUMP_TYPE, err)
+	}
+	// squash merged pull requests use the title as the commit message
+	if titleBumpType := classifier.Classify(conventional.Parse("", title)); conventional.CompareBumpTypes(titleBumpType, requiredBumpType) > 0 {
+		requiredBumpType = titleBumpType
+		reason = fmt.Sprintf("the pull request title requires a %s bump", titleBumpType)
+	}
+	for _, label := range packageConfig.GetStringSlice(config.PACKAGR_CHECK_LABELS) {
+		if labelBumpType := conventional.ParseLabel(label); conventional.CompareBumpTypes(labelBumpType, requiredBumpType) > 0 {
+			requiredBumpType = labelBumpType
+			reason = fmt.Sprintf("the %s label requires a %s bump", label, labelBumpType)
+		}
+	}
+	if requiredBumpType == conventional.BUMP_TYPE_NONE {
+		reason = "no commits or labels require a version bump"
+	}
+	return requiredBumpType, reason, nil
+}
+
+// versionBumpType returns the size of a version change: major, minor, patch, release (a prerelease was released),
+// prerelease or none.
+func versionBumpType(previousVersion *semver.Version, nextVersion *semver.Version) string {
+	switch {
+	case nextVersion.Major() != previousVersion.Major():
+		return conventional.BUMP_TYPE_MAJOR
+	case nextVersion.Minor() != previousVersion.Minor():
+		return conventional.BUMP_TYPE_MINOR
+	case nextVersion.Patch() != previousVersion.Patch():
+		return conventional.BUMP_TYPE_PATCH
+	case nextVersion.Equal(previousVersion):
+		return conventional.BUMP_TYPE_NONE
+	case nextVersion.Prerelease() == "":
+		return "release"
+	default:
+		return "prerelease"
+	}
+}
@@ -30,9 +30,15 @@ func (c *configuration) Init() error {
 	//set defaults
 	c.SetDefault(PACKAGR_PACKAGE_TYPE, "generic")
 	c.SetDefault(PACKAGR_SCM, "default")
+	c.SetDefault(PACKAGR_OUTPUT, "text")
 	c.SetDefault(PACKAGR_VERSION_BUMP_TYPE, "patch")
+	c.SetDefault(PACKAGR_VERSION_SOURCE, "file")
 	c.SetDefault(PACKAGR_ENGINE_REPO_CONFIG_PATH, "packagr.yml")
 	c.SetDefault(PACKAGR_ADDL_VERSION_METADATA_PATHS, map[string]string{})
+	c.SetDefault(PACKAGR_PACKAGES_BUMP_DEPENDENTS, true)
+	c.SetDefault(PACKAGR_GIT_COMMIT_MESSAGE, "chore(release): {{.Version}}")
+	c.SetDefault(PACKAGR_GIT_TAG_PREFIX, "v")
+	c.SetDefault(PACKAGR_CHANGELOG_PATH, "CHANGELOG.md")
 
 	//set the default system config file search path.
 	//if you want to load a non-standard location system config file (~/capsule.yml), use ReadConfig
@@ -21,6 +21,7 @@ func TestConfiguration_init_ShouldCorrectlyInitializeConfiguration(t *testing.T)
 	require.Equal(t, "generic", testConfig.GetString(config.PACKAGR_PACKAGE_TYPE), "should populate package_type with generic default")
 	require.Equal(t, "default", testConfig.GetString(config.PACKAGR_SCM), "should populate scm with default")
 	require.Equal(t, "patch", testConfig.GetString(config.PACKAGR_VERSION_BUMP_TYPE), "should populate runner with default")
+	require.Equal(t, "file", testConfig.GetString(config.PACKAGR_VERSION_SOURCE), "should populate version source with default")
 	require.Equal(t, map[string]interface{}{}, testConfig.GetStringMap(config.PACKAGR_ADDL_VERSION_METADATA_PATHS), "should populate addl metadata paths from config file")
 
 }
@@ -77,3 +78,21 @@ func TestConfiguration_ReadConfig_AddlVersionMetadataPaths(t *testing.T) {
 	require.Equal(t, map[string]interface{}{"node": []interface{}{"package.json"}}, testConfig.GetStringMap(config.PACKAGR_ADDL_VERSION_METADATA_PATHS), "should populate addl metadata paths from config file")
 
 }
+
+func TestClone(t *testing.T) {
+	//setup
+	defer utils.UnsetEnv("PACKAGR_")()
+	testConfig, _ := config.Create()
+	testConfig.Set(config.PACKAGR_SCM, "github")
+
+	//test
+	clonedConfig, err := config.Clone(testConfig, map[string]interface{}{config.PACKAGR_VERSION_BUMP_TYPE: "major"})
+	require.NoError(t, err)
+	clonedConfig.SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "VERSION")
+
+	//assert
+	require.Equal(t, "github", clonedConfig.GetString(config.PACKAGR_SCM), "should copy the original settings")
+	require.Equal(t, "major", clonedConfig.GetString(config.PACKAGR_VERSION_BUMP_TYPE), "should apply the overrides")
+	require.Equal(t, "patch", testConfig.GetString(config.PACKAGR_VERSION_BUMP_TYPE), "should not modify the original configuration")
+	require.False(t, testConfig.IsSet(config.PACKAGR_VERSION_METADATA_PATH), "should not modify the original configuration")
+}
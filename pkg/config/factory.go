@@ -7,3 +7,19 @@ func Create() (Interface, error) {
 	}
 	return config, nil
 }
+
+// Clone returns an isolated copy of the configuration with the overrides applied, so that changes made to the copy (eg.
+// defaults set by an engine) do not affect the original configuration.
+func Clone(parent Interface, overrides map[string]interface{}) (Interface, error) {
+	clone, err := Create()
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range parent.AllSettings() {
+		clone.Set(key, value)
+	}
+	for key, value := range overrides {
+		clone.Set(key, value)
+	}
+	return clone, nil
+}
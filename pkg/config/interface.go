@@ -25,9 +25,39 @@ type Interface interface {
 
 const PACKAGR_PACKAGE_TYPE = "package_type"
 const PACKAGR_SCM = "scm"
+const PACKAGR_DRY_RUN = "dry_run"
+const PACKAGR_OUTPUT = "output"
+const PACKAGR_REPORT_PATH = "report_path"
 const PACKAGR_VERSION_BUMP_TYPE = "version_bump_type"
+const PACKAGR_VERSION_PRERELEASE_ID = "version_prerelease_id"
+const PACKAGR_VERSION_BUMP_RULES = "version_bump_rules"
 const PACKAGR_VERSION_METADATA_PATH = "version_metadata_path"
+const PACKAGR_VERSION_SOURCE = "version_source"
+const PACKAGR_VERSION_CURRENT = "version_current"
+const PACKAGR_VERSION_TAG_PREFIX = "version_tag_prefix"
+const PACKAGR_VERSION_TAG_GLOB = "version_tag_glob"
 const PACKAGR_ADDL_VERSION_METADATA_PATHS = "addl_version_metadata_paths"
+const PACKAGR_PACKAGES = "packages"
+const PACKAGR_PACKAGES_CHANGED_ONLY = "packages_changed_only"
+const PACKAGR_PACKAGES_SHARED_PATHS = "packages_shared_paths"
+const PACKAGR_PACKAGES_LOCKSTEP = "packages_lockstep"
+const PACKAGR_PACKAGES_SYNC = "packages_sync"
+const PACKAGR_PACKAGES_BUMP_DEPENDENTS = "packages_bump_dependents"
+const PACKAGR_HOOKS = "hooks"
+const PACKAGR_CHANGELOG = "changelog"
+const PACKAGR_CHANGELOG_PATH = "changelog_path"
+const PACKAGR_CHANGELOG_TEMPLATE = "changelog_template"
 const PACKAGR_ENGINE_REPO_CONFIG_PATH = "engine_repo_config_path"
+const PACKAGR_ENGINE_GIT_AUTHOR_NAME = "engine_git_author_name"
+const PACKAGR_ENGINE_GIT_AUTHOR_EMAIL = "engine_git_author_email"
+const PACKAGR_GIT_COMMIT = "git_commit"
+const PACKAGR_GIT_COMMIT_MESSAGE = "git_commit_message"
+const PACKAGR_GIT_TAG = "git_tag"
+const PACKAGR_GIT_TAG_PREFIX = "git_tag_prefix"
+const PACKAGR_CHECK_BUMP_TYPE = "check_bump_type"
+const PACKAGR_CHECK_LABELS = "check_labels"
 const PACKAGR_GENERIC_VERSION_TEMPLATE = "generic_version_template"
 const PACKAGR_GENERIC_MERGE_VERSION_FILE = "generic_merge_version_file"
+const PACKAGR_MAVEN_NEXT_DEVELOPMENT_VERSION = "maven_next_development_version"
+const PACKAGR_GRADLE_ANDROID_VERSION_CODE = "gradle_android_version_code"
+const PACKAGR_HELM_APP_VERSION_SOURCE = "helm_app_version_source"
@@ -0,0 +1,46 @@
+package config
+
+import "path"
+
+// Package is an entry in the `packages` list, used to bump multiple independent packages in a single repository
+// (monorepo mode). Empty fields are inherited from the repository configuration.
+type Package struct {
+	// used to name the package outputs, eg. `release_version_<name>`
+	Name string `mapstructure:"name"`
+	// path of the package, relative to the repository root
+	Path string `mapstructure:"path"`
+	// globs (relative to the repository root) of the files that belong to the package, used to determine if the package
+	// changed when `packages_changed_only` is enabled. Defaults to `<path>/**`
+	Paths               []string `mapstructure:"paths"`
+	PackageType         string   `mapstructure:"package_type"`
+	VersionMetadataPath string   `mapstructure:"version_metadata_path"`
+	VersionBumpType     string   `mapstructure:"version_bump_type"`
+	// the source of the chart appVersion (helm packages)
+	HelmAppVersionSource string `mapstructure:"helm_app_version_source"`
+}
+
+// Overrides returns the package specific configuration settings
+func (p *Package) Overrides() map[string]interface{} {
+	overrides := map[string]interface{}{}
+	if p.PackageType != "" {
+		overrides[PACKAGR_PACKAGE_TYPE] = p.PackageType
+	}
+	if p.VersionMetadataPath != "" {
+		overrides[PACKAGR_VERSION_METADATA_PATH] = p.VersionMetadataPath
+	}
+	if p.VersionBumpType != "" {
+		overrides[PACKAGR_VERSION_BUMP_TYPE] = p.VersionBumpType
+	}
+	if p.HelmAppVersionSource != "" {
+		overrides[PACKAGR_HELM_APP_VERSION_SOURCE] = p.HelmAppVersionSource
+	}
+	return overrides
+}
+
+// PathGlobs returns the globs of the files that belong to the package
+func (p *Package) PathGlobs() []string {
+	if len(p.Paths) > 0 {
+		return p.Paths
+	}
+	return []string{path.Join(p.Path, "**")}
+}
@@ -0,0 +1,64 @@
+package conventional
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Classifier determines the bump type required by a set of commits.
+type Classifier struct {
+	Rules map[string]string
+}
+
+// Result contains the highest bump type found, and the commits that required it.
+type Result struct {
+	BumpType string
+	Commits  []*Commit
+}
+
+// NewClassifier creates a classifier using the DefaultRules, merged with the provided rule overrides (eg. chore: none)
+func NewClassifier(ruleOverrides map[string]string) (*Classifier, error) {
+	rules := map[string]string{}
+	for commitType, bumpType := range DefaultRules {
+		rules[commitType] = bumpType
+	}
+	for commitType, bumpType := range ruleOverrides {
+		bumpType = strings.ToLower(bumpType)
+		if !IsValidBumpType(bumpType) {
+			return nil, fmt.Errorf("invalid bump type (%s) for commit type (%s)", bumpType, commitType)
+		}
+		rules[strings.ToLower(commitType)] = bumpType
+	}
+	return &Classifier{Rules: rules}, nil
+}
+
+// Classify a single commit. Breaking changes always require a major bump.
+func (c *Classifier) Classify(commit *Commit) string {
+	if commit.Breaking {
+		return BUMP_TYPE_MAJOR
+	}
+	if bumpType, ok := c.Rules[commit.Type]; ok && commit.Type != "" {
+		return bumpType
+	}
+	return BUMP_TYPE_NONE
+}
+
+// ClassifyAll returns the highest bump type required by the commits, and the commits that drove it.
+func (c *Classifier) ClassifyAll(commits []*Commit) *Result {
+	result := &Result{BumpType: BUMP_TYPE_NONE, Commits: []*Commit{}}
+	for _, commit := range commits {
+		bumpType := c.Classify(commit)
+		if bumpType == BUMP_TYPE_NONE {
+			continue
+		}
+
+		switch CompareBumpTypes(bumpType, result.BumpType) {
+		case 1:
+			result.BumpType = bumpType
+			result.Commits = []*Commit{commit}
+		case 0:
+			result.Commits = append(result.Commits, commit)
+		}
+	}
+	return result
+}
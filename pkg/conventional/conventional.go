@@ -0,0 +1,86 @@
+package conventional
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Commit is a commit message parsed using the Conventional Commits specification
+// https://www.conventionalcommits.org/en/v1.0.0/
+type Commit struct {
+	Sha      string
+	Type     string
+	Scope    string
+	Subject  string
+	Breaking bool
+}
+
+const BUMP_TYPE_NONE = "none"
+const BUMP_TYPE_PATCH = "patch"
+const BUMP_TYPE_MINOR = "minor"
+const BUMP_TYPE_MAJOR = "major"
+
+// bump types ordered from lowest to highest
+var bumpTypePrecedence = map[string]int{
+	BUMP_TYPE_NONE:  0,
+	BUMP_TYPE_PATCH: 1,
+	BUMP_TYPE_MINOR: 2,
+	BUMP_TYPE_MAJOR: 3,
+}
+
+// DefaultRules map Conventional Commit types to bump types. Types that are not listed do not bump the version.
+var DefaultRules = map[string]string{
+	"feat": BUMP_TYPE_MINOR,
+	"fix":  BUMP_TYPE_PATCH,
+	"perf": BUMP_TYPE_PATCH,
+}
+
+var headerRegex = regexp.MustCompile(`^(\w[\w-]*)(?:\(([^)]*)\))?(!)?:\s+(.+)$`)
+var breakingFooterRegex = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE:\s`)
+
+// Parse the commit message, non-conventional commit messages will have an empty Type.
+func Parse(sha string, message string) *Commit {
+	message = strings.TrimSpace(message)
+	header := strings.SplitN(message, "\n", 2)[0]
+
+	commit := &Commit{Sha: sha, Subject: strings.TrimSpace(header)}
+	matches := headerRegex.FindStringSubmatch(commit.Subject)
+	if matches == nil {
+		return commit
+	}
+	commit.Type = strings.ToLower(matches[1])
+	commit.Scope = matches[2]
+	commit.Breaking = matches[3] == "!" || breakingFooterRegex.MatchString(message)
+	commit.Subject = matches[4]
+	return commit
+}
+
+// a pull request label requesting a bump type, eg. `major`, `semver:minor`, `bump/patch`
+var labelRegex = regexp.MustCompile(`(?i)^(?:(?:semver|bump|release|version)\s*[:/_-]\s*)?(major|minor|patch)$`)
+
+// ParseLabel returns the bump type requested by a pull request label, or none if the label does not request a bump.
+func ParseLabel(label string) string {
+	match := labelRegex.FindStringSubmatch(strings.TrimSpace(label))
+	if match == nil {
+		return BUMP_TYPE_NONE
+	}
+	return strings.ToLower(match[1])
+}
+
+// IsValidBumpType returns true if the bump type can be used in a classification rule
+func IsValidBumpType(bumpType string) bool {
+	_, ok := bumpTypePrecedence[bumpType]
+	return ok
+}
+
+// CompareBumpTypes returns an integer comparing the precedence of two bump types.
+// The result will be 0 if a == b, -1 if a < b, and +1 if a > b.
+func CompareBumpTypes(a string, b string) int {
+	aPrecedence, bPrecedence := bumpTypePrecedence[a], bumpTypePrecedence[b]
+	if aPrecedence < bPrecedence {
+		return -1
+	} else if aPrecedence > bPrecedence {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,138 @@
+package conventional_test
+
+import (
+	"github.com/packagrio/bumpr/pkg/conventional"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	//test
+	commit := conventional.Parse("1234", "feat(api): add new endpoint\n\nsome description")
+
+	//assert
+	require.Equal(t, "1234", commit.Sha)
+	require.Equal(t, "feat", commit.Type)
+	require.Equal(t, "api", commit.Scope)
+	require.Equal(t, "add new endpoint", commit.Subject)
+	require.False(t, commit.Breaking)
+}
+
+func TestParse_BreakingBang(t *testing.T) {
+	//test
+	commit := conventional.Parse("1234", "feat!: drop support for node 12")
+
+	//assert
+	require.Equal(t, "feat", commit.Type)
+	require.Empty(t, commit.Scope)
+	require.True(t, commit.Breaking, "should detect breaking change from `!`")
+}
+
+func TestParse_BreakingFooter(t *testing.T) {
+	//test
+	commit := conventional.Parse("1234", "fix: change config format\n\nBREAKING CHANGE: the `foo` key was removed")
+
+	//assert
+	require.Equal(t, "fix", commit.Type)
+	require.True(t, commit.Breaking, "should detect breaking change from footer")
+}
+
+func TestParse_NonConventional(t *testing.T) {
+	//test
+	commit := conventional.Parse("1234", "Merge pull request #12 from foo/bar\n\nfeat: something")
+
+	//assert
+	require.Empty(t, commit.Type, "should not have a type")
+	require.Equal(t, "Merge pull request #12 from foo/bar", commit.Subject)
+	require.False(t, commit.Breaking)
+}
+
+func TestCompareBumpTypes(t *testing.T) {
+	require.Equal(t, 1, conventional.CompareBumpTypes("major", "minor"))
+	require.Equal(t, -1, conventional.CompareBumpTypes("none", "patch"))
+	require.Equal(t, 0, conventional.CompareBumpTypes("minor", "minor"))
+}
+
+func TestClassifier_ClassifyAll(t *testing.T) {
+	//setup
+	classifier, err := conventional.NewClassifier(map[string]string{})
+	require.NoError(t, err)
+	commits := []*conventional.Commit{
+		conventional.Parse("1", "fix: bug"),
+		conventional.Parse("2", "feat: feature 1"),
+		conventional.Parse("3", "chore: cleanup"),
+		conventional.Parse("4", "feat(ui): feature 2"),
+	}
+
+	//test
+	result := classifier.ClassifyAll(commits)
+
+	//assert
+	require.Equal(t, "minor", result.BumpType)
+	require.Len(t, result.Commits, 2, "should only include the commits that drove the bump type")
+	require.Equal(t, "2", result.Commits[0].Sha)
+	require.Equal(t, "4", result.Commits[1].Sha)
+}
+
+func TestClassifier_ClassifyAll_Breaking(t *testing.T) {
+	//setup
+	classifier, err := conventional.NewClassifier(map[string]string{})
+	require.NoError(t, err)
+	commits := []*conventional.Commit{
+		conventional.Parse("1", "feat: feature"),
+		conventional.Parse("2", "refactor!: rewrite"),
+	}
+
+	//test
+	result := classifier.ClassifyAll(commits)
+
+	//assert
+	require.Equal(t, "major", result.BumpType)
+	require.Equal(t, "2", result.Commits[0].Sha)
+}
+
+func TestClassifier_ClassifyAll_None(t *testing.T) {
+	//setup
+	classifier, err := conventional.NewClassifier(map[string]string{})
+	require.NoError(t, err)
+	commits := []*conventional.Commit{
+		conventional.Parse("1", "docs: readme"),
+		conventional.Parse("2", "not a conventional commit"),
+	}
+
+	//test
+	result := classifier.ClassifyAll(commits)
+
+	//assert
+	require.Equal(t, "none", result.BumpType)
+	require.Empty(t, result.Commits)
+}
+
+func TestClassifier_RuleOverrides(t *testing.T) {
+	//setup
+	classifier, err := conventional.NewClassifier(map[string]string{"perf": "minor", "Chore": "patch", "fix": "none"})
+	require.NoError(t, err)
+
+	//assert
+	require.Equal(t, "minor", classifier.Classify(conventional.Parse("1", "perf: faster")))
+	require.Equal(t, "patch", classifier.Classify(conventional.Parse("1", "chore: deps")))
+	require.Equal(t, "none", classifier.Classify(conventional.Parse("1", "fix: bug")))
+	require.Equal(t, "minor", classifier.Classify(conventional.Parse("1", "feat: feature")), "should keep default rules")
+}
+
+func TestClassifier_InvalidRule(t *testing.T) {
+	//test
+	_, err := conventional.NewClassifier(map[string]string{"perf": "huge"})
+
+	//assert
+	require.Error(t, err, "should return an error for invalid bump types")
+}
+
+func TestParseLabel(t *testing.T) {
+	//test & assert
+	require.Equal(t, conventional.BUMP_TYPE_MAJOR, conventional.ParseLabel("major"))
+	require.Equal(t, conventional.BUMP_TYPE_MINOR, conventional.ParseLabel("semver:minor"))
+	require.Equal(t, conventional.BUMP_TYPE_PATCH, conventional.ParseLabel("Bump/Patch"))
+	require.Equal(t, conventional.BUMP_TYPE_NONE, conventional.ParseLabel("bug"))
+	require.Equal(t, conventional.BUMP_TYPE_NONE, conventional.ParseLabel("minor-fix"))
+}
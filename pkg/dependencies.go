@@ -0,0 +1,142 @@
+package pkg
+
+import (
+	"fmt"
+	"github.com/packagrio/bumpr/pkg/changeset"
+	"github.com/packagrio/bumpr/pkg/config"
+	"github.com/packagrio/bumpr/pkg/engine"
+	"github.com/packagrio/go-common/pipeline"
+	"log"
+	"sort"
+)
+
+// dependencyGraph describes the dependencies between the packages of a monorepo, packages are identified by their index
+// in the `packages` list.
+type dependencyGraph struct {
+	// the name other packages use to depend on each package (eg. the go module path), empty if unknown
+	dependencyNames []string
+	// the internal packages each package depends on
+	dependencies [][]int
+	// packages in topological order, dependencies before their dependents
+	order []int
+}
+
+// buildDependencyGraph reads the dependencies declared by every package (via the engines implementing
+// engine.DependencyManager), and sorts the packages so that each package is bumped after its dependencies. Only
+// dependencies between packages of the same type are considered. Returns a *DependencyCycleError if packages depend on
+// each other.
+func (p *Pipeline) buildDependencyGraph(packages []config.Package, packagesData []*pipeline.Data, packagesSettings []config.Interface) (*dependencyGraph, error) {
+	graph := &dependencyGraph{
+		dependencyNames: make([]string, len(packages)),
+		dependencies:    make([][]int, len(packages)),
+	}
+	packageDependencies := make([][]string, len(packages))
+	packageIndexes := map[string]int{}
+	for ndx := range packages {
+		dependencyManager, err := p.dependencyManager(packagesData[ndx], packagesSettings[ndx], changeset.New())
+		if err != nil {
+			return nil, err
+		} else if dependencyManager == nil {
+			continue
+		}
+
+		if graph.dependencyNames[ndx], err = dependencyManager.PackageName(); err != nil {
+			return nil, newPipelineError(PIPELINE_STEP_DEPENDENCY_GRAPH, err)
+		}
+		if graph.dependencyNames[ndx] != "" {
+			packageIndexes[dependencyKey(packagesSettings[ndx], graph.dependencyNames[ndx])] = ndx
+		}
+		if packageDependencies[ndx], err = dependencyManager.Dependencies(); err != nil {
+			return nil, newPipelineError(PIPELINE_STEP_DEPENDENCY_GRAPH, err)
+		}
+	}
+
+	for ndx := range packages {
+		internal := map[int]bool{}
+		for _, dependencyName := range packageDependencies[ndx] {
+			if dependencyNdx, ok := packageIndexes[dependencyKey(packagesSettings[ndx], dependencyName)]; ok && dependencyNdx != ndx && !internal[dependencyNdx] {
+				internal[dependencyNdx] = true
+				graph.dependencies[ndx] = append(graph.dependencies[ndx], dependencyNdx)
+			}
+		}
+		sort.Ints(graph.dependencies[ndx])
+	}
+
+	// depth first topological sort, following the configured package order where possible
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(packages))
+	stack := []int{}
+	var visit func(ndx int) error
+	visit = func(ndx int) error {
+		switch state[ndx] {
+		case visited:
+			return nil
+		case visiting:
+			cycle := []string{}
+			for stackNdx := len(stack) - 1; stackNdx >= 0; stackNdx-- {
+				if stack[stackNdx] == ndx {
+					for _, cycleNdx := range stack[stackNdx:] {
+						cycle = append(cycle, packages[cycleNdx].Name)
+					}
+					break
+				}
+			}
+			return &DependencyCycleError{Packages: append(cycle, packages[ndx].Name)}
+		}
+
+		state[ndx] = visiting
+		stack = append(stack, ndx)
+		for _, dependencyNdx := range graph.dependencies[ndx] {
+			if err := visit(dependencyNdx); err != nil {
+				return err
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[ndx] = visited
+		graph.order = append(graph.order, ndx)
+		return nil
+	}
+	for ndx := range packages {
+		if err := visit(ndx); err != nil {
+			return nil, newPipelineError(PIPELINE_STEP_DEPENDENCY_GRAPH, err)
+		}
+	}
+	return graph, nil
+}
+
+// updateDependencies stages the new versions of the bumped dependencies (by dependency name) in the package files.
+func (p *Pipeline) updateDependencies(packageData *pipeline.Data, packageConfig config.Interface, changeSet *changeset.ChangeSet, versions map[string]string) error {
+	dependencyManager, err := p.dependencyManager(packageData, packageConfig, changeSet)
+	if err != nil || dependencyManager == nil {
+		return err
+	}
+	for _, dependencyName := range sortedKeys(versions) {
+		log.Printf("Updating dependency %s to %s", dependencyName, versions[dependencyName])
+		if err := dependencyManager.SetDependencyVersion(dependencyName, versions[dependencyName]); err != nil {
+			return newPipelineError(PIPELINE_STEP_SET_VERSION, err)
+		}
+	}
+	return nil
+}
+
+// dependencyManager creates the engine of a package, returns nil if the engine can't manage dependencies. The tools are
+// not validated, dependencies are read & written without them (the tools of a package are validated when it is bumped).
+func (p *Pipeline) dependencyManager(packageData *pipeline.Data, packageConfig config.Interface, changeSet *changeset.ChangeSet) (engine.DependencyManager, error) {
+	// engines may modify the pipeline data during Init (eg. the golang GOPATH)
+	engineData := *packageData
+	packageEngine, err := p.newEngine(&engineData, packageConfig, changeSet)
+	if err != nil {
+		return nil, err
+	}
+	dependencyManager, _ := packageEngine.(engine.DependencyManager)
+	return dependencyManager, nil
+}
+
+// dependency names are only unique per package type (eg. an npm & a python package can share the same name)
+func dependencyKey(packageConfig config.Interface, dependencyName string) string {
+	return fmt.Sprintf("%s:%s", packageConfig.GetString(config.PACKAGR_PACKAGE_TYPE), dependencyName)
+}
@@ -0,0 +1,19 @@
+package engine
+
+import (
+	"regexp"
+	"strings"
+)
+
+// a version specifier pinned to (or with a lower bound of) a single version, eg. `1.2.3`, `^1.2.3`, `~1.2`, `>=1.2.3`
+var versionSpecifierPattern = regexp.MustCompile(`^(\s*(?:\^|~|>=|==|~=|=)?\s*v?)(\d+(?:\.\d+){0,2}(?:[-+][0-9A-Za-z.+-]*)?)(\s*)$`)
+
+// updateVersionSpecifier replaces the version in a dependency specifier, preserving its operator (eg. `^1.2.3` ->
+// `^1.3.0`). Returns false if the specifier does not reference a single version (eg. `1.x`, `workspace:*`, `file:../sdk`).
+func updateVersionSpecifier(specifier string, version string) (string, bool) {
+	match := versionSpecifierPattern.FindStringSubmatch(specifier)
+	if match == nil {
+		return specifier, false
+	}
+	return match[1] + strings.TrimPrefix(version, "v") + match[3], true
+}
@@ -0,0 +1,36 @@
+package engine
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestUpdateVersionSpecifier(t *testing.T) {
+	for specifier, expected := range map[string]string{
+		"1.2.3":                              "1.3.0",
+		"^1.2.3":                             "^1.3.0",
+		"~1.2":                               "~1.3.0",
+		">= 1.2.3":                           ">= 1.3.0",
+		"==1.2.3":                            "==1.3.0",
+		"v1.2.3":                             "v1.3.0",
+		"v0.0.0-20230101000000-abcdef123456": "v1.3.0",
+	} {
+		//test
+		updated, ok := updateVersionSpecifier(specifier, "1.3.0")
+
+		//assert
+		require.True(t, ok, specifier)
+		require.Equal(t, expected, updated, specifier)
+	}
+}
+
+func TestUpdateVersionSpecifier_Unsupported(t *testing.T) {
+	for _, specifier := range []string{"1.x", "*", "workspace:*", "file:../sdk", ">=1.2.3 <2.0.0", "latest"} {
+		//test
+		updated, ok := updateVersionSpecifier(specifier, "1.3.0")
+
+		//assert
+		require.False(t, ok, specifier)
+		require.Equal(t, specifier, updated, "should leave the specifier unchanged")
+	}
+}
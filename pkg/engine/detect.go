@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"fmt"
+	"github.com/packagrio/bumpr/pkg/config"
+	"github.com/packagrio/go-common/errors"
+	"strings"
+)
+
+// PACKAGR_ENGINE_DETECTION_ORDER is the priority order used when `package_type` is `auto`. More specific engines are
+// checked first, since a repository can contain multiple marker files (eg. a Go module with a VERSION file).
+var PACKAGR_ENGINE_DETECTION_ORDER = []string{
+	PACKAGR_ENGINE_TYPE_GOLANG,
+	PACKAGR_ENGINE_TYPE_NODE,
+	PACKAGR_ENGINE_TYPE_RUST,
+	PACKAGR_ENGINE_TYPE_MAVEN,
+	PACKAGR_ENGINE_TYPE_GRADLE,
+	PACKAGR_ENGINE_TYPE_DOTNET,
+	PACKAGR_ENGINE_TYPE_HELM,
+	PACKAGR_ENGINE_TYPE_RUBY,
+	PACKAGR_ENGINE_TYPE_CHEF,
+	PACKAGR_ENGINE_TYPE_PYTHON,
+	PACKAGR_ENGINE_TYPE_GENERIC,
+}
+
+type DetectionCandidate struct {
+	EngineType string
+	Reason     string
+}
+
+type Detection struct {
+	// the selected engine type (the first candidate)
+	EngineType string
+	Reason     string
+
+	// every engine type that matched the repository, in priority order
+	Candidates []DetectionCandidate
+}
+
+// Explain returns a human readable description of the detection result
+func (d *Detection) Explain() string {
+	explanation := fmt.Sprintf("detected package type %s (%s)", d.EngineType, d.Reason)
+	if len(d.Candidates) > 1 {
+		others := []string{}
+		for _, candidate := range d.Candidates[1:] {
+			others = append(others, fmt.Sprintf("%s (%s)", candidate.EngineType, candidate.Reason))
+		}
+		explanation += fmt.Sprintf(", other candidates: %s", strings.Join(others, ", "))
+	}
+	return explanation
+}
+
+// Detect checks the repository for the marker files of each engine (in PACKAGR_ENGINE_DETECTION_ORDER), and selects the
+// first engine type that matches.
+// Engines are not initialized during detection, so no configuration defaults are set.
+func Detect(gitLocalPath string, configImpl config.Interface) (*Detection, error) {
+	detection := new(Detection)
+	for _, engineType := range PACKAGR_ENGINE_DETECTION_ORDER {
+		eng, err := newEngine(engineType)
+		if err != nil {
+			return nil, err
+		}
+		if found, reason := eng.DetectPackage(gitLocalPath, configImpl); found {
+			detection.Candidates = append(detection.Candidates, DetectionCandidate{EngineType: engineType, Reason: reason})
+		}
+	}
+
+	if len(detection.Candidates) == 0 {
+		return nil, errors.EngineUnspecifiedError(fmt.Sprintf("Could not detect the package type, none of the supported package types (%s) were found", strings.Join(PACKAGR_ENGINE_DETECTION_ORDER, ", ")))
+	}
+	detection.EngineType = detection.Candidates[0].EngineType
+	detection.Reason = detection.Candidates[0].Reason
+	return detection, nil
+}
@@ -0,0 +1,105 @@
+package engine_test
+
+import (
+	"github.com/packagrio/bumpr/pkg/config"
+	"github.com/packagrio/bumpr/pkg/engine"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path"
+	"testing"
+)
+
+func writeMarkerFiles(t *testing.T, markerFiles ...string) string {
+	gitLocalPath := t.TempDir()
+	for _, markerFile := range markerFiles {
+		require.NoError(t, os.MkdirAll(path.Dir(path.Join(gitLocalPath, markerFile)), 0755))
+		require.NoError(t, os.WriteFile(path.Join(gitLocalPath, markerFile), []byte{}, 0644))
+	}
+	return gitLocalPath
+}
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		markerFiles        []string
+		expectedEngineType string
+	}{
+		{[]string{"go.mod", "pkg/version/version.go"}, engine.PACKAGR_ENGINE_TYPE_GOLANG},
+		{[]string{"package.json"}, engine.PACKAGR_ENGINE_TYPE_NODE},
+		{[]string{"Cargo.toml"}, engine.PACKAGR_ENGINE_TYPE_RUST},
+		{[]string{"pom.xml"}, engine.PACKAGR_ENGINE_TYPE_MAVEN},
+		{[]string{"settings.gradle.kts"}, engine.PACKAGR_ENGINE_TYPE_GRADLE},
+		{[]string{"Acme.Core.csproj"}, engine.PACKAGR_ENGINE_TYPE_DOTNET},
+		{[]string{"Directory.Build.props"}, engine.PACKAGR_ENGINE_TYPE_DOTNET},
+		{[]string{"Chart.yaml"}, engine.PACKAGR_ENGINE_TYPE_HELM},
+		{[]string{"example.gemspec"}, engine.PACKAGR_ENGINE_TYPE_RUBY},
+		{[]string{"metadata.rb"}, engine.PACKAGR_ENGINE_TYPE_CHEF},
+		{[]string{"setup.py"}, engine.PACKAGR_ENGINE_TYPE_PYTHON},
+		{[]string{"pyproject.toml"}, engine.PACKAGR_ENGINE_TYPE_PYTHON},
+		{[]string{"VERSION"}, engine.PACKAGR_ENGINE_TYPE_GENERIC},
+		{[]string{"go.mod", "VERSION"}, engine.PACKAGR_ENGINE_TYPE_GENERIC},
+	}
+
+	for _, tc := range testCases {
+		//setup
+		gitLocalPath := writeMarkerFiles(t, tc.markerFiles...)
+		testConfig, err := config.Create()
+		require.NoError(t, err)
+
+		//test
+		detection, derr := engine.Detect(gitLocalPath, testConfig)
+
+		//assert
+		require.NoError(t, derr)
+		require.Equal(t, tc.expectedEngineType, detection.EngineType, "should detect %v as %s", tc.markerFiles, tc.expectedEngineType)
+	}
+}
+
+func TestDetect_MultipleCandidates(t *testing.T) {
+	//setup
+	gitLocalPath := writeMarkerFiles(t, "go.mod", "pkg/version/version.go", "package.json", "VERSION")
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+
+	//test
+	detection, derr := engine.Detect(gitLocalPath, testConfig)
+
+	//assert
+	require.NoError(t, derr)
+	require.Equal(t, engine.PACKAGR_ENGINE_TYPE_GOLANG, detection.EngineType, "should select the engine with the highest priority")
+	require.Equal(t, "found go.mod and pkg/version/version.go", detection.Reason)
+	require.Equal(t, []engine.DetectionCandidate{
+		{EngineType: engine.PACKAGR_ENGINE_TYPE_GOLANG, Reason: "found go.mod and pkg/version/version.go"},
+		{EngineType: engine.PACKAGR_ENGINE_TYPE_NODE, Reason: "found package.json"},
+		{EngineType: engine.PACKAGR_ENGINE_TYPE_GENERIC, Reason: "found VERSION"},
+	}, detection.Candidates)
+	require.Equal(t, "detected package type golang (found go.mod and pkg/version/version.go), other candidates: node (found package.json), generic (found VERSION)", detection.Explain())
+}
+
+func TestDetect_CustomVersionMetadataPath(t *testing.T) {
+	//setup
+	gitLocalPath := writeMarkerFiles(t, "version.txt")
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+	testConfig.Set(config.PACKAGR_VERSION_METADATA_PATH, "version.txt")
+
+	//test
+	detection, derr := engine.Detect(gitLocalPath, testConfig)
+
+	//assert
+	require.NoError(t, derr)
+	require.Equal(t, engine.PACKAGR_ENGINE_TYPE_GENERIC, detection.EngineType)
+}
+
+func TestDetect_NoMarkerFiles(t *testing.T) {
+	//setup
+	gitLocalPath := writeMarkerFiles(t, "README.md")
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+
+	//test
+	detection, derr := engine.Detect(gitLocalPath, testConfig)
+
+	//assert
+	require.Error(t, derr)
+	require.Nil(t, detection)
+}
@@ -4,32 +4,190 @@ import (
 	stderrors "errors"
 	"fmt"
 	"github.com/Masterminds/semver"
+	"github.com/packagrio/bumpr/pkg/changeset"
 	"github.com/packagrio/bumpr/pkg/config"
+	"github.com/packagrio/bumpr/pkg/git"
 	"github.com/packagrio/go-common/pipeline"
+	"log"
+	"os"
+	"strconv"
+	"strings"
 )
 
 type engineBase struct {
 	Config       config.Interface
 	PipelineData *pipeline.Data
+	ChangeSet    *changeset.ChangeSet
+
+	// the version returned by ResolveCurrentVersion
+	resolvedVersion string
+}
+
+// ResolvedCurrentVersion returns the current version that was bumped (see ResolveCurrentVersion), or an empty string if
+// the version was not resolved yet.
+func (e *engineBase) ResolvedCurrentVersion() string {
+	return e.resolvedVersion
+}
+
+// SetChangeSet routes all file reads & writes through the change set, so that modifications are held in memory until
+// they are committed. Without a change set, files are written directly to disk.
+func (e *engineBase) SetChangeSet(changeSet *changeset.ChangeSet) {
+	e.ChangeSet = changeSet
 }
 
 //Helper functions
 
+func (e *engineBase) readFile(filePath string) ([]byte, error) {
+	if e.ChangeSet == nil {
+		return os.ReadFile(filePath)
+	}
+	return e.ChangeSet.ReadFile(filePath)
+}
+
+func (e *engineBase) writeFile(filePath string, data []byte, perm os.FileMode) error {
+	if e.ChangeSet == nil {
+		return os.WriteFile(filePath, data, perm)
+	}
+	return e.ChangeSet.WriteFile(filePath, data, perm)
+}
+
+// ResolveCurrentVersion returns the version that should be bumped. By default this is the version read from the metadata
+// file, when the version_source is `tag` the latest semver git tag is used instead, so that the file and tag can't drift.
+// When the version_source is `manual`, the version_current setting is used.
+func (e *engineBase) ResolveCurrentVersion(metadataVersion string) (string, error) {
+	var err error
+	switch e.Config.GetString(config.PACKAGR_VERSION_SOURCE) {
+	case PACKAGR_ENGINE_TYPE_TAG:
+		e.resolvedVersion, err = e.RetrieveTagVersion()
+	case "manual":
+		// the current version is specified explicitly (eg. by lockstep mode)
+		if e.resolvedVersion = e.Config.GetString(config.PACKAGR_VERSION_CURRENT); e.resolvedVersion == "" {
+			err = stderrors.New("version_current is required when version_source is manual")
+		}
+	default:
+		e.resolvedVersion = metadataVersion
+	}
+	return e.resolvedVersion, err
+}
+
+// RetrieveTagVersion returns the version of the latest semver git tag reachable from the head commit, or 0.0.0 if
+// the repository has not been tagged yet.
+func (e *engineBase) RetrieveTagVersion() (string, error) {
+	var headSha string
+	if e.PipelineData.GitHeadInfo != nil {
+		headSha = e.PipelineData.GitHeadInfo.Sha
+	}
+
+	latestTag, err := git.GitFindLatestSemverTag(e.PipelineData.GitLocalPath, headSha,
+		e.Config.GetString(config.PACKAGR_VERSION_TAG_PREFIX),
+		e.Config.GetString(config.PACKAGR_VERSION_TAG_GLOB))
+	if err != nil {
+		return "", err
+	}
+	if latestTag == nil {
+		log.Printf("No semver tag found, using 0.0.0 as current version")
+		return "0.0.0", nil
+	}
+	log.Printf("Using latest tag (%s) as current version", latestTag.Name)
+	return latestTag.Version.String(), nil
+}
+
 func (e *engineBase) GenerateNextVersion(currentVersion string) (string, error) {
 	v, nerr := semver.NewVersion(currentVersion)
 	if nerr != nil {
 		return "", nerr
 	}
 
+	// prerelease versions have a lower precedence than the associated release version, so bumping a prerelease
+	// will release it if it's already at the requested interval (the same way `npm version` does).
+	// eg. 1.3.0-rc.4 + minor = 1.3.0, 1.3.1-rc.4 + minor = 1.4.0
+	isPrerelease := v.Prerelease() != ""
+
 	switch bumpType := e.Config.GetString(config.PACKAGR_VERSION_BUMP_TYPE); bumpType {
 	case "major":
+		if isPrerelease && v.Minor() == 0 && v.Patch() == 0 {
+			return fmt.Sprintf("%d.%d.%d", v.Major(), 0, 0), nil
+		}
 		return fmt.Sprintf("%d.%d.%d", v.Major()+1, 0, 0), nil
 	case "minor":
+		if isPrerelease && v.Patch() == 0 {
+			return fmt.Sprintf("%d.%d.%d", v.Major(), v.Minor(), 0), nil
+		}
 		return fmt.Sprintf("%d.%d.%d", v.Major(), v.Minor()+1, 0), nil
 	case "patch":
+		if isPrerelease {
+			return fmt.Sprintf("%d.%d.%d", v.Major(), v.Minor(), v.Patch()), nil
+		}
 		return fmt.Sprintf("%d.%d.%d", v.Major(), v.Minor(), v.Patch()+1), nil
+	case "none":
+		// keep the current version
+		return currentVersion, nil
+	case "release":
+		// remove the prerelease & build metadata suffixes
+		return fmt.Sprintf("%d.%d.%d", v.Major(), v.Minor(), v.Patch()), nil
+	case "premajor":
+		return e.generatePrereleaseVersion(v.Major()+1, 0, 0, "")
+	case "preminor":
+		return e.generatePrereleaseVersion(v.Major(), v.Minor()+1, 0, "")
+	case "prepatch":
+		return e.generatePrereleaseVersion(v.Major(), v.Minor(), v.Patch()+1, "")
+	case "prerelease":
+		// a release version is treated like a prepatch, a prerelease version has its counter incremented.
+		if v.Prerelease() == "" {
+			return e.generatePrereleaseVersion(v.Major(), v.Minor(), v.Patch()+1, "")
+		}
+		nextVersion, err := e.generatePrereleaseVersion(v.Major(), v.Minor(), v.Patch(), v.Prerelease())
+		if err != nil {
+			return "", err
+		}
+		// switching to an identifier with a lower precedence (eg. rc.1 -> beta.1) would produce a lower version
+		if !semver.MustParse(nextVersion).GreaterThan(v) {
+			return "", fmt.Errorf("the next prerelease version (%s) would be lower than the current version (%s), use a prerelease identifier that sorts after %s", nextVersion, currentVersion, v.Prerelease())
+		}
+		return nextVersion, nil
 	default:
 		return "", stderrors.New("Unknown version bump interval")
 	}
 
 }
+
+// generatePrereleaseVersion will generate a prerelease version (eg. 1.4.0-rc.1) using the configured prerelease identifier.
+// If the current prerelease uses the same identifier its numeric counter is incremented (rc.1 -> rc.2), otherwise the
+// counter starts at 1. When no identifier is configured, the identifier of the current prerelease is reused.
+func (e *engineBase) generatePrereleaseVersion(major int64, minor int64, patch int64, currentPrerelease string) (string, error) {
+	prereleaseId := e.Config.GetString(config.PACKAGR_VERSION_PRERELEASE_ID)
+	currentId, currentCounter := splitPrerelease(currentPrerelease)
+	if prereleaseId == "" {
+		prereleaseId = currentId
+	}
+
+	counter := 1
+	if currentPrerelease != "" && currentId == prereleaseId && currentCounter > 0 {
+		counter = currentCounter + 1
+	}
+
+	prerelease := strconv.Itoa(counter)
+	if prereleaseId != "" {
+		prerelease = fmt.Sprintf("%s.%d", prereleaseId, counter)
+	}
+
+	nextVersion := fmt.Sprintf("%d.%d.%d-%s", major, minor, patch, prerelease)
+	if _, nerr := semver.NewVersion(nextVersion); nerr != nil {
+		return "", fmt.Errorf("invalid prerelease identifier (%s): %v", prereleaseId, nerr)
+	}
+	return nextVersion, nil
+}
+
+// splitPrerelease splits a prerelease string (eg. rc.4) into its identifier (rc) and numeric counter (4).
+// The counter is 0 if the prerelease does not end in a number.
+func splitPrerelease(prerelease string) (string, int) {
+	if prerelease == "" {
+		return "", 0
+	}
+	parts := strings.Split(prerelease, ".")
+	counter, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil {
+		return prerelease, 0
+	}
+	return strings.Join(parts[:len(parts)-1], "."), counter
+}
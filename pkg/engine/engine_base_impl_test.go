@@ -101,3 +101,273 @@ func TestEngineBase_BumpVersion_WithVPrefix(t *testing.T) {
 	//assert
 	require.Equal(t, nextV, "1.2.4", "should correctly do a patch bump")
 }
+
+func TestEngineBase_BumpVersion_Premajor(t *testing.T) {
+
+	//setup
+	mockCtrl := gomock.NewController(t)
+	fakeConfig := mock_config.NewMockInterface(mockCtrl)
+	fakeConfig.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).MinTimes(1).Return("premajor")
+	fakeConfig.EXPECT().GetString(config.PACKAGR_VERSION_PRERELEASE_ID).MinTimes(1).Return("rc")
+	eng := engineBase{
+		Config: fakeConfig,
+	}
+
+	//test
+	ver, err := eng.GenerateNextVersion("1.2.2")
+	require.Nil(t, err)
+
+	//assert
+	require.Equal(t, "2.0.0-rc.1", ver, "should correctly do a premajor bump")
+}
+
+func TestEngineBase_BumpVersion_Preminor(t *testing.T) {
+
+	//setup
+	mockCtrl := gomock.NewController(t)
+	fakeConfig := mock_config.NewMockInterface(mockCtrl)
+	fakeConfig.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).MinTimes(1).Return("preminor")
+	fakeConfig.EXPECT().GetString(config.PACKAGR_VERSION_PRERELEASE_ID).MinTimes(1).Return("rc")
+	eng := engineBase{
+		Config: fakeConfig,
+	}
+
+	//test
+	ver, err := eng.GenerateNextVersion("1.3.2")
+	require.Nil(t, err)
+
+	//assert
+	require.Equal(t, "1.4.0-rc.1", ver, "should correctly do a preminor bump")
+}
+
+func TestEngineBase_BumpVersion_Prepatch(t *testing.T) {
+
+	//setup
+	mockCtrl := gomock.NewController(t)
+	fakeConfig := mock_config.NewMockInterface(mockCtrl)
+	fakeConfig.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).MinTimes(1).Return("prepatch")
+	fakeConfig.EXPECT().GetString(config.PACKAGR_VERSION_PRERELEASE_ID).MinTimes(1).Return("beta")
+	eng := engineBase{
+		Config: fakeConfig,
+	}
+
+	//test
+	ver, err := eng.GenerateNextVersion("1.2.2-rc.3")
+	require.Nil(t, err)
+
+	//assert
+	require.Equal(t, "1.2.3-beta.1", ver, "should correctly do a prepatch bump")
+}
+
+func TestEngineBase_BumpVersion_Prerelease(t *testing.T) {
+
+	//setup
+	mockCtrl := gomock.NewController(t)
+	fakeConfig := mock_config.NewMockInterface(mockCtrl)
+	fakeConfig.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).MinTimes(1).Return("prerelease")
+	fakeConfig.EXPECT().GetString(config.PACKAGR_VERSION_PRERELEASE_ID).MinTimes(1).Return("rc")
+	eng := engineBase{
+		Config: fakeConfig,
+	}
+
+	//test
+	ver, err := eng.GenerateNextVersion("1.4.0-rc.1")
+	require.Nil(t, err)
+
+	ver2, err := eng.GenerateNextVersion("1.4.0-beta.2")
+	require.Nil(t, err)
+
+	ver3, err := eng.GenerateNextVersion("1.4.0")
+	require.Nil(t, err)
+
+	//assert
+	require.Equal(t, "1.4.0-rc.2", ver, "should increment the prerelease counter")
+	require.Equal(t, "1.4.0-rc.1", ver2, "should reset the counter when the prerelease identifier changes")
+	require.Equal(t, "1.4.1-rc.1", ver3, "should do a prepatch bump for release versions")
+}
+
+func TestEngineBase_BumpVersion_Prerelease_LowerIdentifier(t *testing.T) {
+
+	//setup
+	mockCtrl := gomock.NewController(t)
+	fakeConfig := mock_config.NewMockInterface(mockCtrl)
+	fakeConfig.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).MinTimes(1).Return("prerelease")
+	fakeConfig.EXPECT().GetString(config.PACKAGR_VERSION_PRERELEASE_ID).MinTimes(1).Return("beta")
+	eng := engineBase{
+		Config: fakeConfig,
+	}
+
+	//test
+	nextV, err := eng.GenerateNextVersion("1.4.0-rc.1")
+
+	//assert
+	require.Error(t, err, "should return an error if the prerelease identifier sorts lower than the current one")
+	require.Empty(t, nextV, "should be empty next version")
+}
+
+func TestEngineBase_BumpVersion_Prerelease_WithoutIdentifier(t *testing.T) {
+
+	//setup
+	mockCtrl := gomock.NewController(t)
+	fakeConfig := mock_config.NewMockInterface(mockCtrl)
+	fakeConfig.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).MinTimes(1).Return("prerelease")
+	fakeConfig.EXPECT().GetString(config.PACKAGR_VERSION_PRERELEASE_ID).MinTimes(1).Return("")
+	eng := engineBase{
+		Config: fakeConfig,
+	}
+
+	//test
+	ver, err := eng.GenerateNextVersion("1.4.0-alpha.1")
+	require.Nil(t, err)
+
+	ver2, err := eng.GenerateNextVersion("1.4.0")
+	require.Nil(t, err)
+
+	//assert
+	require.Equal(t, "1.4.0-alpha.2", ver, "should reuse the current prerelease identifier")
+	require.Equal(t, "1.4.1-1", ver2, "should use a numeric prerelease")
+}
+
+func TestEngineBase_BumpVersion_Prerelease_InvalidIdentifier(t *testing.T) {
+
+	//setup
+	mockCtrl := gomock.NewController(t)
+	fakeConfig := mock_config.NewMockInterface(mockCtrl)
+	fakeConfig.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).MinTimes(1).Return("prepatch")
+	fakeConfig.EXPECT().GetString(config.PACKAGR_VERSION_PRERELEASE_ID).MinTimes(1).Return("rc_1")
+	eng := engineBase{
+		Config: fakeConfig,
+	}
+
+	//test
+	nextV, err := eng.GenerateNextVersion("1.4.0")
+
+	//assert
+	require.Error(t, err, "should return an error if the prerelease identifier is invalid")
+	require.Empty(t, nextV, "should be empty next version")
+}
+
+func TestEngineBase_BumpVersion_Release(t *testing.T) {
+
+	//setup
+	mockCtrl := gomock.NewController(t)
+	fakeConfig := mock_config.NewMockInterface(mockCtrl)
+	fakeConfig.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).MinTimes(1).Return("release")
+	eng := engineBase{
+		Config: fakeConfig,
+	}
+
+	//test
+	ver, err := eng.GenerateNextVersion("1.3.0-rc.4")
+	require.Nil(t, err)
+
+	ver2, err := eng.GenerateNextVersion("1.3.0-rc.4+build.12")
+	require.Nil(t, err)
+
+	ver3, err := eng.GenerateNextVersion("1.3.0")
+	require.Nil(t, err)
+
+	//assert
+	require.Equal(t, "1.3.0", ver, "should remove the prerelease")
+	require.Equal(t, "1.3.0", ver2, "should remove the prerelease and build metadata")
+	require.Equal(t, "1.3.0", ver3, "should not change a release version")
+}
+
+func TestEngineBase_BumpVersion_None(t *testing.T) {
+
+	//setup
+	mockCtrl := gomock.NewController(t)
+	fakeConfig := mock_config.NewMockInterface(mockCtrl)
+	fakeConfig.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).MinTimes(1).Return("none")
+	eng := engineBase{
+		Config: fakeConfig,
+	}
+
+	//test
+	ver, err := eng.GenerateNextVersion("1.3.0-rc.4")
+	require.Nil(t, err)
+
+	//assert
+	require.Equal(t, "1.3.0-rc.4", ver, "should keep the current version")
+}
+
+func TestEngineBase_BumpVersion_Patch_WithPrerelease(t *testing.T) {
+
+	//setup
+	mockCtrl := gomock.NewController(t)
+	fakeConfig := mock_config.NewMockInterface(mockCtrl)
+	fakeConfig.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).MinTimes(1).Return("patch")
+	eng := engineBase{
+		Config: fakeConfig,
+	}
+
+	//test
+	ver, err := eng.GenerateNextVersion("1.3.0-rc.4")
+	require.Nil(t, err)
+
+	//assert
+	require.Equal(t, "1.3.0", ver, "should release the prerelease version")
+}
+
+func TestEngineBase_BumpVersion_Minor_WithPrerelease(t *testing.T) {
+
+	//setup
+	mockCtrl := gomock.NewController(t)
+	fakeConfig := mock_config.NewMockInterface(mockCtrl)
+	fakeConfig.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).MinTimes(1).Return("minor")
+	eng := engineBase{
+		Config: fakeConfig,
+	}
+
+	//test
+	ver, err := eng.GenerateNextVersion("1.3.0-rc.4")
+	require.Nil(t, err)
+
+	ver2, err := eng.GenerateNextVersion("1.3.1-rc.4")
+	require.Nil(t, err)
+
+	//assert
+	require.Equal(t, "1.3.0", ver, "should release the prerelease version")
+	require.Equal(t, "1.4.0", ver2, "should bump the minor version of a prerelease patch")
+}
+
+func TestEngineBase_BumpVersion_Major_WithPrerelease(t *testing.T) {
+
+	//setup
+	mockCtrl := gomock.NewController(t)
+	fakeConfig := mock_config.NewMockInterface(mockCtrl)
+	fakeConfig.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).MinTimes(1).Return("major")
+	eng := engineBase{
+		Config: fakeConfig,
+	}
+
+	//test
+	ver, err := eng.GenerateNextVersion("2.0.0-rc.1")
+	require.Nil(t, err)
+
+	ver2, err := eng.GenerateNextVersion("1.3.0-rc.1")
+	require.Nil(t, err)
+
+	//assert
+	require.Equal(t, "2.0.0", ver, "should release the prerelease version")
+	require.Equal(t, "2.0.0", ver2, "should bump the major version of a prerelease minor")
+}
+
+func TestEngineBase_ResolveCurrentVersion_Manual(t *testing.T) {
+
+	//setup
+	mockCtrl := gomock.NewController(t)
+	fakeConfig := mock_config.NewMockInterface(mockCtrl)
+	fakeConfig.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).MinTimes(1).Return("manual")
+	fakeConfig.EXPECT().GetString(config.PACKAGR_VERSION_CURRENT).MinTimes(1).Return("1.4.0")
+	eng := engineBase{
+		Config: fakeConfig,
+	}
+
+	//test
+	ver, err := eng.ResolveCurrentVersion("1.3.2")
+	require.Nil(t, err)
+
+	//assert
+	require.Equal(t, "1.4.0", ver, "should use the version_current setting instead of the metadata version")
+}
@@ -3,6 +3,7 @@ package engine
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/Masterminds/semver"
 	"github.com/analogj/go-util/utils"
 	"github.com/packagrio/bumpr/pkg/config"
 	"github.com/packagrio/go-common/errors"
@@ -13,6 +14,7 @@ import (
 	"os"
 	"os/exec"
 	"path"
+	"regexp"
 )
 
 type engineChef struct {
@@ -38,6 +40,9 @@ func (g *engineChef) GetCurrentMetadata() interface{} {
 func (g *engineChef) GetNextMetadata() interface{} {
 	return g.NextMetadata
 }
+func (g *engineChef) GetCurrentVersion() string {
+	return g.CurrentMetadata.Version
+}
 
 func (g *engineChef) ValidateTools() error {
 	if _, kerr := exec.LookPath("knife"); kerr != nil {
@@ -47,6 +52,13 @@ func (g *engineChef) ValidateTools() error {
 	return nil
 }
 
+func (g *engineChef) DetectPackage(gitLocalPath string, configImpl config.Interface) (bool, string) {
+	if utils.FileExists(path.Join(gitLocalPath, "metadata.rb")) {
+		return true, "found metadata.rb"
+	}
+	return false, ""
+}
+
 func (g *engineChef) BumpVersion() error {
 	//validate that the chef metadata.rb file exists
 
@@ -74,6 +86,23 @@ func (g *engineChef) SetVersion(versionMetadataPath string, nextVersion string)
 	return g.writeNextMetadata(versionMetadataPath, nextVersion)
 }
 
+// GetVersion reads the version from a metadata.rb file (or the metadata.rb in the specified cookbook directory).
+func (g *engineChef) GetVersion(versionMetadataPath string) (string, error) {
+	metadataPath := versionMetadataPath
+	if path.Base(versionMetadataPath) != "metadata.rb" {
+		metadataPath = path.Join(versionMetadataPath, "metadata.rb")
+	}
+	metadataContent, rerr := g.readFile(metadataPath)
+	if rerr != nil {
+		return "", rerr
+	}
+	match := chefVersionPattern.FindSubmatch(metadataContent)
+	if match == nil {
+		return "", errors.EngineBuildPackageFailed(fmt.Sprintf("Could not find the version in %s", metadataPath))
+	}
+	return string(match[2]), nil
+}
+
 //private Helpers
 
 func (g *engineChef) retrieveCurrentMetadata(gitLocalPath string) error {
@@ -99,7 +128,12 @@ func (g *engineChef) retrieveCurrentMetadata(gitLocalPath string) error {
 
 func (g *engineChef) populateNextMetadata() error {
 
-	nextVersion, err := g.GenerateNextVersion(g.CurrentMetadata.Version)
+	currentVersion, err := g.ResolveCurrentVersion(g.CurrentMetadata.Version)
+	if err != nil {
+		return err
+	}
+
+	nextVersion, err := g.GenerateNextVersion(currentVersion)
 	if err != nil {
 		return err
 	}
@@ -110,6 +144,31 @@ func (g *engineChef) populateNextMetadata() error {
 	return nil
 }
 
-func (g *engineChef) writeNextMetadata(gitLocalPath string, nextVersion string) error {
-	return utils.BashCmdExec(fmt.Sprintf("knife spork bump %s manual %s -o ../", path.Base(gitLocalPath), nextVersion), gitLocalPath, nil, "")
+func (g *engineChef) writeNextMetadata(versionMetadataPath string, nextVersion string) error {
+	// chef cookbook versions are limited to major.minor.patch
+	if v, nerr := semver.NewVersion(nextVersion); nerr != nil {
+		return nerr
+	} else if v.Prerelease() != "" || v.Metadata() != "" {
+		return errors.EngineBuildPackageInvalid(fmt.Sprintf("Chef cookbooks do not support prerelease or build metadata versions (%s)", nextVersion))
+	}
+
+	// the version metadata path may be the cookbook directory, or the metadata.rb file itself.
+	metadataPath := versionMetadataPath
+	if path.Base(versionMetadataPath) != "metadata.rb" {
+		metadataPath = path.Join(versionMetadataPath, "metadata.rb")
+	}
+
+	// update the version in place (the same way `knife spork bump` does), so that the change can be staged.
+	metadataContent, rerr := g.readFile(metadataPath)
+	if rerr != nil {
+		return rerr
+	}
+	if !chefVersionPattern.Match(metadataContent) {
+		return errors.EngineBuildPackageFailed(fmt.Sprintf("Could not find the version in %s", metadataPath))
+	}
+	updatedContent := chefVersionPattern.ReplaceAll(metadataContent, []byte("${1}"+nextVersion+"${3}"))
+	return g.writeFile(metadataPath, updatedContent, 0644)
 }
+
+// the `version '1.2.3'` declaration in metadata.rb
+var chefVersionPattern = regexp.MustCompile(`(?m)^(\s*version\s*\(?\s*['"])([^'"]*)(['"])`)
@@ -92,6 +92,7 @@ func (suite *EngineChefTestSuite) TestEngineChef_BumpVersion() {
 	//setup
 	//suite.Config.EXPECT().SetDefault(gomock.Any(), gomock.Any()).MinTimes(1)
 	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("patch").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
 
 	//copy cookbook fixture into a temp directory.
 	parentPath, err := ioutil.TempDir("", "")
@@ -118,6 +119,7 @@ func (suite *EngineChefTestSuite) TestEngineChef_BumpVersion_WithMinimalCookbook
 	//setup
 	//suite.Config.EXPECT().SetDefault(gomock.Any(), gomock.Any()).MinTimes(1)
 	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("patch").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
 
 	//copy cookbook fixture into a temp directory.
 	parentPath, err := ioutil.TempDir("", "")
@@ -163,3 +165,21 @@ func (suite *EngineChefTestSuite) TestEngineChef_BumpVersion_WithoutMetadata() {
 	//assert
 	require.Error(suite.T(), berr, "should return an error")
 }
+
+func TestEngineChef_GetVersion(t *testing.T) {
+	//setup
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+	pipelineData := new(pipeline.Data)
+	pipelineData.GitLocalPath = t.TempDir()
+	require.NoError(t, ioutil.WriteFile(path.Join(pipelineData.GitLocalPath, "metadata.rb"), []byte("name 'acme'\nversion '0.3.1'\n"), 0644))
+	chefEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_CHEF, pipelineData, testConfig, nil)
+	require.NoError(t, err)
+
+	//test
+	version, err := chefEngine.(engine.VersionReader).GetVersion(path.Join(pipelineData.GitLocalPath, "metadata.rb"))
+
+	//assert
+	require.NoError(t, err)
+	require.Equal(t, "0.3.1", version)
+}
@@ -0,0 +1,340 @@
+package engine
+
+import (
+	"fmt"
+	"github.com/Masterminds/semver"
+	"github.com/analogj/go-util/utils"
+	"github.com/packagrio/bumpr/pkg/config"
+	"github.com/packagrio/go-common/errors"
+	"github.com/packagrio/go-common/pipeline"
+	"github.com/packagrio/go-common/scm"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const dotnetDirectoryBuildProps = "Directory.Build.props"
+
+// the MSBuild project files that can set the version
+var dotnetProjectPatterns = []string{"*.csproj", "*.fsproj", "*.vbproj"}
+
+// DotnetMetadata is the metadata of a .NET project (go-common does not define a .NET metadata type).
+type DotnetMetadata struct {
+	PackageId string
+	Version   string
+}
+
+// a property that references other properties (eg. `$(VersionPrefix)-$(VersionSuffix)`)
+var dotnetPropertyReferencePattern = regexp.MustCompile(`\$\(`)
+
+// engineDotnet reads & writes the version properties of a .NET project: `<Version>`, or `<VersionPrefix>` &
+// `<VersionSuffix>`, in the project file or the Directory.Build.props file it imports. `<AssemblyVersion>` &
+// `<FileVersion>` are kept in sync. The files are edited in place, so the `dotnet` SDK is not required.
+type engineDotnet struct {
+	engineBase
+
+	Scm             scm.Interface //Interface
+	CurrentMetadata *DotnetMetadata
+	NextMetadata    *DotnetMetadata
+}
+
+func (g *engineDotnet) Init(pipelineData *pipeline.Data, configData config.Interface, sourceScm scm.Interface) error {
+	g.Scm = sourceScm
+	g.Config = configData
+	g.PipelineData = pipelineData
+	g.CurrentMetadata = new(DotnetMetadata)
+	g.NextMetadata = new(DotnetMetadata)
+
+	//set command defaults (can be overridden by repo/system configuration)
+	//the default version file is the project file, the version may be set by the Directory.Build.props it imports.
+	if projectFiles := dotnetProjectFiles(g.PipelineData.GitLocalPath); len(projectFiles) > 0 {
+		g.Config.SetDefault(config.PACKAGR_VERSION_METADATA_PATH, path.Base(projectFiles[0]))
+	} else {
+		g.Config.SetDefault(config.PACKAGR_VERSION_METADATA_PATH, dotnetDirectoryBuildProps)
+	}
+	return nil
+}
+
+func (g *engineDotnet) GetCurrentMetadata() interface{} {
+	return g.CurrentMetadata
+}
+func (g *engineDotnet) GetNextMetadata() interface{} {
+	return g.NextMetadata
+}
+func (g *engineDotnet) GetCurrentVersion() string {
+	return g.CurrentMetadata.Version
+}
+
+// ValidateTools is a no-op, the MSBuild files are updated without the `dotnet` SDK.
+func (g *engineDotnet) ValidateTools() error {
+	return nil
+}
+
+func (g *engineDotnet) DetectPackage(gitLocalPath string, configImpl config.Interface) (bool, string) {
+	if projectFiles := dotnetProjectFiles(gitLocalPath); len(projectFiles) > 0 {
+		return true, fmt.Sprintf("found %s", path.Base(projectFiles[0]))
+	}
+	if utils.FileExists(path.Join(gitLocalPath, dotnetDirectoryBuildProps)) {
+		return true, fmt.Sprintf("found %s", dotnetDirectoryBuildProps)
+	}
+	return false, ""
+}
+
+func (g *engineDotnet) BumpVersion() error {
+	versionMetadataPath := path.Join(g.PipelineData.GitLocalPath, g.Config.GetString(config.PACKAGR_VERSION_METADATA_PATH))
+	versionFile, ok := g.versionFile(versionMetadataPath)
+	if !ok {
+		return errors.EngineBuildPackageInvalid("a .csproj or Directory.Build.props file that sets the version is required to process .NET project")
+	}
+
+	if merr := g.retrieveCurrentMetadata(versionMetadataPath, versionFile); merr != nil {
+		return merr
+	}
+
+	if perr := g.populateNextMetadata(); perr != nil {
+		return perr
+	}
+
+	if nerr := g.SetVersion(versionMetadataPath, g.NextMetadata.Version); nerr != nil {
+		return nerr
+	}
+
+	return nil
+}
+
+// SetVersion updates the effective version property of a project (the path is a project file, a Directory.Build.props
+// file, or a directory), and the `<AssemblyVersion>` & `<FileVersion>` of the project & Directory.Build.props files.
+func (g *engineDotnet) SetVersion(versionMetadataPath string, nextVersion string) error {
+	versionFile, ok := g.versionFile(versionMetadataPath)
+	if !ok {
+		return errors.EngineBuildPackageFailed(fmt.Sprintf("Could not find the version in %s", versionMetadataPath))
+	}
+	v, err := semver.NewVersion(nextVersion)
+	if err != nil {
+		return err
+	}
+	versionPrefix := fmt.Sprintf("%d.%d.%d", v.Major(), v.Minor(), v.Patch())
+	// the four part version required by AssemblyVersion & FileVersion
+	assemblyVersion := versionPrefix + ".0"
+
+	for _, msbuildFile := range g.msbuildFiles(versionMetadataPath) {
+		content, err := g.readFile(msbuildFile)
+		if err != nil {
+			return err
+		}
+		updatedContent := content
+		if msbuildFile == versionFile {
+			if updatedContent, err = setDotnetVersion(msbuildFile, updatedContent, nextVersion, versionPrefix, v.Prerelease()); err != nil {
+				return err
+			}
+		}
+		for _, property := range []string{"AssemblyVersion", "FileVersion"} {
+			if updatedContent, err = setDotnetProperty(updatedContent, property, assemblyVersion); err != nil {
+				return err
+			}
+		}
+		if string(updatedContent) != string(content) {
+			if err := g.writeFile(msbuildFile, updatedContent, 0644); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// GetVersion reads the effective version of a project (the path is a project file, a Directory.Build.props file, or a
+// directory).
+func (g *engineDotnet) GetVersion(versionMetadataPath string) (string, error) {
+	versionFile, ok := g.versionFile(versionMetadataPath)
+	if !ok {
+		return "", errors.EngineBuildPackageFailed(fmt.Sprintf("Could not find the version in %s", versionMetadataPath))
+	}
+	content, err := g.readFile(versionFile)
+	if err != nil {
+		return "", err
+	}
+	version, _, err := dotnetVersion(content)
+	return version, err
+}
+
+//private Helpers
+
+func (g *engineDotnet) retrieveCurrentMetadata(versionMetadataPath string, versionFile string) error {
+	content, err := g.readFile(versionFile)
+	if err != nil {
+		return err
+	}
+	if g.CurrentMetadata.Version, _, err = dotnetVersion(content); err != nil {
+		return err
+	}
+
+	// the PackageId defaults to the project file name
+	for _, msbuildFile := range g.msbuildFiles(versionMetadataPath) {
+		msbuildContent, err := g.readFile(msbuildFile)
+		if err != nil {
+			return err
+		}
+		if packageId, ok, err := findXmlElement(msbuildContent, "Project", "PropertyGroup", "PackageId"); err != nil {
+			return err
+		} else if ok && !dotnetPropertyReferencePattern.MatchString(packageId.Text) {
+			g.CurrentMetadata.PackageId = packageId.Text
+			return nil
+		}
+		if path.Base(msbuildFile) != dotnetDirectoryBuildProps {
+			g.CurrentMetadata.PackageId = strings.TrimSuffix(path.Base(msbuildFile), path.Ext(msbuildFile))
+			return nil
+		}
+	}
+	return nil
+}
+
+func (g *engineDotnet) populateNextMetadata() error {
+
+	currentVersion, err := g.ResolveCurrentVersion(g.CurrentMetadata.Version)
+	if err != nil {
+		return err
+	}
+
+	nextVersion, err := g.GenerateNextVersion(currentVersion)
+	if err != nil {
+		return err
+	}
+
+	g.NextMetadata.Version = nextVersion
+	g.NextMetadata.PackageId = g.CurrentMetadata.PackageId
+	g.PipelineData.ReleaseVersion = g.NextMetadata.Version
+	return nil
+}
+
+// msbuildFiles returns the (existing) files that define the version of a project, in MSBuild evaluation order (reversed): the
+// project file, and the nearest Directory.Build.props file, which is imported before the project properties.
+func (g *engineDotnet) msbuildFiles(versionMetadataPath string) []string {
+	msbuildFiles := []string{}
+	projectDir := versionMetadataPath
+	if info, err := os.Stat(versionMetadataPath); err == nil && info.IsDir() {
+		msbuildFiles = append(msbuildFiles, dotnetProjectFiles(versionMetadataPath)...)
+	} else if path.Base(versionMetadataPath) == dotnetDirectoryBuildProps && utils.FileExists(versionMetadataPath) {
+		return []string{versionMetadataPath}
+	} else if utils.FileExists(versionMetadataPath) {
+		msbuildFiles = append(msbuildFiles, versionMetadataPath)
+		projectDir = path.Dir(versionMetadataPath)
+	} else {
+		return msbuildFiles
+	}
+
+	for dir := projectDir; ; dir = path.Dir(dir) {
+		if utils.FileExists(path.Join(dir, dotnetDirectoryBuildProps)) {
+			return append(msbuildFiles, path.Join(dir, dotnetDirectoryBuildProps))
+		} else if dir == path.Dir(dir) || utils.FileExists(path.Join(dir, ".git")) {
+			return msbuildFiles
+		}
+	}
+}
+
+// versionFile returns the first of the msbuildFiles that sets the version, the properties of the project file override
+// the properties of Directory.Build.props.
+func (g *engineDotnet) versionFile(versionMetadataPath string) (string, bool) {
+	for _, msbuildFile := range g.msbuildFiles(versionMetadataPath) {
+		content, err := g.readFile(msbuildFile)
+		if err != nil {
+			continue
+		}
+		if _, _, err := dotnetVersion(content); err == nil {
+			return msbuildFile, true
+		}
+	}
+	return "", false
+}
+
+// dotnetVersion returns the version set by the file, and the property that sets it: `Version`, or `VersionPrefix` (with
+// the optional `VersionSuffix`) when `Version` is not set, is empty, or references other properties.
+func dotnetVersion(content []byte) (string, string, error) {
+	version, ok, err := findXmlElement(content, "Project", "PropertyGroup", "Version")
+	if err != nil {
+		return "", "", err
+	} else if ok && version.Text != "" && !dotnetPropertyReferencePattern.MatchString(version.Text) {
+		return version.Text, "Version", nil
+	}
+
+	versionPrefix, ok, err := findXmlElement(content, "Project", "PropertyGroup", "VersionPrefix")
+	if err != nil {
+		return "", "", err
+	} else if !ok || dotnetPropertyReferencePattern.MatchString(versionPrefix.Text) {
+		return "", "", errors.EngineBuildPackageFailed("Could not find the <Version> or <VersionPrefix> property")
+	}
+	versionSuffix, ok, err := findXmlElement(content, "Project", "PropertyGroup", "VersionSuffix")
+	if err != nil {
+		return "", "", err
+	} else if ok && versionSuffix.Text != "" && !dotnetPropertyReferencePattern.MatchString(versionSuffix.Text) {
+		return versionPrefix.Text + "-" + versionSuffix.Text, "VersionPrefix", nil
+	}
+	return versionPrefix.Text, "VersionPrefix", nil
+}
+
+// setDotnetVersion updates the property that sets the version. When the version is split into `VersionPrefix` &
+// `VersionSuffix`, a `VersionSuffix` element is added (after `VersionPrefix`) for prerelease versions if required.
+func setDotnetVersion(msbuildFile string, content []byte, nextVersion string, versionPrefix string, versionSuffix string) ([]byte, error) {
+	_, property, err := dotnetVersion(content)
+	if err != nil {
+		return nil, errors.EngineBuildPackageFailed(fmt.Sprintf("%s in %s", err, msbuildFile))
+	}
+	if property == "Version" {
+		return setDotnetProperty(content, "Version", nextVersion)
+	}
+
+	if content, err = setDotnetProperty(content, "VersionPrefix", versionPrefix); err != nil {
+		return nil, err
+	}
+	if _, ok, err := findXmlElement(content, "Project", "PropertyGroup", "VersionSuffix"); err != nil {
+		return nil, err
+	} else if ok {
+		return setDotnetProperty(content, "VersionSuffix", versionSuffix)
+	} else if versionSuffix == "" {
+		return content, nil
+	}
+
+	// the VersionSuffix element is added on the line after VersionPrefix, with the same indentation
+	prefixElement, _, err := findXmlElement(content, "Project", "PropertyGroup", "VersionPrefix")
+	if err != nil {
+		return nil, err
+	}
+	lineStart := strings.LastIndex(string(content[:prefixElement.start]), "\n") + 1
+	linePrefix := string(content[lineStart:prefixElement.start])
+	indent := linePrefix[:len(linePrefix)-len(strings.TrimLeft(linePrefix, " \t"))]
+	// the end of the </VersionPrefix> closing tag
+	lineEnd := prefixElement.end + strings.Index(string(content[prefixElement.end:]), ">") + 1
+	suffixElement := fmt.Sprintf("\n%s<VersionSuffix>%s</VersionSuffix>", indent, versionSuffix)
+	updated := append([]byte{}, content[:lineEnd]...)
+	updated = append(updated, suffixElement...)
+	return append(updated, content[lineEnd:]...), nil
+}
+
+// setDotnetProperty sets the value of every literal (that doesn't reference other properties) occurrence of the
+// property, eg. in conditional property groups.
+func setDotnetProperty(content []byte, property string, value string) ([]byte, error) {
+	elements, err := findXmlElements(content, "Project", "PropertyGroup", property)
+	if err != nil {
+		return nil, err
+	}
+	// replaced in reverse order, so that the offsets of the previous elements are unchanged
+	for ndx := len(elements) - 1; ndx >= 0; ndx-- {
+		if !dotnetPropertyReferencePattern.MatchString(elements[ndx].Text) {
+			content = replaceXmlElementText(content, elements[ndx], value)
+		}
+	}
+	return content, nil
+}
+
+// dotnetProjectFiles returns the MSBuild project files in the directory, sorted by name.
+func dotnetProjectFiles(dirPath string) []string {
+	projectFiles := []string{}
+	for _, pattern := range dotnetProjectPatterns {
+		matches, _ := filepath.Glob(path.Join(dirPath, pattern))
+		projectFiles = append(projectFiles, matches...)
+	}
+	sort.Strings(projectFiles)
+	return projectFiles
+}
@@ -0,0 +1,307 @@
+//go:build dotnet
+// +build dotnet
+
+package engine_test
+
+import (
+	"github.com/analogj/go-util/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/packagrio/bumpr/pkg/config"
+	"github.com/packagrio/bumpr/pkg/config/mock"
+	"github.com/packagrio/bumpr/pkg/engine"
+	"github.com/packagrio/go-common/pipeline"
+	"github.com/packagrio/go-common/scm"
+	"github.com/packagrio/go-common/scm/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestEngineDotnet_Create(t *testing.T) {
+	//setup
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+
+	testConfig.Set(config.PACKAGR_SCM, "github")
+	testConfig.Set(config.PACKAGR_PACKAGE_TYPE, "dotnet")
+	pipelineData := new(pipeline.Data)
+	githubScm, err := scm.Create("github", pipelineData, testConfig, &http.Client{})
+	require.NoError(t, err)
+
+	//test
+	dotnetEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_DOTNET, pipelineData, testConfig, githubScm)
+
+	//assert
+	require.NoError(t, err)
+	require.NotNil(t, dotnetEngine)
+}
+
+// Define the suite, and absorb the built-in basic suite
+// functionality from testify - including a T() method which
+// returns the current testing context
+type EngineDotnetTestSuite struct {
+	suite.Suite
+	MockCtrl     *gomock.Controller
+	Scm          *mock_scm.MockInterface
+	Config       *mock_config.MockInterface
+	PipelineData *pipeline.Data
+}
+
+// Make sure that VariableThatShouldStartAtFive is set to five
+// before each test
+func (suite *EngineDotnetTestSuite) SetupTest() {
+	suite.MockCtrl = gomock.NewController(suite.T())
+
+	suite.PipelineData = new(pipeline.Data)
+
+	suite.Config = mock_config.NewMockInterface(suite.MockCtrl)
+	suite.Scm = mock_scm.NewMockInterface(suite.MockCtrl)
+
+}
+
+func (suite *EngineDotnetTestSuite) TearDownTest() {
+	suite.MockCtrl.Finish()
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestEngineDotnet_TestSuite(t *testing.T) {
+	suite.Run(t, new(EngineDotnetTestSuite))
+}
+
+func (suite *EngineDotnetTestSuite) TestEngineDotnet_ValidateTools() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "Directory.Build.props")
+	dotnetEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_DOTNET, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	verr := dotnetEngine.ValidateTools()
+
+	//assert
+	require.NoError(suite.T(), verr, "should not require the dotnet SDK")
+}
+
+func (suite *EngineDotnetTestSuite) TestEngineDotnet_BumpVersion() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "Acme.Core.csproj")
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_METADATA_PATH).Return("Acme.Core.csproj").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("minor").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
+
+	//copy fixture into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "csproj_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "dotnet", "csproj_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	dotnetEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_DOTNET, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := dotnetEngine.BumpVersion()
+	require.NoError(suite.T(), berr)
+
+	//assert
+	require.Equal(suite.T(), &engine.DotnetMetadata{PackageId: "Acme.Core", Version: "1.4.2"}, dotnetEngine.GetCurrentMetadata())
+	require.Equal(suite.T(), &engine.DotnetMetadata{PackageId: "Acme.Core", Version: "1.5.0"}, dotnetEngine.GetNextMetadata())
+	csproj, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "Acme.Core.csproj"))
+	require.NoError(suite.T(), err)
+	expectedCsproj, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "Acme.Core.csproj.expected"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), string(expectedCsproj), string(csproj), "should only update the version properties, preserving the formatting")
+}
+
+func (suite *EngineDotnetTestSuite) TestEngineDotnet_BumpVersion_DirectoryBuildProps() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "Directory.Build.props")
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_METADATA_PATH).Return("src/Acme.Cli/Acme.Cli.csproj").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("release").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
+
+	//copy fixture into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "directory_build_props_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "dotnet", "directory_build_props_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	dotnetEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_DOTNET, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := dotnetEngine.BumpVersion()
+	require.NoError(suite.T(), berr)
+
+	//assert
+	require.Equal(suite.T(), &engine.DotnetMetadata{PackageId: "Acme.Cli", Version: "2.0.0-beta.1"}, dotnetEngine.GetCurrentMetadata(), "should read the version of the imported Directory.Build.props")
+	directoryBuildProps, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "Directory.Build.props"))
+	require.NoError(suite.T(), err)
+	expectedDirectoryBuildProps, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "Directory.Build.props.expected"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), string(expectedDirectoryBuildProps), string(directoryBuildProps))
+	csproj, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "src", "Acme.Cli", "Acme.Cli.csproj"))
+	require.NoError(suite.T(), err)
+	require.Contains(suite.T(), string(csproj), "<FileVersion>2.0.0.0</FileVersion>")
+}
+
+func (suite *EngineDotnetTestSuite) TestEngineDotnet_BumpVersion_VersionSuffix() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "Directory.Build.props")
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_METADATA_PATH).Return("Directory.Build.props").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("preminor").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_PRERELEASE_ID).Return("rc").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
+
+	//copy fixture into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "version_suffix_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "dotnet", "version_suffix_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	dotnetEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_DOTNET, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := dotnetEngine.BumpVersion()
+	require.NoError(suite.T(), berr)
+
+	//assert
+	require.Equal(suite.T(), "0.4.0-rc.1", dotnetEngine.GetNextMetadata().(*engine.DotnetMetadata).Version)
+	directoryBuildProps, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "Directory.Build.props"))
+	require.NoError(suite.T(), err)
+	expectedDirectoryBuildProps, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "Directory.Build.props.expected"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), string(expectedDirectoryBuildProps), string(directoryBuildProps), "should add the VersionSuffix property")
+}
+
+func (suite *EngineDotnetTestSuite) TestEngineDotnet_BumpVersion_ProjectOverride() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "Acme.Core.csproj")
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_METADATA_PATH).Return("Acme.Core.csproj").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("patch").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
+
+	//copy fixture into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "project_override_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "dotnet", "project_override_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	dotnetEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_DOTNET, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := dotnetEngine.BumpVersion()
+	require.NoError(suite.T(), berr)
+
+	//assert
+	require.Equal(suite.T(), "1.4.2", dotnetEngine.GetCurrentMetadata().(*engine.DotnetMetadata).Version, "the project properties should override Directory.Build.props")
+	csproj, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "Acme.Core.csproj"))
+	require.NoError(suite.T(), err)
+	require.Contains(suite.T(), string(csproj), "<Version>1.4.3</Version>")
+	directoryBuildProps, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "Directory.Build.props"))
+	require.NoError(suite.T(), err)
+	originalDirectoryBuildProps, err := ioutil.ReadFile(path.Join("testdata", "dotnet", "project_override_analogj_test", "Directory.Build.props"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), string(originalDirectoryBuildProps), string(directoryBuildProps))
+}
+
+func (suite *EngineDotnetTestSuite) TestEngineDotnet_GetVersion() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "Directory.Build.props")
+	suite.PipelineData.GitLocalPath = path.Join("testdata", "dotnet", "solution_analogj_test")
+
+	dotnetEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_DOTNET, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+	versionReader := dotnetEngine.(engine.VersionReader)
+
+	//test
+	coreVersion, cerr := versionReader.GetVersion(path.Join(suite.PipelineData.GitLocalPath, "src", "Acme.Core"))
+	cliVersion, lerr := versionReader.GetVersion(path.Join(suite.PipelineData.GitLocalPath, "src", "Acme.Cli", "Acme.Cli.csproj"))
+
+	//assert
+	require.NoError(suite.T(), cerr)
+	require.Equal(suite.T(), "1.4.2", coreVersion)
+	require.NoError(suite.T(), lerr)
+	require.Equal(suite.T(), "2.0.0-beta.1", cliVersion)
+}
+
+func (suite *EngineDotnetTestSuite) TestEngineDotnet_BumpVersion_SelfClosingVersionSuffix() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "Acme.Core.csproj")
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_METADATA_PATH).Return("Acme.Core.csproj").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("prepatch").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_PRERELEASE_ID).Return("rc").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
+
+	//copy fixture into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "self_closing_version_suffix_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "dotnet", "self_closing_version_suffix_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	dotnetEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_DOTNET, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := dotnetEngine.BumpVersion()
+	require.NoError(suite.T(), berr)
+
+	//assert
+	require.Equal(suite.T(), "1.4.3-rc.1", dotnetEngine.GetNextMetadata().(*engine.DotnetMetadata).Version)
+	csproj, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "Acme.Core.csproj"))
+	require.NoError(suite.T(), err)
+	expectedCsproj, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "Acme.Core.csproj.expected"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), string(expectedCsproj), string(csproj), "should expand the self-closing VersionSuffix element")
+}
+
+func (suite *EngineDotnetTestSuite) TestEngineDotnet_BumpVersion_SelfClosingAssemblyVersion() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "Acme.Core.csproj")
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_METADATA_PATH).Return("Acme.Core.csproj").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("patch").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
+
+	//copy fixture into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "self_closing_assembly_version_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "dotnet", "self_closing_assembly_version_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	dotnetEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_DOTNET, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := dotnetEngine.BumpVersion()
+	require.NoError(suite.T(), berr)
+
+	//assert
+	csproj, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "Acme.Core.csproj"))
+	require.NoError(suite.T(), err)
+	expectedCsproj, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "Acme.Core.csproj.expected"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), string(expectedCsproj), string(csproj), "should expand the self-closing AssemblyVersion & FileVersion elements")
+}
@@ -2,6 +2,7 @@ package engine
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"github.com/Masterminds/semver"
 	"github.com/analogj/go-util/utils"
@@ -10,11 +11,16 @@ import (
 	"github.com/packagrio/go-common/metadata"
 	"github.com/packagrio/go-common/pipeline"
 	"github.com/packagrio/go-common/scm"
-	"os"
 	"path"
+	"regexp"
 	"strings"
 )
 
+// the version template placeholder, and the semver pattern it is matched against when reading the version file.
+const genericVersionMetadataPath = "VERSION"
+const genericVersionPlaceholder = "%d.%d.%d"
+const genericVersionPattern = `(\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?)`
+
 type engineGeneric struct {
 	engineBase
 
@@ -32,7 +38,7 @@ func (g *engineGeneric) Init(pipelineData *pipeline.Data, configData config.Inte
 
 	//set command defaults (can be overridden by repo/system configuration)
 	g.Config.SetDefault(config.PACKAGR_GENERIC_VERSION_TEMPLATE, `version := "%d.%d.%d"`)
-	g.Config.SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "VERSION")
+	g.Config.SetDefault(config.PACKAGR_VERSION_METADATA_PATH, genericVersionMetadataPath)
 	return nil
 }
 
@@ -42,11 +48,25 @@ func (g *engineGeneric) GetCurrentMetadata() interface{} {
 func (g *engineGeneric) GetNextMetadata() interface{} {
 	return g.NextMetadata
 }
+func (g *engineGeneric) GetCurrentVersion() string {
+	return g.CurrentMetadata.Version
+}
 
 func (g *engineGeneric) ValidateTools() error {
 	return nil
 }
 
+func (g *engineGeneric) DetectPackage(gitLocalPath string, configImpl config.Interface) (bool, string) {
+	versionMetadataPath := configImpl.GetString(config.PACKAGR_VERSION_METADATA_PATH)
+	if versionMetadataPath == "" {
+		versionMetadataPath = genericVersionMetadataPath
+	}
+	if utils.FileExists(path.Join(gitLocalPath, versionMetadataPath)) {
+		return true, fmt.Sprintf("found %s", versionMetadataPath)
+	}
+	return false, ""
+}
+
 func (g *engineGeneric) BumpVersion() error {
 	//validate that the chef metadata.rb file exists
 
@@ -74,33 +94,31 @@ func (g *engineGeneric) SetVersion(versionMetadataPath string, nextVersion strin
 	return g.writeNextMetadata(versionMetadataPath, nextVersion)
 }
 
-// Helpers
-func (g *engineGeneric) retrieveCurrentMetadata(gitLocalPath string) error {
-	//read VERSION file.
-	filePath := path.Join(gitLocalPath, g.Config.GetString(config.PACKAGR_VERSION_METADATA_PATH))
+// GetVersion reads the version from a version file, using the generic version template.
+func (g *engineGeneric) GetVersion(versionMetadataPath string) (string, error) {
 	template := g.Config.GetString(config.PACKAGR_GENERIC_VERSION_TEMPLATE)
 
 	// Handle if the user wants to merge the version file and not overwrite it
 	if g.Config.GetBool(config.PACKAGR_GENERIC_MERGE_VERSION_FILE) {
-		versionContent, err := g.matchAsSingleLine(filePath, template)
-		if err != nil {
-			return err
-		}
-		g.CurrentMetadata.Version = versionContent
-		return nil
+		return g.matchAsSingleLine(versionMetadataPath, template)
 	}
+	return g.matchAsMultiLine(versionMetadataPath, template)
+}
 
-	match, err := g.matchAsMultiLine(filePath, template)
+// Helpers
+func (g *engineGeneric) retrieveCurrentMetadata(gitLocalPath string) error {
+	//read VERSION file.
+	version, err := g.GetVersion(path.Join(gitLocalPath, g.Config.GetString(config.PACKAGR_VERSION_METADATA_PATH)))
 	if err != nil {
 		return err
 	}
-	g.CurrentMetadata.Version = match
+	g.CurrentMetadata.Version = version
 	return nil
 }
 
 // Matches the template with the entire file, useful for simple version files
 func (g *engineGeneric) matchAsMultiLine(filePath string, template string) (string, error) {
-	versionContent, err := os.ReadFile(filePath)
+	versionContent, err := g.readFile(filePath)
 	if err != nil {
 		return "", err
 	}
@@ -109,11 +127,11 @@ func (g *engineGeneric) matchAsMultiLine(filePath string, template string) (stri
 
 // Only matches the version for a single line, used when you have a version on a single line within a complete multiline file
 func (g *engineGeneric) matchAsSingleLine(filePath string, template string) (string, error) {
-	fileReader, rerr := os.Open(filePath)
-	scanner := bufio.NewScanner(fileReader)
+	versionContent, rerr := g.readFile(filePath)
 	if rerr != nil {
 		return "", rerr
 	}
+	scanner := bufio.NewScanner(bytes.NewReader(versionContent))
 
 	for scanner.Scan() {
 		readLine := scanner.Text()
@@ -129,19 +147,64 @@ func (g *engineGeneric) matchAsSingleLine(filePath string, template string) (str
 }
 
 func (g *engineGeneric) getVersionFromString(versionContent string, template string) (string, error) {
-	major := 0
-	minor := 0
-	patch := 0
-	_, err := fmt.Sscanf(strings.TrimSpace(string(versionContent)), template, &major, &minor, &patch)
+	if !strings.Contains(template, genericVersionPlaceholder) {
+		// templates that split up the version components can only be matched as major/minor/patch integers.
+		major := 0
+		minor := 0
+		patch := 0
+		_, err := fmt.Sscanf(strings.TrimSpace(string(versionContent)), template, &major, &minor, &patch)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%d.%d.%d", major, minor, patch), nil
+	}
+
+	// the placeholder is replaced with a semver pattern, so that prerelease & build metadata suffixes are also matched.
+	templateParts := strings.SplitN(template, genericVersionPlaceholder, 2)
+	versionRegex, err := regexp.Compile("^" + g.templateToRegex(templateParts[0]) + genericVersionPattern + g.templateToRegex(templateParts[1]))
 	if err != nil {
 		return "", err
 	}
-	return fmt.Sprintf("%d.%d.%d", major, minor, patch), nil
+	matches := versionRegex.FindStringSubmatch(strings.TrimSpace(versionContent))
+	if matches == nil {
+		return "", errors.EngineUnspecifiedError(fmt.Sprintf("version content does not match the format `%s`", template))
+	}
+	return matches[1], nil
+}
+
+// converts the literal parts of a version template into a regex, whitespace is matched loosely (like fmt.Sscanf)
+func (g *engineGeneric) templateToRegex(templatePart string) string {
+	literal := regexp.QuoteMeta(strings.ReplaceAll(templatePart, "%%", "%"))
+	return regexp.MustCompile(`\s+`).ReplaceAllString(literal, `\s*`)
+}
+
+// renders the version template with the specified version, including any prerelease & build metadata suffixes.
+// Templates that split up the version components can't represent the suffixes, so they are rejected instead of dropped.
+func (g *engineGeneric) renderVersionTemplate(template string, version string) (string, error) {
+	if strings.Contains(template, genericVersionPlaceholder) {
+		return fmt.Sprintf(strings.Replace(template, genericVersionPlaceholder, "%s", 1), version), nil
+	}
+
+	v, nerr := semver.NewVersion(version)
+	if nerr != nil {
+		return "", nerr
+	}
+	if v.Prerelease() != "" || v.Metadata() != "" {
+		return "", errors.EngineBuildPackageInvalid(fmt.Sprintf(
+			"The version template `%s` can't represent the prerelease or build metadata of %s, use the `%s` placeholder", template, version, genericVersionPlaceholder,
+		))
+	}
+	return fmt.Sprintf(template, v.Major(), v.Minor(), v.Patch()), nil
 }
 
 func (g *engineGeneric) populateNextMetadata() error {
 
-	nextVersion, err := g.GenerateNextVersion(g.CurrentMetadata.Version)
+	currentVersion, err := g.ResolveCurrentVersion(g.CurrentMetadata.Version)
+	if err != nil {
+		return err
+	}
+
+	nextVersion, err := g.GenerateNextVersion(currentVersion)
 	if err != nil {
 		return err
 	}
@@ -153,26 +216,32 @@ func (g *engineGeneric) populateNextMetadata() error {
 
 func (g *engineGeneric) writeNextMetadata(gitLocalMetadataPath string, nextVersion string) error {
 
-	v, nerr := semver.NewVersion(nextVersion)
-	if nerr != nil {
+	if _, nerr := semver.NewVersion(nextVersion); nerr != nil {
 		return nerr
 	}
 
 	template := g.Config.GetString(config.PACKAGR_GENERIC_VERSION_TEMPLATE)
-	versionContent := fmt.Sprintf(template, v.Major(), v.Minor(), v.Patch())
+	versionContent, terr := g.renderVersionTemplate(template, nextVersion)
+	if terr != nil {
+		return terr
+	}
 	if g.Config.GetBool(config.PACKAGR_GENERIC_MERGE_VERSION_FILE) {
-		completeVersionContent, err := os.ReadFile(gitLocalMetadataPath)
+		completeVersionContent, err := g.readFile(gitLocalMetadataPath)
 		if err == nil {
-			oldVersion, err := semver.NewVersion(g.CurrentMetadata.Version)
+			// additional version files may have a different version than the primary version file (eg. `verify --fix`)
+			oldVersion, verr := g.matchAsSingleLine(gitLocalMetadataPath, template)
+			if verr != nil {
+				oldVersion = g.CurrentMetadata.Version
+			}
+			oldVersionContent, err := g.renderVersionTemplate(template, oldVersion)
 			if err != nil {
 				return err
 			}
-			oldVersionContent := fmt.Sprintf(template, oldVersion.Major(), oldVersion.Minor(), oldVersion.Patch())
 			versionContent = strings.Replace(string(completeVersionContent), oldVersionContent, versionContent, 1)
 		} else {
 			println(fmt.Sprintf("Error reading file for merge `%s` with error: `%s`, creating new one ", gitLocalMetadataPath, err.Error()))
 		}
 	}
 
-	return os.WriteFile(gitLocalMetadataPath, []byte(versionContent), 0644)
+	return g.writeFile(gitLocalMetadataPath, []byte(versionContent), 0644)
 }
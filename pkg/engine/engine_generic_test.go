@@ -6,6 +6,7 @@ package engine_test
 import (
 	"github.com/analogj/go-util/utils"
 	"github.com/golang/mock/gomock"
+	"github.com/packagrio/bumpr/pkg/changeset"
 	"github.com/packagrio/bumpr/pkg/config"
 	"github.com/packagrio/bumpr/pkg/engine"
 	"github.com/packagrio/go-common/metadata"
@@ -186,3 +187,135 @@ func (suite *EngineGenericTestSuite) TestEngineGeneric_BumpVersion_Template() {
 	require.Equal(suite.T(), "0.0.2", genericEngine.GetNextMetadata().(*metadata.GenericMetadata).Version)
 
 }
+
+func (suite *EngineGenericTestSuite) TestEngineGeneric_BumpVersion_Prerelease() {
+	//setup
+	suite.Config.Set(config.PACKAGR_GENERIC_MERGE_VERSION_FILE, "true")
+	suite.Config.Set(config.PACKAGR_VERSION_BUMP_TYPE, "prerelease")
+	suite.Config.Set(config.PACKAGR_VERSION_PRERELEASE_ID, "rc")
+	//copy into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "generic_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "generic", "generic_prerelease_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	genericEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_GENERIC, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := genericEngine.BumpVersion()
+	require.NoError(suite.T(), berr)
+
+	//assert
+	require.Equal(suite.T(), "1.4.0-rc.1", genericEngine.GetCurrentMetadata().(*metadata.GenericMetadata).Version)
+	require.Equal(suite.T(), "1.4.0-rc.2", genericEngine.GetNextMetadata().(*metadata.GenericMetadata).Version)
+	expected, err := os.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "VERSION_expected"))
+	require.NoError(suite.T(), err)
+	generated, err := os.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "VERSION"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), expected, generated)
+}
+
+func (suite *EngineGenericTestSuite) TestEngineGeneric_BumpVersion_Template_Preminor() {
+	//setup
+	suite.Config.Set(config.PACKAGR_GENERIC_VERSION_TEMPLATE, "%d.%d.%d")
+	suite.Config.Set(config.PACKAGR_VERSION_BUMP_TYPE, "preminor")
+	suite.Config.Set(config.PACKAGR_VERSION_PRERELEASE_ID, "rc")
+	//copy into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "generic_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "generic", "generic_template_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	genericEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_GENERIC, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := genericEngine.BumpVersion()
+	require.NoError(suite.T(), berr)
+
+	//assert
+	require.Equal(suite.T(), "0.1.0-rc.1", genericEngine.GetNextMetadata().(*metadata.GenericMetadata).Version)
+	generated, err := os.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "VERSION"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), "0.1.0-rc.1", string(generated))
+}
+
+func (suite *EngineGenericTestSuite) TestEngineGeneric_BumpVersion_SplitTemplate_Preminor() {
+	//setup
+	suite.Config.Set(config.PACKAGR_GENERIC_VERSION_TEMPLATE, "MAJOR=%d\nMINOR=%d\nPATCH=%d")
+	suite.Config.Set(config.PACKAGR_VERSION_BUMP_TYPE, "preminor")
+	suite.Config.Set(config.PACKAGR_VERSION_PRERELEASE_ID, "rc")
+	//copy into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "generic_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "generic", "generic_split_template_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	genericEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_GENERIC, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := genericEngine.BumpVersion()
+
+	//assert
+	require.Error(suite.T(), berr, "should not drop the prerelease suffix")
+	require.Equal(suite.T(), "1.2.3", genericEngine.GetCurrentMetadata().(*metadata.GenericMetadata).Version)
+	generated, err := os.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "VERSION"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), "MAJOR=1\nMINOR=2\nPATCH=3\n", string(generated))
+}
+
+func (suite *EngineGenericTestSuite) TestEngineGeneric_BumpVersion_ChangeSet() {
+	//copy into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "generic_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "generic", "generic_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+	original, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "VERSION"))
+	require.NoError(suite.T(), err)
+
+	genericEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_GENERIC, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+	changeSet := changeset.New()
+	genericEngine.SetChangeSet(changeSet)
+
+	//test
+	berr := genericEngine.BumpVersion()
+	require.NoError(suite.T(), berr)
+
+	//assert
+	require.Equal(suite.T(), "0.0.2", genericEngine.GetNextMetadata().(*metadata.GenericMetadata).Version)
+	onDisk, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "VERSION"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), string(original), string(onDisk), "should not modify the file until the change set is committed")
+	require.Len(suite.T(), changeSet.Changes(), 1)
+	require.Contains(suite.T(), changeSet.UnifiedDiff(suite.PipelineData.GitLocalPath), "+++ b/VERSION")
+}
+
+func TestEngineGeneric_GetVersion(t *testing.T) {
+	//setup
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+	pipelineData := new(pipeline.Data)
+	pipelineData.GitLocalPath = t.TempDir()
+	require.NoError(t, ioutil.WriteFile(path.Join(pipelineData.GitLocalPath, "version.txt"), []byte("version := \"1.4.0-rc.1\""), 0644))
+	genericEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_GENERIC, pipelineData, testConfig, nil)
+	require.NoError(t, err)
+
+	//test
+	version, err := genericEngine.(engine.VersionReader).GetVersion(path.Join(pipelineData.GitLocalPath, "version.txt"))
+
+	//assert
+	require.NoError(t, err)
+	require.Equal(t, "1.4.0-rc.1", version)
+}
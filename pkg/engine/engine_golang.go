@@ -3,6 +3,7 @@ package engine
 import (
 	"bytes"
 	"fmt"
+	"github.com/Masterminds/semver"
 	"github.com/analogj/go-util/utils"
 	"github.com/packagrio/bumpr/pkg/config"
 	"github.com/packagrio/go-common/errors"
@@ -13,13 +14,14 @@ import (
 	"go/format"
 	"go/parser"
 	"go/token"
-	"io/ioutil"
 	"os"
 	"os/exec"
 	"path"
 	"strings"
 )
 
+const golangVersionMetadataPath = "pkg/version/version.go"
+
 type engineGolang struct {
 	engineBase
 
@@ -36,7 +38,7 @@ func (g *engineGolang) Init(pipelineData *pipeline.Data, configData config.Inter
 	g.NextMetadata = new(metadata.GolangMetadata)
 
 	//set command defaults (can be overridden by repo/system configuration)
-	g.Config.SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "pkg/version/version.go")
+	g.Config.SetDefault(config.PACKAGR_VERSION_METADATA_PATH, golangVersionMetadataPath)
 	var scmDomain string
 	if g.Config.GetString(config.PACKAGR_SCM) == "bitbucket" {
 		scmDomain = "bitbucket.org"
@@ -78,6 +80,9 @@ func (g *engineGolang) GetCurrentMetadata() interface{} {
 func (g *engineGolang) GetNextMetadata() interface{} {
 	return g.NextMetadata
 }
+func (g *engineGolang) GetCurrentVersion() string {
+	return g.CurrentMetadata.Version
+}
 
 func (g *engineGolang) ValidateTools() error {
 	if _, kerr := exec.LookPath("go"); kerr != nil {
@@ -87,6 +92,17 @@ func (g *engineGolang) ValidateTools() error {
 	return nil
 }
 
+func (g *engineGolang) DetectPackage(gitLocalPath string, configImpl config.Interface) (bool, string) {
+	versionMetadataPath := configImpl.GetString(config.PACKAGR_VERSION_METADATA_PATH)
+	if versionMetadataPath == "" {
+		versionMetadataPath = golangVersionMetadataPath
+	}
+	if utils.FileExists(path.Join(gitLocalPath, "go.mod")) && utils.FileExists(path.Join(gitLocalPath, versionMetadataPath)) {
+		return true, fmt.Sprintf("found go.mod and %s", versionMetadataPath)
+	}
+	return false, ""
+}
+
 func (g *engineGolang) BumpVersion() error {
 	//validate that the chef metadata.rb file exists
 
@@ -114,13 +130,11 @@ func (g *engineGolang) SetVersion(versionMetadataPath string, nextVersion string
 	return g.writeNextMetadata(versionMetadataPath, nextVersion)
 }
 
-//private Helpers
-
-func (g *engineGolang) retrieveCurrentMetadata(gitLocalPath string) error {
-
-	versionContent, rerr := ioutil.ReadFile(path.Join(g.PipelineData.GitLocalPath, g.Config.GetString(config.PACKAGR_VERSION_METADATA_PATH)))
+// GetVersion reads the version constant from a go source file.
+func (g *engineGolang) GetVersion(versionMetadataPath string) (string, error) {
+	versionContent, rerr := g.readFile(versionMetadataPath)
 	if rerr != nil {
-		return rerr
+		return "", rerr
 	}
 
 	//Oh.My.God.
@@ -129,10 +143,16 @@ func (g *engineGolang) retrieveCurrentMetadata(gitLocalPath string) error {
 	fset := token.NewFileSet() // positions are relative to fset
 	f, err := parser.ParseFile(fset, "", string(versionContent), 0)
 	if err != nil {
-		return err
+		return "", err
 	}
+	return g.parseGoVersion(f.Decls)
+}
+
+//private Helpers
+
+func (g *engineGolang) retrieveCurrentMetadata(gitLocalPath string) error {
 
-	version, verr := g.parseGoVersion(f.Decls)
+	version, verr := g.GetVersion(path.Join(g.PipelineData.GitLocalPath, g.Config.GetString(config.PACKAGR_VERSION_METADATA_PATH)))
 	if verr != nil {
 		return verr
 	}
@@ -143,7 +163,12 @@ func (g *engineGolang) retrieveCurrentMetadata(gitLocalPath string) error {
 
 func (g *engineGolang) populateNextMetadata() error {
 
-	nextVersion, err := g.GenerateNextVersion(g.CurrentMetadata.Version)
+	currentVersion, err := g.ResolveCurrentVersion(g.CurrentMetadata.Version)
+	if err != nil {
+		return err
+	}
+
+	nextVersion, err := g.GenerateNextVersion(currentVersion)
 	if err != nil {
 		return err
 	}
@@ -155,7 +180,7 @@ func (g *engineGolang) populateNextMetadata() error {
 
 func (g *engineGolang) writeNextMetadata(gitLocalMetadataPath string, nextVersion string) error {
 	versionPath := gitLocalMetadataPath
-	versionContent, rerr := ioutil.ReadFile(versionPath)
+	versionContent, rerr := g.readFile(versionPath)
 	if rerr != nil {
 		return rerr
 	}
@@ -181,7 +206,7 @@ func (g *engineGolang) writeNextMetadata(gitLocalMetadataPath string, nextVersio
 		return err
 	}
 
-	return ioutil.WriteFile(versionPath, buf.Bytes(), 0644)
+	return g.writeFile(versionPath, buf.Bytes(), 0644)
 }
 
 func (g *engineGolang) parseGoVersion(list []ast.Decl) (string, error) {
@@ -218,3 +243,110 @@ func (g *engineGolang) setGoVersion(list []ast.Decl, version string) ([]ast.Decl
 	}
 	return nil, errors.EngineBuildPackageFailed(fmt.Sprintf("Could not set the version in %s", g.Config.GetString(config.PACKAGR_VERSION_METADATA_PATH)))
 }
+
+// PackageName returns the module path declared in go.mod.
+func (g *engineGolang) PackageName() (string, error) {
+	goMod, err := g.readGoMod()
+	if goMod == nil || err != nil {
+		return "", err
+	}
+	return goMod.module, nil
+}
+
+// Dependencies returns the module paths required in go.mod.
+func (g *engineGolang) Dependencies() ([]string, error) {
+	goMod, err := g.readGoMod()
+	if goMod == nil || err != nil {
+		return []string{}, err
+	}
+	dependencies := []string{}
+	for _, require := range goMod.requires {
+		dependencies = append(dependencies, require.path)
+	}
+	return dependencies, nil
+}
+
+// SetDependencyVersion updates the required version of a module in go.mod. go.sum is not updated, `go mod tidy` must be
+// run once the dependency has been released. A major version (v2+) change is rejected, as it requires the `/vN` module
+// path suffix (in the dependency's go.mod & in the imports), which is not updated.
+func (g *engineGolang) SetDependencyVersion(dependencyName string, version string) error {
+	goMod, err := g.readGoMod()
+	if goMod == nil || err != nil {
+		return err
+	}
+	nextVersion, err := semver.NewVersion(version)
+	if err != nil {
+		return err
+	}
+
+	lines := strings.SplitAfter(goMod.content, "\n")
+	for _, require := range goMod.requires {
+		if require.path != dependencyName {
+			continue
+		}
+		updatedVersion, ok := updateVersionSpecifier(require.version, version)
+		if !ok {
+			continue
+		}
+		if requiredVersion, err := semver.NewVersion(require.version); err == nil && nextVersion.Major() >= 2 && nextVersion.Major() != requiredVersion.Major() {
+			return errors.EngineBuildPackageFailed(fmt.Sprintf("Could not update the %s dependency to %s, the major version requires the /v%d module path suffix", dependencyName, version, nextVersion.Major()))
+		}
+		// the version is the last field of the directive (ignoring comments), the module path may contain the same text.
+		line := lines[require.line]
+		versionIndex := strings.LastIndex(stripGoModComment(line), require.version)
+		lines[require.line] = line[:versionIndex] + updatedVersion + line[versionIndex+len(require.version):]
+	}
+	return g.writeFile(path.Join(g.PipelineData.GitLocalPath, "go.mod"), []byte(strings.Join(lines, "")), 0644)
+}
+
+type goModRequire struct {
+	path    string
+	version string
+	line    int
+}
+
+type goModFile struct {
+	content  string
+	module   string
+	requires []goModRequire
+}
+
+// readGoMod parses the module path & require directives (single line & block form) of go.mod, returns nil if the
+// package does not have a go.mod file.
+func (g *engineGolang) readGoMod() (*goModFile, error) {
+	goModPath := path.Join(g.PipelineData.GitLocalPath, "go.mod")
+	if !utils.FileExists(goModPath) {
+		return nil, nil
+	}
+	content, err := g.readFile(goModPath)
+	if err != nil {
+		return nil, err
+	}
+
+	goMod := &goModFile{content: string(content)}
+	inRequireBlock := false
+	for ndx, line := range strings.SplitAfter(goMod.content, "\n") {
+		fields := strings.Fields(stripGoModComment(line))
+		switch {
+		case len(fields) == 0:
+		case inRequireBlock && fields[0] == ")":
+			inRequireBlock = false
+		case inRequireBlock && len(fields) == 2:
+			goMod.requires = append(goMod.requires, goModRequire{path: strings.Trim(fields[0], `"`), version: fields[1], line: ndx})
+		case fields[0] == "module" && len(fields) == 2:
+			goMod.module = strings.Trim(fields[1], `"`)
+		case (fields[0] == "require" && len(fields) == 2 && fields[1] == "(") || (fields[0] == "require(" && len(fields) == 1):
+			inRequireBlock = true
+		case fields[0] == "require" && len(fields) == 3:
+			goMod.requires = append(goMod.requires, goModRequire{path: strings.Trim(fields[1], `"`), version: fields[2], line: ndx})
+		}
+	}
+	return goMod, nil
+}
+
+func stripGoModComment(line string) string {
+	if commentIndex := strings.Index(line, "//"); commentIndex >= 0 {
+		return line[:commentIndex]
+	}
+	return line
+}
@@ -96,6 +96,7 @@ func (suite *EngineGolangTestSuite) TestEngineGolang_BumpVersion() {
 	//setup
 	suite.Config.EXPECT().SetDefault(gomock.Any(), gomock.Any()).MinTimes(1)
 	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("patch").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
 	suite.Config.EXPECT().GetString(config.PACKAGR_SCM).Return("github").MinTimes(1)
 	suite.Config.EXPECT().GetString("scm_repo_full_name").Return("AnalogJ/golang_analogj_test").MinTimes(1)
 	suite.Config.EXPECT().GetString("engine_golang_package_path").Return("github.com/analogj/golang_analogj_test").MinTimes(1)
@@ -125,6 +126,7 @@ func (suite *EngineGolangTestSuite) TestEngineGolang_BumpVersion_WithMinimalRepo
 	//setup
 	suite.Config.EXPECT().SetDefault(gomock.Any(), gomock.Any()).MinTimes(1)
 	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("patch").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
 	suite.Config.EXPECT().GetString(config.PACKAGR_SCM).Return("github").MinTimes(1)
 	suite.Config.EXPECT().GetString("scm_repo_full_name").Return("AnalogJ/golang_analogj_test").MinTimes(1)
 	suite.Config.EXPECT().GetString("engine_golang_package_path").Return("github.com/analogj/golang_analogj_test").MinTimes(1)
@@ -177,3 +179,103 @@ func (suite *EngineGolangTestSuite) TestEngineGolang_BumpVersion_WithoutVersion(
 	//assert
 	require.Error(suite.T(), berr, "should return an error")
 }
+
+func TestEngineGolang_Dependencies(t *testing.T) {
+	//setup
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+	pipelineData := new(pipeline.Data)
+	pipelineData.GitParentPath = t.TempDir()
+	pipelineData.GitLocalPath = path.Join(pipelineData.GitParentPath, "cli")
+	require.NoError(t, os.MkdirAll(pipelineData.GitLocalPath, 0755))
+	require.NoError(t, ioutil.WriteFile(path.Join(pipelineData.GitLocalPath, "go.mod"), []byte(`module github.com/acme/cli
+
+go 1.18
+
+require github.com/acme/sdk v1.0.0
+
+require (
+	github.com/acme/api v1.2.0 // indirect
+	github.com/stretchr/testify v1.8.0
+)
+
+replace github.com/acme/api v1.2.0 => ../api
+`), 0644))
+
+	golangEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_GOLANG, pipelineData, testConfig, nil)
+	require.NoError(t, err)
+	dependencyManager := golangEngine.(engine.DependencyManager)
+
+	//test
+	packageName, nerr := dependencyManager.PackageName()
+	dependencies, derr := dependencyManager.Dependencies()
+	require.NoError(t, dependencyManager.SetDependencyVersion("github.com/acme/sdk", "1.1.0"))
+	require.NoError(t, dependencyManager.SetDependencyVersion("github.com/acme/api", "1.2.1"))
+
+	//assert
+	require.NoError(t, nerr)
+	require.Equal(t, "github.com/acme/cli", packageName)
+	require.NoError(t, derr)
+	require.Equal(t, []string{"github.com/acme/sdk", "github.com/acme/api", "github.com/stretchr/testify"}, dependencies)
+	content, err := ioutil.ReadFile(path.Join(pipelineData.GitLocalPath, "go.mod"))
+	require.NoError(t, err)
+	require.Equal(t, `module github.com/acme/cli
+
+go 1.18
+
+require github.com/acme/sdk v1.1.0
+
+require (
+	github.com/acme/api v1.2.1 // indirect
+	github.com/stretchr/testify v1.8.0
+)
+
+replace github.com/acme/api v1.2.0 => ../api
+`, string(content), "should only update the require directives")
+}
+
+func TestEngineGolang_SetDependencyVersion_Major(t *testing.T) {
+	//setup
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+	pipelineData := new(pipeline.Data)
+	pipelineData.GitParentPath = t.TempDir()
+	pipelineData.GitLocalPath = path.Join(pipelineData.GitParentPath, "cli")
+	require.NoError(t, os.MkdirAll(pipelineData.GitLocalPath, 0755))
+	goMod := "module github.com/acme/cli\n\ngo 1.18\n\nrequire (\n\tgithub.com/acme/api/v2 v2.3.0\n\tgithub.com/acme/sdk v1.4.0\n)\n"
+	require.NoError(t, ioutil.WriteFile(path.Join(pipelineData.GitLocalPath, "go.mod"), []byte(goMod), 0644))
+
+	golangEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_GOLANG, pipelineData, testConfig, nil)
+	require.NoError(t, err)
+	dependencyManager := golangEngine.(engine.DependencyManager)
+
+	//test
+	majorErr := dependencyManager.SetDependencyVersion("github.com/acme/sdk", "2.0.0")
+	minorErr := dependencyManager.SetDependencyVersion("github.com/acme/api/v2", "2.4.0")
+
+	//assert
+	require.Error(t, majorErr, "should not require a v2+ version without the /v2 module path suffix")
+	require.Contains(t, majorErr.Error(), "/v2")
+	require.NoError(t, minorErr, "should update a module with the major version suffix")
+	content, err := ioutil.ReadFile(path.Join(pipelineData.GitLocalPath, "go.mod"))
+	require.NoError(t, err)
+	require.Equal(t, "module github.com/acme/cli\n\ngo 1.18\n\nrequire (\n\tgithub.com/acme/api/v2 v2.4.0\n\tgithub.com/acme/sdk v1.4.0\n)\n", string(content))
+}
+
+func TestEngineGolang_GetVersion(t *testing.T) {
+	//setup
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+	pipelineData := new(pipeline.Data)
+	pipelineData.GitLocalPath = t.TempDir()
+	require.NoError(t, ioutil.WriteFile(path.Join(pipelineData.GitLocalPath, "version.go"), []byte("package version\n\nconst VERSION = \"2.1.0\"\n"), 0644))
+	golangEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_GOLANG, pipelineData, testConfig, nil)
+	require.NoError(t, err)
+
+	//test
+	version, err := golangEngine.(engine.VersionReader).GetVersion(path.Join(pipelineData.GitLocalPath, "version.go"))
+
+	//assert
+	require.NoError(t, err)
+	require.Equal(t, "2.1.0", version)
+}
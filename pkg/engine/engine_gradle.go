@@ -0,0 +1,315 @@
+package engine
+
+import (
+	"fmt"
+	"github.com/analogj/go-util/utils"
+	"github.com/packagrio/bumpr/pkg/config"
+	"github.com/packagrio/go-common/errors"
+	"github.com/packagrio/go-common/pipeline"
+	"github.com/packagrio/go-common/scm"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+)
+
+// gradleVersionFiles are searched (in order) for the version, when `version_metadata_path` is not set (or is a directory).
+var gradleVersionFiles = []string{"gradle.properties", "build.gradle", "build.gradle.kts"}
+
+// GradleMetadata is the metadata of a Gradle project (go-common does not define a Gradle metadata type). VersionCode is
+// only populated when `gradle_android_version_code` is enabled.
+type GradleMetadata struct {
+	Version     string
+	VersionCode int
+}
+
+var (
+	// `version=1.2.3` (or `version: 1.2.3`) in gradle.properties
+	gradlePropertiesVersionPattern = regexp.MustCompile(`(?m)^([ \t]*version[ \t]*[=:][ \t]*)([^\s#!]+)`)
+	// `version = '1.2.3'` in build.gradle, `version = "1.2.3"` in build.gradle.kts. Interpolated versions are ignored.
+	gradleBuildVersionPattern = regexp.MustCompile(`(?m)^([ \t]*(?:project\.)?version[ \t]*=?[ \t]*['"])([^'"$\s]+)['"]`)
+	// `versionName "1.2.3"` in the defaultConfig of an Android module
+	gradleVersionNamePattern = regexp.MustCompile(`(?m)^([ \t]*versionName[ \t]*=?[ \t]*['"])([^'"$\s]+)['"]`)
+	gradleVersionCodePattern = regexp.MustCompile(`(?m)^([ \t]*versionCode[ \t]*=?[ \t]*)([0-9]+)\b`)
+)
+
+// engineGradle reads & writes the version assignment of a Gradle project: `version=` in gradle.properties, or
+// `version = '...'` in build.gradle (or build.gradle.kts). Only the assignment is replaced, so `gradle` is not required.
+type engineGradle struct {
+	engineBase
+
+	Scm             scm.Interface //Interface
+	CurrentMetadata *GradleMetadata
+	NextMetadata    *GradleMetadata
+}
+
+func (g *engineGradle) Init(pipelineData *pipeline.Data, configData config.Interface, sourceScm scm.Interface) error {
+	g.Scm = sourceScm
+	g.Config = configData
+	g.PipelineData = pipelineData
+	g.CurrentMetadata = new(GradleMetadata)
+	g.NextMetadata = new(GradleMetadata)
+
+	//set command defaults (can be overridden by repo/system configuration)
+	//the default version file is the first of gradleVersionFiles that contains a version.
+	if versionFile, ok := g.findVersionFile(g.PipelineData.GitLocalPath); ok {
+		g.Config.SetDefault(config.PACKAGR_VERSION_METADATA_PATH, path.Base(versionFile))
+	}
+	return nil
+}
+
+func (g *engineGradle) GetCurrentMetadata() interface{} {
+	return g.CurrentMetadata
+}
+func (g *engineGradle) GetNextMetadata() interface{} {
+	return g.NextMetadata
+}
+func (g *engineGradle) GetCurrentVersion() string {
+	return g.CurrentMetadata.Version
+}
+
+// ValidateTools is a no-op, the version files are updated without the `gradle` binary.
+func (g *engineGradle) ValidateTools() error {
+	return nil
+}
+
+func (g *engineGradle) DetectPackage(gitLocalPath string, configImpl config.Interface) (bool, string) {
+	for _, markerFile := range []string{"settings.gradle", "settings.gradle.kts", "build.gradle", "build.gradle.kts"} {
+		if utils.FileExists(path.Join(gitLocalPath, markerFile)) {
+			return true, fmt.Sprintf("found %s", markerFile)
+		}
+	}
+	return false, ""
+}
+
+func (g *engineGradle) BumpVersion() error {
+	versionFile, ok := g.versionFile(path.Join(g.PipelineData.GitLocalPath, g.Config.GetString(config.PACKAGR_VERSION_METADATA_PATH)))
+	if !ok {
+		return errors.EngineBuildPackageInvalid("a gradle.properties, build.gradle or build.gradle.kts file that sets the version is required to process Gradle project")
+	}
+
+	if merr := g.retrieveCurrentMetadata(versionFile); merr != nil {
+		return merr
+	}
+
+	if perr := g.populateNextMetadata(); perr != nil {
+		return perr
+	}
+
+	if nerr := g.writeNextMetadata(versionFile); nerr != nil {
+		return nerr
+	}
+
+	return nil
+}
+
+// SetVersion replaces the version assignment of a Gradle file (or the first of gradleVersionFiles in the specified
+// directory that sets the version). The Android versionCode is only incremented by BumpVersion.
+func (g *engineGradle) SetVersion(versionMetadataPath string, nextVersion string) error {
+	versionFile, ok := g.versionFile(versionMetadataPath)
+	if !ok {
+		return errors.EngineBuildPackageFailed(fmt.Sprintf("Could not find the version in %s", versionMetadataPath))
+	}
+	content, err := g.readFile(versionFile)
+	if err != nil {
+		return err
+	}
+	updatedContent, err := replaceGradleVersion(versionFile, content, nextVersion)
+	if err != nil {
+		return err
+	}
+	return g.writeFile(versionFile, updatedContent, 0644)
+}
+
+// GetVersion reads the version assignment of a Gradle file (or the first of gradleVersionFiles in the specified
+// directory that sets the version).
+func (g *engineGradle) GetVersion(versionMetadataPath string) (string, error) {
+	versionFile, ok := g.versionFile(versionMetadataPath)
+	if !ok {
+		return "", errors.EngineBuildPackageFailed(fmt.Sprintf("Could not find the version in %s", versionMetadataPath))
+	}
+	content, err := g.readFile(versionFile)
+	if err != nil {
+		return "", err
+	}
+	match, err := gradleVersionMatch(versionFile, content)
+	if err != nil {
+		return "", err
+	}
+	return string(content[match[4]:match[5]]), nil
+}
+
+//private Helpers
+
+func (g *engineGradle) retrieveCurrentMetadata(versionFile string) error {
+	content, err := g.readFile(versionFile)
+	if err != nil {
+		return err
+	}
+	match, err := gradleVersionMatch(versionFile, content)
+	if err != nil {
+		return err
+	}
+	g.CurrentMetadata.Version = string(content[match[4]:match[5]])
+
+	if g.Config.GetBool(config.PACKAGR_GRADLE_ANDROID_VERSION_CODE) {
+		_, buildContent, versionCodeMatch, err := g.androidVersionCode(versionFile)
+		if err != nil {
+			return err
+		}
+		if g.CurrentMetadata.VersionCode, err = strconv.Atoi(string(buildContent[versionCodeMatch[4]:versionCodeMatch[5]])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *engineGradle) populateNextMetadata() error {
+
+	currentVersion, err := g.ResolveCurrentVersion(g.CurrentMetadata.Version)
+	if err != nil {
+		return err
+	}
+
+	nextVersion, err := g.GenerateNextVersion(currentVersion)
+	if err != nil {
+		return err
+	}
+
+	g.NextMetadata.Version = nextVersion
+	g.NextMetadata.VersionCode = g.CurrentMetadata.VersionCode
+	// the versionCode is only incremented when the version changes (eg. not with the `none` bump type)
+	if g.Config.GetBool(config.PACKAGR_GRADLE_ANDROID_VERSION_CODE) && nextVersion != g.CurrentMetadata.Version {
+		g.NextMetadata.VersionCode++
+	}
+	g.PipelineData.ReleaseVersion = g.NextMetadata.Version
+	return nil
+}
+
+func (g *engineGradle) writeNextMetadata(versionFile string) error {
+	content, err := g.readFile(versionFile)
+	if err != nil {
+		return err
+	}
+	updatedContent, err := replaceGradleVersion(versionFile, content, g.NextMetadata.Version)
+	if err != nil {
+		return err
+	}
+	if err := g.writeFile(versionFile, updatedContent, 0644); err != nil {
+		return err
+	}
+	if g.NextMetadata.VersionCode != g.CurrentMetadata.VersionCode {
+		// the build script is read again, as it may be the version file
+		buildFile, buildContent, versionCodeMatch, err := g.androidVersionCode(versionFile)
+		if err != nil {
+			return err
+		}
+		return g.writeFile(buildFile, replaceSubmatch(buildContent, versionCodeMatch, strconv.Itoa(g.NextMetadata.VersionCode)), 0644)
+	}
+	return nil
+}
+
+// androidVersionCode returns the build script that sets the Android versionName (the version file, or a build script in
+// the same directory when the version is set elsewhere, eg. in gradle.properties), its content, and the submatch indexes
+// of the versionCode closest to the versionName.
+func (g *engineGradle) androidVersionCode(versionFile string) (string, []byte, []int, error) {
+	buildFiles := []string{versionFile}
+	for _, buildFileName := range []string{"build.gradle", "build.gradle.kts"} {
+		buildFiles = append(buildFiles, path.Join(path.Dir(versionFile), buildFileName))
+	}
+	for _, buildFile := range buildFiles {
+		if path.Ext(buildFile) == ".properties" || !utils.FileExists(buildFile) {
+			continue
+		}
+		content, err := g.readFile(buildFile)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		versionNameMatch := gradleVersionNamePattern.FindSubmatchIndex(content)
+		if versionNameMatch == nil {
+			continue
+		}
+		versionCodeMatch := gradleVersionCodeMatch(content, versionNameMatch[0])
+		if versionCodeMatch == nil {
+			return "", nil, nil, errors.EngineBuildPackageFailed(fmt.Sprintf("Could not find the versionCode in %s, required by gradle_android_version_code", buildFile))
+		}
+		return buildFile, content, versionCodeMatch, nil
+	}
+	return "", nil, nil, errors.EngineBuildPackageFailed(fmt.Sprintf("Could not find the Android versionName in %s, required by gradle_android_version_code", versionFile))
+}
+
+// versionFile returns the Gradle file that sets the version, the path is either a file, or a directory that contains
+// one of gradleVersionFiles.
+func (g *engineGradle) versionFile(versionMetadataPath string) (string, bool) {
+	if info, err := os.Stat(versionMetadataPath); err == nil && info.IsDir() {
+		return g.findVersionFile(versionMetadataPath)
+	}
+	return versionMetadataPath, utils.FileExists(versionMetadataPath)
+}
+
+// findVersionFile returns the first of gradleVersionFiles (in the directory) that contains a version assignment.
+func (g *engineGradle) findVersionFile(dirPath string) (string, bool) {
+	for _, versionFileName := range gradleVersionFiles {
+		versionFile := path.Join(dirPath, versionFileName)
+		if !utils.FileExists(versionFile) {
+			continue
+		}
+		content, err := g.readFile(versionFile)
+		if err != nil {
+			continue
+		}
+		if _, err := gradleVersionMatch(versionFile, content); err == nil {
+			return versionFile, true
+		}
+	}
+	return "", false
+}
+
+// gradleVersionMatch returns the submatch indexes of the version assignment: the prefix (1) and the version (2). Build
+// scripts that don't set the project version are checked for the Android versionName.
+func gradleVersionMatch(versionFile string, content []byte) ([]int, error) {
+	patterns := []*regexp.Regexp{gradleBuildVersionPattern, gradleVersionNamePattern}
+	if path.Ext(versionFile) == ".properties" {
+		patterns = []*regexp.Regexp{gradlePropertiesVersionPattern}
+	}
+	for _, pattern := range patterns {
+		if match := pattern.FindSubmatchIndex(content); match != nil {
+			return match, nil
+		}
+	}
+	return nil, errors.EngineBuildPackageFailed(fmt.Sprintf("Could not find the version in %s", versionFile))
+}
+
+// gradleVersionCodeMatch returns the submatch indexes of the versionCode closest to the versionName (at offset),
+// so that the versionCode of the same defaultConfig (or product flavor) is used.
+func gradleVersionCodeMatch(content []byte, offset int) []int {
+	var closest []int
+	for _, match := range gradleVersionCodePattern.FindAllSubmatchIndex(content, -1) {
+		if closest == nil || absInt(match[0]-offset) < absInt(closest[0]-offset) {
+			closest = match
+		}
+	}
+	return closest
+}
+
+func replaceGradleVersion(versionFile string, content []byte, nextVersion string) ([]byte, error) {
+	match, err := gradleVersionMatch(versionFile, content)
+	if err != nil {
+		return nil, err
+	}
+	return replaceSubmatch(content, match, nextVersion), nil
+}
+
+// replaceSubmatch replaces the second submatch (the value) of a match returned by FindSubmatchIndex
+func replaceSubmatch(content []byte, match []int, value string) []byte {
+	updated := append([]byte{}, content[:match[4]]...)
+	updated = append(updated, value...)
+	return append(updated, content[match[5]:]...)
+}
+
+func absInt(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
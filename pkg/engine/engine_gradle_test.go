@@ -0,0 +1,354 @@
+//go:build gradle
+// +build gradle
+
+package engine_test
+
+import (
+	"github.com/analogj/go-util/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/packagrio/bumpr/pkg/config"
+	"github.com/packagrio/bumpr/pkg/config/mock"
+	"github.com/packagrio/bumpr/pkg/engine"
+	"github.com/packagrio/go-common/pipeline"
+	"github.com/packagrio/go-common/scm"
+	"github.com/packagrio/go-common/scm/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestEngineGradle_Create(t *testing.T) {
+	//setup
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+
+	testConfig.Set(config.PACKAGR_SCM, "github")
+	testConfig.Set(config.PACKAGR_PACKAGE_TYPE, "gradle")
+	pipelineData := new(pipeline.Data)
+	githubScm, err := scm.Create("github", pipelineData, testConfig, &http.Client{})
+	require.NoError(t, err)
+
+	//test
+	gradleEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_GRADLE, pipelineData, testConfig, githubScm)
+
+	//assert
+	require.NoError(t, err)
+	require.NotNil(t, gradleEngine)
+}
+
+// Define the suite, and absorb the built-in basic suite
+// functionality from testify - including a T() method which
+// returns the current testing context
+type EngineGradleTestSuite struct {
+	suite.Suite
+	MockCtrl     *gomock.Controller
+	Scm          *mock_scm.MockInterface
+	Config       *mock_config.MockInterface
+	PipelineData *pipeline.Data
+}
+
+// Make sure that VariableThatShouldStartAtFive is set to five
+// before each test
+func (suite *EngineGradleTestSuite) SetupTest() {
+	suite.MockCtrl = gomock.NewController(suite.T())
+
+	suite.PipelineData = new(pipeline.Data)
+
+	suite.Config = mock_config.NewMockInterface(suite.MockCtrl)
+	suite.Scm = mock_scm.NewMockInterface(suite.MockCtrl)
+
+}
+
+func (suite *EngineGradleTestSuite) TearDownTest() {
+	suite.MockCtrl.Finish()
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestEngineGradle_TestSuite(t *testing.T) {
+	suite.Run(t, new(EngineGradleTestSuite))
+}
+
+func (suite *EngineGradleTestSuite) TestEngineGradle_ValidateTools() {
+	//setup
+	gradleEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_GRADLE, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	verr := gradleEngine.ValidateTools()
+
+	//assert
+	require.NoError(suite.T(), verr, "should not require the gradle binary")
+}
+
+func (suite *EngineGradleTestSuite) TestEngineGradle_BumpVersion_Properties() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "gradle.properties")
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_METADATA_PATH).Return("gradle.properties").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("minor").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
+	suite.Config.EXPECT().GetBool(config.PACKAGR_GRADLE_ANDROID_VERSION_CODE).Return(false).MinTimes(1)
+
+	//copy fixture into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "gradle_properties_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "gradle", "gradle_properties_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	gradleEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_GRADLE, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := gradleEngine.BumpVersion()
+	require.NoError(suite.T(), berr)
+
+	//assert
+	require.Equal(suite.T(), &engine.GradleMetadata{Version: "0.9.1"}, gradleEngine.GetCurrentMetadata())
+	require.Equal(suite.T(), &engine.GradleMetadata{Version: "0.10.0"}, gradleEngine.GetNextMetadata())
+	gradleProperties, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "gradle.properties"))
+	require.NoError(suite.T(), err)
+	expectedGradleProperties, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "gradle.properties.expected"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), string(expectedGradleProperties), string(gradleProperties))
+	buildGradle, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "build.gradle"))
+	require.NoError(suite.T(), err)
+	originalBuildGradle, err := ioutil.ReadFile(path.Join("testdata", "gradle", "gradle_properties_analogj_test", "build.gradle"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), string(originalBuildGradle), string(buildGradle), "should only update the first file that sets the version")
+}
+
+func (suite *EngineGradleTestSuite) TestEngineGradle_BumpVersion_BuildGradle() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "build.gradle")
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_METADATA_PATH).Return("build.gradle").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("patch").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
+	suite.Config.EXPECT().GetBool(config.PACKAGR_GRADLE_ANDROID_VERSION_CODE).Return(false).MinTimes(1)
+
+	//copy fixture into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "gradle_build_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "gradle", "gradle_build_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	gradleEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_GRADLE, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := gradleEngine.BumpVersion()
+	require.NoError(suite.T(), berr)
+
+	//assert
+	buildGradle, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "build.gradle"))
+	require.NoError(suite.T(), err)
+	expectedBuildGradle, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "build.gradle.expected"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), string(expectedBuildGradle), string(buildGradle), "should only update the version assignment")
+}
+
+func (suite *EngineGradleTestSuite) TestEngineGradle_BumpVersion_VersionMetadataPath() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "gradle.properties")
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_METADATA_PATH).Return("build.gradle.kts").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("release").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
+	suite.Config.EXPECT().GetBool(config.PACKAGR_GRADLE_ANDROID_VERSION_CODE).Return(false).MinTimes(1)
+
+	//copy fixture into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "gradle_kts_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "gradle", "gradle_kts_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	gradleEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_GRADLE, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := gradleEngine.BumpVersion()
+	require.NoError(suite.T(), berr)
+
+	//assert
+	buildGradleKts, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "build.gradle.kts"))
+	require.NoError(suite.T(), err)
+	expectedBuildGradleKts, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "build.gradle.kts.expected"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), string(expectedBuildGradleKts), string(buildGradleKts))
+	gradleProperties, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "gradle.properties"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), "version=0.9.1\n", string(gradleProperties))
+}
+
+func (suite *EngineGradleTestSuite) TestEngineGradle_BumpVersion_AndroidVersionCode() {
+	//setup
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_METADATA_PATH).Return("app").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("minor").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
+	suite.Config.EXPECT().GetBool(config.PACKAGR_GRADLE_ANDROID_VERSION_CODE).Return(true).MinTimes(1)
+
+	//copy fixture into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "gradle_android_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "gradle", "gradle_android_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	gradleEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_GRADLE, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := gradleEngine.BumpVersion()
+	require.NoError(suite.T(), berr)
+
+	//assert
+	require.Equal(suite.T(), &engine.GradleMetadata{Version: "2.3.0", VersionCode: 41}, gradleEngine.GetCurrentMetadata())
+	require.Equal(suite.T(), &engine.GradleMetadata{Version: "2.4.0", VersionCode: 42}, gradleEngine.GetNextMetadata())
+	buildGradleKts, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "app", "build.gradle.kts"))
+	require.NoError(suite.T(), err)
+	expectedBuildGradleKts, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "app", "build.gradle.kts.expected"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), string(expectedBuildGradleKts), string(buildGradleKts))
+}
+
+func (suite *EngineGradleTestSuite) TestEngineGradle_BumpVersion_AndroidVersionCode_Properties() {
+	//setup
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_METADATA_PATH).Return("app").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("minor").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
+	suite.Config.EXPECT().GetBool(config.PACKAGR_GRADLE_ANDROID_VERSION_CODE).Return(true).MinTimes(1)
+
+	//copy fixture into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "gradle_android_properties_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "gradle", "gradle_android_properties_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	gradleEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_GRADLE, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := gradleEngine.BumpVersion()
+	require.NoError(suite.T(), berr)
+
+	//assert
+	require.Equal(suite.T(), &engine.GradleMetadata{Version: "2.4.0", VersionCode: 42}, gradleEngine.GetNextMetadata())
+	gradleProperties, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "app", "gradle.properties"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), "version=2.4.0\n", string(gradleProperties))
+	buildGradleKts, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "app", "build.gradle.kts"))
+	require.NoError(suite.T(), err)
+	require.Contains(suite.T(), string(buildGradleKts), "versionCode = 42\n", "should increment the versionCode of the build script that sets the versionName")
+}
+
+func (suite *EngineGradleTestSuite) TestEngineGradle_BumpVersion_AndroidVersionCode_ProjectVersion() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "build.gradle")
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_METADATA_PATH).Return("build.gradle").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("patch").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
+	suite.Config.EXPECT().GetBool(config.PACKAGR_GRADLE_ANDROID_VERSION_CODE).Return(true).MinTimes(1)
+
+	//copy fixture into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "gradle_android_flavors_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "gradle", "gradle_android_flavors_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	gradleEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_GRADLE, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := gradleEngine.BumpVersion()
+	require.NoError(suite.T(), berr)
+
+	//assert
+	require.Equal(suite.T(), &engine.GradleMetadata{Version: "2.3.0", VersionCode: 41}, gradleEngine.GetCurrentMetadata(), "should use the versionCode next to the versionName, not the project version")
+	buildGradle, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "build.gradle"))
+	require.NoError(suite.T(), err)
+	require.Contains(suite.T(), string(buildGradle), "version = '2.3.1'\n")
+	require.Contains(suite.T(), string(buildGradle), "versionCode 7\n")
+	require.Contains(suite.T(), string(buildGradle), "versionCode 42\n")
+}
+
+func (suite *EngineGradleTestSuite) TestEngineGradle_BumpVersion_AndroidVersionCode_NoVersionName() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "gradle.properties")
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_METADATA_PATH).Return("gradle.properties").MinTimes(1)
+	suite.Config.EXPECT().GetBool(config.PACKAGR_GRADLE_ANDROID_VERSION_CODE).Return(true).MinTimes(1)
+
+	//copy fixture into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "gradle_android_no_version_name_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "gradle", "gradle_android_no_version_name_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	gradleEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_GRADLE, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := gradleEngine.BumpVersion()
+
+	//assert
+	require.Error(suite.T(), berr, "should not use a versionCode without a versionName")
+	gradleProperties, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "gradle.properties"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), "version=2.3.0\n", string(gradleProperties))
+}
+
+func (suite *EngineGradleTestSuite) TestEngineGradle_BumpVersion_NotFound() {
+	//setup
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_METADATA_PATH).Return("").MinTimes(1)
+	suite.PipelineData.GitLocalPath = path.Join("testdata", "gradle", "gradle_interpolated_analogj_test")
+
+	gradleEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_GRADLE, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := gradleEngine.BumpVersion()
+
+	//assert
+	require.Error(suite.T(), berr, "should not update interpolated versions")
+}
+
+func (suite *EngineGradleTestSuite) TestEngineGradle_GetVersion() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "build.gradle")
+	suite.PipelineData.GitLocalPath = path.Join("testdata", "gradle", "gradle_build_analogj_test")
+
+	gradleEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_GRADLE, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+	versionReader := gradleEngine.(engine.VersionReader)
+
+	//test
+	projectVersion, perr := versionReader.GetVersion(suite.PipelineData.GitLocalPath)
+	androidVersion, aerr := versionReader.GetVersion(path.Join("testdata", "gradle", "gradle_android_analogj_test", "app", "build.gradle.kts"))
+
+	//assert
+	require.NoError(suite.T(), perr)
+	require.Equal(suite.T(), "1.4.2", projectVersion)
+	require.NoError(suite.T(), aerr)
+	require.Equal(suite.T(), "2.3.0", androidVersion, "should read the versionName")
+}
@@ -0,0 +1,444 @@
+package engine
+
+import (
+	"fmt"
+	"github.com/analogj/go-util/utils"
+	"github.com/packagrio/bumpr/pkg/config"
+	"github.com/packagrio/go-common/errors"
+	"github.com/packagrio/go-common/pipeline"
+	"github.com/packagrio/go-common/scm"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const helmVersionMetadataPath = "Chart.yaml"
+
+// HelmMetadata is the metadata of a Helm chart (go-common does not define a Helm metadata type).
+type HelmMetadata struct {
+	Name       string
+	Version    string
+	AppVersion string
+}
+
+var (
+	// a `key: value` line of a mapping (optionally the first key of a sequence item), the scalar value may be quoted, and
+	// followed by a comment.
+	helmKeyValuePattern     = regexp.MustCompile(`^(\s*(?:-\s+)?)([A-Za-z0-9_.-]+):(?:([ \t]+)(["']?)([^"'#\s]*)(["']?)(\s*(?:#.*)?))?\r?\n?$`)
+	helmSequenceItemPattern = regexp.MustCompile(`^(\s*)-\s`)
+)
+
+// engineHelm reads & writes the `version` of a Helm chart (Chart.yaml), and optionally its `appVersion` (see
+// `helm_app_version_source`). Chart.yaml is edited line by line, so comments & key order are preserved, and the `helm`
+// binary is not required.
+type engineHelm struct {
+	engineBase
+
+	Scm             scm.Interface //Interface
+	CurrentMetadata *HelmMetadata
+	NextMetadata    *HelmMetadata
+}
+
+func (g *engineHelm) Init(pipelineData *pipeline.Data, configData config.Interface, sourceScm scm.Interface) error {
+	g.Scm = sourceScm
+	g.Config = configData
+	g.PipelineData = pipelineData
+	g.CurrentMetadata = new(HelmMetadata)
+	g.NextMetadata = new(HelmMetadata)
+
+	//set command defaults (can be overridden by repo/system configuration)
+	g.Config.SetDefault(config.PACKAGR_VERSION_METADATA_PATH, helmVersionMetadataPath)
+	return nil
+}
+
+func (g *engineHelm) GetCurrentMetadata() interface{} {
+	return g.CurrentMetadata
+}
+func (g *engineHelm) GetNextMetadata() interface{} {
+	return g.NextMetadata
+}
+func (g *engineHelm) GetCurrentVersion() string {
+	return g.CurrentMetadata.Version
+}
+
+// ValidateTools is a no-op, Chart.yaml is updated without the `helm` binary.
+func (g *engineHelm) ValidateTools() error {
+	return nil
+}
+
+func (g *engineHelm) DetectPackage(gitLocalPath string, configImpl config.Interface) (bool, string) {
+	if utils.FileExists(path.Join(gitLocalPath, helmVersionMetadataPath)) {
+		return true, fmt.Sprintf("found %s", helmVersionMetadataPath)
+	}
+	return false, ""
+}
+
+func (g *engineHelm) BumpVersion() error {
+	chartPath := helmChartPath(path.Join(g.PipelineData.GitLocalPath, g.Config.GetString(config.PACKAGR_VERSION_METADATA_PATH)))
+	if !utils.FileExists(chartPath) {
+		return errors.EngineBuildPackageInvalid(fmt.Sprintf("%s file is required to process Helm chart", g.Config.GetString(config.PACKAGR_VERSION_METADATA_PATH)))
+	}
+
+	if merr := g.retrieveCurrentMetadata(chartPath); merr != nil {
+		return merr
+	}
+
+	if perr := g.populateNextMetadata(); perr != nil {
+		return perr
+	}
+
+	if nerr := g.writeNextMetadata(chartPath); nerr != nil {
+		return nerr
+	}
+
+	return nil
+}
+
+// SetVersion updates the version of a Chart.yaml file (or the Chart.yaml in the specified directory), and its appVersion
+// when `helm_app_version_source` is set.
+func (g *engineHelm) SetVersion(versionMetadataPath string, nextVersion string) error {
+	chartPath := helmChartPath(versionMetadataPath)
+	appVersion, err := g.resolveAppVersion(nextVersion)
+	if err != nil {
+		return err
+	}
+	chart, err := g.readChart(chartPath)
+	if err != nil {
+		return err
+	}
+	if err := chart.setVersion(nextVersion, appVersion); err != nil {
+		return err
+	}
+	return g.writeFile(chartPath, chart.bytes(), 0644)
+}
+
+// GetVersion reads the version of a Chart.yaml file (or the Chart.yaml in the specified directory).
+func (g *engineHelm) GetVersion(versionMetadataPath string) (string, error) {
+	chart, err := g.readChart(helmChartPath(versionMetadataPath))
+	if err != nil {
+		return "", err
+	}
+	version, ok := chart.value("version")
+	if !ok {
+		return "", errors.EngineBuildPackageFailed(fmt.Sprintf("Could not find the version in %s", chart.path))
+	}
+	return version, nil
+}
+
+// PackageName returns the chart name, used by parent charts to depend on the chart (`dependencies`).
+func (g *engineHelm) PackageName() (string, error) {
+	chart, err := g.readChart(g.chartPath())
+	if err != nil {
+		return "", err
+	}
+	name, _ := chart.value("name")
+	return name, nil
+}
+
+// Dependencies returns the names of the subcharts listed in `dependencies`.
+func (g *engineHelm) Dependencies() ([]string, error) {
+	chart, err := g.readChart(g.chartPath())
+	if err != nil {
+		return nil, err
+	}
+	dependencyNames := []string{}
+	for _, dependency := range chart.dependencies() {
+		if name, ok := chart.itemValue(dependency, "name"); ok {
+			dependencyNames = append(dependencyNames, name)
+		}
+	}
+	return dependencyNames, nil
+}
+
+// SetDependencyVersion updates the version constraint of a subchart, preserving its operator (eg. `~1.2.3`). Ranges
+// (eg. `>=1.2.0 <2.0.0`) and wildcards (eg. `1.2.x`) are left unchanged.
+func (g *engineHelm) SetDependencyVersion(dependencyName string, version string) error {
+	chart, err := g.readChart(g.chartPath())
+	if err != nil {
+		return err
+	}
+	updated := false
+	for _, dependency := range chart.dependencies() {
+		if name, _ := chart.itemValue(dependency, "name"); name != dependencyName {
+			continue
+		}
+		specifier, ok := chart.itemValue(dependency, "version")
+		if !ok {
+			continue
+		}
+		if updatedSpecifier, ok := updateVersionSpecifier(specifier, version); ok && updatedSpecifier != specifier {
+			if err := chart.setItemValue(dependency, "version", updatedSpecifier); err != nil {
+				return err
+			}
+			updated = true
+		}
+	}
+	if !updated {
+		return nil
+	}
+	return g.writeFile(chart.path, chart.bytes(), 0644)
+}
+
+//private Helpers
+
+func (g *engineHelm) chartPath() string {
+	return helmChartPath(path.Join(g.PipelineData.GitLocalPath, g.Config.GetString(config.PACKAGR_VERSION_METADATA_PATH)))
+}
+
+func (g *engineHelm) retrieveCurrentMetadata(chartPath string) error {
+	chart, err := g.readChart(chartPath)
+	if err != nil {
+		return err
+	}
+	version, ok := chart.value("version")
+	if !ok {
+		return errors.EngineBuildPackageFailed(fmt.Sprintf("Could not find the version in %s", chartPath))
+	}
+	g.CurrentMetadata.Version = version
+	g.CurrentMetadata.Name, _ = chart.value("name")
+	g.CurrentMetadata.AppVersion, _ = chart.value("appVersion")
+	return nil
+}
+
+func (g *engineHelm) populateNextMetadata() error {
+
+	currentVersion, err := g.ResolveCurrentVersion(g.CurrentMetadata.Version)
+	if err != nil {
+		return err
+	}
+
+	nextVersion, err := g.GenerateNextVersion(currentVersion)
+	if err != nil {
+		return err
+	}
+
+	g.NextMetadata.Version = nextVersion
+	g.NextMetadata.Name = g.CurrentMetadata.Name
+	g.NextMetadata.AppVersion = g.CurrentMetadata.AppVersion
+	if appVersion, err := g.resolveAppVersion(nextVersion); err != nil {
+		return err
+	} else if appVersion != "" {
+		g.NextMetadata.AppVersion = appVersion
+	}
+	g.PipelineData.ReleaseVersion = g.NextMetadata.Version
+	return nil
+}
+
+func (g *engineHelm) writeNextMetadata(chartPath string) error {
+	chart, err := g.readChart(chartPath)
+	if err != nil {
+		return err
+	}
+	appVersion := ""
+	if g.NextMetadata.AppVersion != g.CurrentMetadata.AppVersion {
+		appVersion = g.NextMetadata.AppVersion
+	}
+	if err := chart.setVersion(g.NextMetadata.Version, appVersion); err != nil {
+		return err
+	}
+	return g.writeFile(chartPath, chart.bytes(), 0644)
+}
+
+// resolveAppVersion returns the application version configured by `helm_app_version_source`, or an empty string if the
+// appVersion is not managed:
+//   - `chart` - the chart version
+//   - `tag` - the version of the latest semver git tag reachable from HEAD (see RetrieveTagVersion). This is the version
+//     that was already released & tagged (eg. by the application's pipeline), not the version bumped by this run, as
+//     the release tag is only created once the run completes.
+//   - `<engine>:<path>` - the version read from a version file (relative to the chart package) by the engine, eg.
+//     `node:package.json`. Changes staged by the current run are visible, so the application's release version is used
+//     when it is bumped before the chart.
+func (g *engineHelm) resolveAppVersion(chartVersion string) (string, error) {
+	source := g.Config.GetString(config.PACKAGR_HELM_APP_VERSION_SOURCE)
+	switch source {
+	case "":
+		return "", nil
+	case "chart":
+		return chartVersion, nil
+	case PACKAGR_ENGINE_TYPE_TAG:
+		return g.RetrieveTagVersion()
+	}
+
+	engineType, versionPath, ok := strings.Cut(source, ":")
+	if !ok || engineType == PACKAGR_ENGINE_TYPE_HELM {
+		return "", errors.EngineBuildPackageInvalid(fmt.Sprintf("invalid helm_app_version_source %q, expected chart, tag or <engine>:<path>", source))
+	}
+	// the engine sets its own defaults during Init
+	sourceConfig, err := config.Clone(g.Config, nil)
+	if err != nil {
+		return "", err
+	}
+	sourceData := *g.PipelineData
+	sourceEngine, err := Create(engineType, &sourceData, sourceConfig, g.Scm)
+	if err != nil {
+		return "", err
+	}
+	sourceEngine.SetChangeSet(g.ChangeSet)
+	versionReader, ok := sourceEngine.(VersionReader)
+	if !ok {
+		return "", errors.EngineBuildPackageInvalid(fmt.Sprintf("the %s engine can't read the helm_app_version_source version file", engineType))
+	}
+	return versionReader.GetVersion(path.Join(g.PipelineData.GitLocalPath, versionPath))
+}
+
+func (g *engineHelm) readChart(chartPath string) (*helmChart, error) {
+	content, err := g.readFile(chartPath)
+	if err != nil {
+		return nil, err
+	}
+	return &helmChart{path: chartPath, lines: strings.SplitAfter(string(content), "\n")}, nil
+}
+
+// helmChart is a line based view of a Chart.yaml file, only the scalar values of the top level keys & of the
+// `dependencies` items are read & updated.
+type helmChart struct {
+	path  string
+	lines []string
+}
+
+// helmChartItem is a `dependencies` sequence item, the line range of the item, and the indentation of its keys.
+type helmChartItem struct {
+	start  int
+	end    int
+	indent int
+}
+
+func (c *helmChart) bytes() []byte {
+	return []byte(strings.Join(c.lines, ""))
+}
+
+// value returns the scalar value of a top level key
+func (c *helmChart) value(key string) (string, bool) {
+	ndx := c.keyLine(key, 0, len(c.lines), 0)
+	if ndx < 0 {
+		return "", false
+	}
+	match := helmKeyValuePattern.FindStringSubmatch(c.lines[ndx])
+	return match[5], true
+}
+
+// setVersion updates the chart version, and the appVersion if specified. A missing appVersion is added after the version.
+func (c *helmChart) setVersion(version string, appVersion string) error {
+	versionNdx := c.keyLine("version", 0, len(c.lines), 0)
+	if versionNdx < 0 {
+		return errors.EngineBuildPackageFailed(fmt.Sprintf("Could not find the version in %s", c.path))
+	}
+	if err := c.setLineValue(versionNdx, "version", version); err != nil {
+		return err
+	}
+	if appVersion == "" {
+		return nil
+	}
+	if appVersionNdx := c.keyLine("appVersion", 0, len(c.lines), 0); appVersionNdx >= 0 {
+		return c.setLineValue(appVersionNdx, "appVersion", appVersion)
+	}
+	// the appVersion is quoted, as recommended by helm (eg. so that `1.10` is not parsed as a number)
+	appVersionLine := fmt.Sprintf("appVersion: %q\n", appVersion)
+	if !strings.HasSuffix(c.lines[versionNdx], "\n") {
+		c.lines[versionNdx] += "\n"
+		appVersionLine = strings.TrimSuffix(appVersionLine, "\n")
+	}
+	c.lines = append(c.lines[:versionNdx+1], append([]string{appVersionLine}, c.lines[versionNdx+1:]...)...)
+	return nil
+}
+
+// dependencies returns the items of the top level `dependencies` sequence.
+func (c *helmChart) dependencies() []*helmChartItem {
+	items := []*helmChartItem{}
+	dependenciesNdx := c.keyLine("dependencies", 0, len(c.lines), 0)
+	if dependenciesNdx < 0 {
+		return items
+	}
+	var item *helmChartItem
+	for ndx := dependenciesNdx + 1; ndx < len(c.lines); ndx++ {
+		line := c.lines[ndx]
+		if isHelmBlankLine(line) {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if indent == 0 && !strings.HasPrefix(line, "-") {
+			// the next top level key
+			break
+		}
+		if item != nil && item.indent < 0 {
+			// the keys of an item that starts with a bare `-` are aligned with the next line
+			item.indent = indent
+		}
+		// a new item, unless the sequence is nested in the current item (eg. `import-values`)
+		if match := helmSequenceItemPattern.FindStringSubmatch(line); match != nil && (item == nil || len(match[1]) < item.indent) {
+			// the keys of the item are aligned with the first key, after the `- `
+			item = &helmChartItem{start: ndx, indent: -1}
+			if itemContent := line[len(match[1])+1:]; strings.TrimSpace(itemContent) != "" {
+				item.indent = len(line) - len(strings.TrimLeft(itemContent, " "))
+			}
+			items = append(items, item)
+		}
+		if item != nil {
+			item.end = ndx + 1
+		}
+	}
+	return items
+}
+
+// itemValue returns the scalar value of a key of a sequence item
+func (c *helmChart) itemValue(item *helmChartItem, key string) (string, bool) {
+	ndx := c.keyLine(key, item.start, item.end, item.indent)
+	if ndx < 0 {
+		return "", false
+	}
+	match := helmKeyValuePattern.FindStringSubmatch(c.lines[ndx])
+	return match[5], true
+}
+
+func (c *helmChart) setItemValue(item *helmChartItem, key string, value string) error {
+	if ndx := c.keyLine(key, item.start, item.end, item.indent); ndx >= 0 {
+		return c.setLineValue(ndx, key, value)
+	}
+	return nil
+}
+
+// setLineValue replaces the scalar value of the key on the line, fails if the line has no value (eg. `version:`) rather
+// than leaving it unchanged.
+func (c *helmChart) setLineValue(ndx int, key string, value string) error {
+	line, ok := setHelmValue(c.lines[ndx], value)
+	if !ok {
+		return errors.EngineBuildPackageFailed(fmt.Sprintf("Could not set the %s in %s, the %s on line %d has no value", key, c.path, key, ndx+1))
+	}
+	c.lines[ndx] = line
+	return nil
+}
+
+// keyLine returns the index of the line (between start & end) that sets the key at the indentation, or -1.
+func (c *helmChart) keyLine(key string, start int, end int, indent int) int {
+	for ndx := start; ndx < end; ndx++ {
+		match := helmKeyValuePattern.FindStringSubmatch(c.lines[ndx])
+		if match == nil || match[2] != key || len(match[1]) != indent {
+			continue
+		}
+		return ndx
+	}
+	return -1
+}
+
+// setHelmValue replaces the scalar value of a `key: value` line, preserving the quotes & comment. Returns false if the
+// line has no value.
+func setHelmValue(line string, value string) (string, bool) {
+	match := helmKeyValuePattern.FindStringSubmatchIndex(line)
+	if match == nil || match[10] < 0 {
+		return line, false
+	}
+	return line[:match[10]] + value + line[match[11]:], true
+}
+
+func isHelmBlankLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" || strings.HasPrefix(trimmed, "#")
+}
+
+// the version metadata path may be the chart directory, or the Chart.yaml file itself.
+func helmChartPath(versionMetadataPath string) string {
+	if path.Base(versionMetadataPath) == helmVersionMetadataPath {
+		return versionMetadataPath
+	}
+	return path.Join(versionMetadataPath, helmVersionMetadataPath)
+}
@@ -0,0 +1,320 @@
+//go:build helm
+// +build helm
+
+package engine_test
+
+import (
+	"github.com/analogj/go-util/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/packagrio/bumpr/pkg/changeset"
+	"github.com/packagrio/bumpr/pkg/config"
+	"github.com/packagrio/bumpr/pkg/config/mock"
+	"github.com/packagrio/bumpr/pkg/engine"
+	"github.com/packagrio/go-common/pipeline"
+	"github.com/packagrio/go-common/scm"
+	"github.com/packagrio/go-common/scm/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestEngineHelm_Create(t *testing.T) {
+	//setup
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+
+	testConfig.Set(config.PACKAGR_SCM, "github")
+	testConfig.Set(config.PACKAGR_PACKAGE_TYPE, "helm")
+	pipelineData := new(pipeline.Data)
+	githubScm, err := scm.Create("github", pipelineData, testConfig, &http.Client{})
+	require.NoError(t, err)
+
+	//test
+	helmEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_HELM, pipelineData, testConfig, githubScm)
+
+	//assert
+	require.NoError(t, err)
+	require.NotNil(t, helmEngine)
+}
+
+// Define the suite, and absorb the built-in basic suite
+// functionality from testify - including a T() method which
+// returns the current testing context
+type EngineHelmTestSuite struct {
+	suite.Suite
+	MockCtrl     *gomock.Controller
+	Scm          *mock_scm.MockInterface
+	Config       *mock_config.MockInterface
+	PipelineData *pipeline.Data
+}
+
+// Make sure that VariableThatShouldStartAtFive is set to five
+// before each test
+func (suite *EngineHelmTestSuite) SetupTest() {
+	suite.MockCtrl = gomock.NewController(suite.T())
+
+	suite.PipelineData = new(pipeline.Data)
+
+	suite.Config = mock_config.NewMockInterface(suite.MockCtrl)
+	suite.Scm = mock_scm.NewMockInterface(suite.MockCtrl)
+
+}
+
+func (suite *EngineHelmTestSuite) TearDownTest() {
+	suite.MockCtrl.Finish()
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestEngineHelm_TestSuite(t *testing.T) {
+	suite.Run(t, new(EngineHelmTestSuite))
+}
+
+func (suite *EngineHelmTestSuite) TestEngineHelm_ValidateTools() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "Chart.yaml")
+	helmEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_HELM, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	verr := helmEngine.ValidateTools()
+
+	//assert
+	require.NoError(suite.T(), verr, "should not require the helm binary")
+}
+
+func (suite *EngineHelmTestSuite) TestEngineHelm_BumpVersion() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "Chart.yaml")
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_METADATA_PATH).Return("Chart.yaml").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("minor").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_HELM_APP_VERSION_SOURCE).Return("").MinTimes(1)
+
+	//copy fixture into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "chart_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "helm", "chart_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	helmEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_HELM, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := helmEngine.BumpVersion()
+	require.NoError(suite.T(), berr)
+
+	//assert
+	require.Equal(suite.T(), &engine.HelmMetadata{Name: "acme-api", Version: "0.4.1", AppVersion: "1.8.0"}, helmEngine.GetCurrentMetadata())
+	require.Equal(suite.T(), &engine.HelmMetadata{Name: "acme-api", Version: "0.5.0", AppVersion: "1.8.0"}, helmEngine.GetNextMetadata(), "should not update the appVersion by default")
+	chartYaml, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "Chart.yaml"))
+	require.NoError(suite.T(), err)
+	expectedChartYaml, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "Chart.yaml.expected"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), string(expectedChartYaml), string(chartYaml), "should only update the version, preserving the comments")
+}
+
+func (suite *EngineHelmTestSuite) TestEngineHelm_BumpVersion_AppVersion() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "Chart.yaml")
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_METADATA_PATH).Return("chart").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("patch").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_HELM_APP_VERSION_SOURCE).Return("node:package.json").MinTimes(1)
+	suite.Config.EXPECT().AllSettings().Return(map[string]interface{}{
+		config.PACKAGR_VERSION_METADATA_PATH:   "chart",
+		config.PACKAGR_HELM_APP_VERSION_SOURCE: "node:package.json",
+	})
+
+	//copy fixture into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "chart_app_version_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "helm", "chart_app_version_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	helmEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_HELM, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := helmEngine.BumpVersion()
+	require.NoError(suite.T(), berr)
+
+	//assert
+	require.Equal(suite.T(), "1.9.0", helmEngine.GetNextMetadata().(*engine.HelmMetadata).AppVersion)
+	chartYaml, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "chart", "Chart.yaml"))
+	require.NoError(suite.T(), err)
+	expectedChartYaml, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "chart", "Chart.yaml.expected"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), string(expectedChartYaml), string(chartYaml), "should add the appVersion after the version")
+}
+
+func (suite *EngineHelmTestSuite) TestEngineHelm_SetVersion_AppVersionChart() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "Chart.yaml")
+	suite.Config.EXPECT().GetString(config.PACKAGR_HELM_APP_VERSION_SOURCE).Return("chart").MinTimes(1)
+
+	//copy fixture into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "chart_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "helm", "chart_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	helmEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_HELM, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	serr := helmEngine.SetVersion(suite.PipelineData.GitLocalPath, "2.0.0")
+	require.NoError(suite.T(), serr)
+
+	//assert
+	chartYaml, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "Chart.yaml"))
+	require.NoError(suite.T(), err)
+	require.Contains(suite.T(), string(chartYaml), "version: 2.0.0 # chart version\nappVersion: \"2.0.0\"\n")
+}
+
+func (suite *EngineHelmTestSuite) TestEngineHelm_BumpVersion_InvalidAppVersionSource() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "Chart.yaml")
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_METADATA_PATH).Return("Chart.yaml").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("patch").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_HELM_APP_VERSION_SOURCE).Return("package.json").MinTimes(1)
+
+	//copy fixture into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "chart_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "helm", "chart_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	helmEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_HELM, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := helmEngine.BumpVersion()
+
+	//assert
+	require.Error(suite.T(), berr)
+	chartYaml, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "Chart.yaml"))
+	require.NoError(suite.T(), err)
+	originalChartYaml, err := ioutil.ReadFile(path.Join("testdata", "helm", "chart_analogj_test", "Chart.yaml"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), string(originalChartYaml), string(chartYaml))
+}
+
+func (suite *EngineHelmTestSuite) TestEngineHelm_SetVersion_EmptyVersion() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "Chart.yaml")
+	suite.Config.EXPECT().GetString(config.PACKAGR_HELM_APP_VERSION_SOURCE).Return("chart").MinTimes(1)
+
+	//copy fixture into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "chart_empty_version_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "helm", "chart_empty_version_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	helmEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_HELM, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	serr := helmEngine.SetVersion(suite.PipelineData.GitLocalPath, "1.0.0")
+
+	//assert
+	require.Error(suite.T(), serr, "should not silently leave the version unchanged")
+	require.Contains(suite.T(), serr.Error(), "line 3")
+	chartYaml, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "Chart.yaml"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), "apiVersion: v2\nname: acme-api\nversion:\nappVersion:\n", string(chartYaml))
+}
+
+func (suite *EngineHelmTestSuite) TestEngineHelm_Dependencies() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "Chart.yaml")
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_METADATA_PATH).Return("Chart.yaml").MinTimes(1)
+	suite.PipelineData.GitLocalPath = path.Join("testdata", "helm", "chart_analogj_test")
+
+	helmEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_HELM, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+	dependencyManager := helmEngine.(engine.DependencyManager)
+
+	//test
+	packageName, perr := dependencyManager.PackageName()
+	dependencies, derr := dependencyManager.Dependencies()
+
+	//assert
+	require.NoError(suite.T(), perr)
+	require.Equal(suite.T(), "acme-api", packageName)
+	require.NoError(suite.T(), derr)
+	require.Equal(suite.T(), []string{"acme-worker", "postgresql", "acme-cron"}, dependencies, "should ignore nested sequences & other top level keys")
+}
+
+func (suite *EngineHelmTestSuite) TestEngineHelm_SetDependencyVersion() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "Chart.yaml")
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_METADATA_PATH).Return("Chart.yaml").MinTimes(1)
+
+	//copy fixture into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "chart_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "helm", "chart_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	helmEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_HELM, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+	changeSet := changeset.New()
+	helmEngine.SetChangeSet(changeSet)
+	dependencyManager := helmEngine.(engine.DependencyManager)
+
+	//test
+	require.NoError(suite.T(), dependencyManager.SetDependencyVersion("acme-worker", "0.3.0"))
+	require.NoError(suite.T(), dependencyManager.SetDependencyVersion("acme-cron", "0.2.1"))
+	require.NoError(suite.T(), dependencyManager.SetDependencyVersion("postgresql", "13.0.0"))
+
+	//assert
+	require.NoError(suite.T(), changeSet.Commit())
+	chartYaml, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "Chart.yaml"))
+	require.NoError(suite.T(), err)
+	expectedChartYaml, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "Chart.yaml.dependencies.expected"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), string(expectedChartYaml), string(chartYaml), "should only update the constraints of the matching subcharts")
+}
+
+func (suite *EngineHelmTestSuite) TestEngineHelm_GetVersion() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "Chart.yaml")
+	suite.PipelineData.GitLocalPath = path.Join("testdata", "helm")
+
+	helmEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_HELM, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+	versionReader := helmEngine.(engine.VersionReader)
+
+	//test
+	chartVersion, cerr := versionReader.GetVersion(path.Join(suite.PipelineData.GitLocalPath, "chart_analogj_test"))
+	_, merr := versionReader.GetVersion(suite.PipelineData.GitLocalPath)
+
+	//assert
+	require.NoError(suite.T(), cerr)
+	require.Equal(suite.T(), "0.4.1", chartVersion)
+	require.Error(suite.T(), merr)
+}
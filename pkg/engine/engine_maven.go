@@ -0,0 +1,310 @@
+package engine
+
+import (
+	"fmt"
+	"github.com/Masterminds/semver"
+	"github.com/analogj/go-util/utils"
+	"github.com/packagrio/bumpr/pkg/config"
+	"github.com/packagrio/go-common/errors"
+	"github.com/packagrio/go-common/pipeline"
+	"github.com/packagrio/go-common/scm"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const mavenVersionMetadataPath = "pom.xml"
+const mavenSnapshotSuffix = "-SNAPSHOT"
+
+// MavenMetadata is the metadata of a Maven project (go-common does not define a Maven metadata type).
+type MavenMetadata struct {
+	GroupId    string
+	ArtifactId string
+	Version    string
+}
+
+// a version that references a property, eg. `${revision}` (CI friendly versions)
+var mavenPropertyPattern = regexp.MustCompile(`^\$\{([A-Za-z0-9_.-]+)\}$`)
+
+// engineMaven reads & writes the `<project><version>` of a pom.xml file, and the `<parent><version>` of the child modules
+// of a multi-module build. The files are edited in place (preserving their formatting), so `mvn` is not required.
+type engineMaven struct {
+	engineBase
+
+	Scm             scm.Interface //Interface
+	CurrentMetadata *MavenMetadata
+	NextMetadata    *MavenMetadata
+}
+
+func (g *engineMaven) Init(pipelineData *pipeline.Data, configData config.Interface, sourceScm scm.Interface) error {
+	g.Scm = sourceScm
+	g.Config = configData
+	g.PipelineData = pipelineData
+	g.CurrentMetadata = new(MavenMetadata)
+	g.NextMetadata = new(MavenMetadata)
+
+	//set command defaults (can be overridden by repo/system configuration)
+	g.Config.SetDefault(config.PACKAGR_VERSION_METADATA_PATH, mavenVersionMetadataPath)
+	return nil
+}
+
+func (g *engineMaven) GetCurrentMetadata() interface{} {
+	return g.CurrentMetadata
+}
+func (g *engineMaven) GetNextMetadata() interface{} {
+	return g.NextMetadata
+}
+func (g *engineMaven) GetCurrentVersion() string {
+	return g.CurrentMetadata.Version
+}
+
+// ValidateTools is a no-op, the pom.xml files are updated without the `mvn` binary.
+func (g *engineMaven) ValidateTools() error {
+	return nil
+}
+
+func (g *engineMaven) DetectPackage(gitLocalPath string, configImpl config.Interface) (bool, string) {
+	versionMetadataPath := configImpl.GetString(config.PACKAGR_VERSION_METADATA_PATH)
+	if path.Ext(versionMetadataPath) != ".xml" {
+		versionMetadataPath = mavenVersionMetadataPath
+	}
+	if utils.FileExists(path.Join(gitLocalPath, versionMetadataPath)) {
+		return true, fmt.Sprintf("found %s", versionMetadataPath)
+	}
+	return false, ""
+}
+
+func (g *engineMaven) BumpVersion() error {
+	pomPath := mavenPomPath(path.Join(g.PipelineData.GitLocalPath, g.Config.GetString(config.PACKAGR_VERSION_METADATA_PATH)))
+	if !utils.FileExists(pomPath) {
+		return errors.EngineBuildPackageInvalid(fmt.Sprintf("%s file is required to process Maven project", g.Config.GetString(config.PACKAGR_VERSION_METADATA_PATH)))
+	}
+
+	if merr := g.retrieveCurrentMetadata(pomPath); merr != nil {
+		return merr
+	}
+
+	if perr := g.populateNextMetadata(); perr != nil {
+		return perr
+	}
+
+	if nerr := g.SetVersion(pomPath, g.NextMetadata.Version); nerr != nil {
+		return nerr
+	}
+
+	return nil
+}
+
+// SetVersion updates the version of a pom.xml file (or the pom.xml in the specified directory), and the parent version
+// of its modules.
+func (g *engineMaven) SetVersion(versionMetadataPath string, nextVersion string) error {
+	return g.writeNextMetadata(mavenPomPath(versionMetadataPath), nextVersion)
+}
+
+// GetVersion reads the `<project><version>` from a pom.xml file (or the pom.xml in the specified directory). A version
+// that references a property (eg. `${revision}`) is resolved from the `<properties>` of the same file.
+func (g *engineMaven) GetVersion(versionMetadataPath string) (string, error) {
+	pomPath := mavenPomPath(versionMetadataPath)
+	pomContent, err := g.readFile(pomPath)
+	if err != nil {
+		return "", err
+	}
+	versionElement, err := mavenVersionElement(pomPath, pomContent)
+	if err != nil {
+		return "", err
+	}
+	return versionElement.Text, nil
+}
+
+// SetDevelopmentVersion sets the version to the next development version (eg. `1.2.4-SNAPSHOT` after `1.2.3`), when
+// `maven_next_development_version` is enabled. A prerelease is followed by the development of its release version
+// (eg. `2.0.0-SNAPSHOT` after `2.0.0-rc.1`).
+func (g *engineMaven) SetDevelopmentVersion(releaseVersion string) (string, error) {
+	if !g.Config.GetBool(config.PACKAGR_MAVEN_NEXT_DEVELOPMENT_VERSION) {
+		return "", nil
+	}
+	v, err := semver.NewVersion(releaseVersion)
+	if err != nil {
+		return "", err
+	}
+	developmentPatch := v.Patch() + 1
+	if v.Prerelease() != "" {
+		developmentPatch = v.Patch()
+	}
+	developmentVersion := fmt.Sprintf("%d.%d.%d%s", v.Major(), v.Minor(), developmentPatch, mavenSnapshotSuffix)
+	pomPath := mavenPomPath(path.Join(g.PipelineData.GitLocalPath, g.Config.GetString(config.PACKAGR_VERSION_METADATA_PATH)))
+	if err := g.SetVersion(pomPath, developmentVersion); err != nil {
+		return "", err
+	}
+	return developmentVersion, nil
+}
+
+//private Helpers
+
+func (g *engineMaven) retrieveCurrentMetadata(pomPath string) error {
+	pomContent, err := g.readFile(pomPath)
+	if err != nil {
+		return err
+	}
+	versionElement, err := mavenVersionElement(pomPath, pomContent)
+	if err != nil {
+		return err
+	}
+	g.CurrentMetadata.Version = versionElement.Text
+	if g.CurrentMetadata.GroupId, err = mavenGroupId(pomContent); err != nil {
+		return err
+	}
+	artifactId, _, err := findXmlElement(pomContent, "project", "artifactId")
+	g.CurrentMetadata.ArtifactId = artifactId.Text
+	return err
+}
+
+// populateNextMetadata generates the next version, a `-SNAPSHOT` version is a prerelease of its release version, so
+// bumping `1.2.3-SNAPSHOT` (eg. with the `release` or `patch` bump types) releases `1.2.3`. SNAPSHOT is not a
+// prerelease identifier with a counter, so the `prerelease` bump type starts the prereleases of the release version
+// instead (eg. `1.2.3-rc.1`).
+func (g *engineMaven) populateNextMetadata() error {
+
+	currentVersion, err := g.ResolveCurrentVersion(g.CurrentMetadata.Version)
+	if err != nil {
+		return err
+	}
+
+	var nextVersion string
+	if strings.HasSuffix(currentVersion, mavenSnapshotSuffix) && g.Config.GetString(config.PACKAGR_VERSION_BUMP_TYPE) == "prerelease" {
+		nextVersion, err = g.generateSnapshotPrereleaseVersion(currentVersion)
+	} else {
+		nextVersion, err = g.GenerateNextVersion(currentVersion)
+	}
+	if err != nil {
+		return err
+	}
+
+	g.NextMetadata.Version = nextVersion
+	g.NextMetadata.GroupId = g.CurrentMetadata.GroupId
+	g.NextMetadata.ArtifactId = g.CurrentMetadata.ArtifactId
+	g.PipelineData.ReleaseVersion = g.NextMetadata.Version
+	return nil
+}
+
+func (g *engineMaven) generateSnapshotPrereleaseVersion(currentVersion string) (string, error) {
+	if g.Config.GetString(config.PACKAGR_VERSION_PRERELEASE_ID) == "" {
+		return "", errors.EngineBuildPackageFailed(fmt.Sprintf("Could not bump the prerelease of %s, the %s setting is required for -SNAPSHOT versions", currentVersion, config.PACKAGR_VERSION_PRERELEASE_ID))
+	}
+	v, err := semver.NewVersion(currentVersion)
+	if err != nil {
+		return "", err
+	}
+	return g.generatePrereleaseVersion(v.Major(), v.Minor(), v.Patch(), "")
+}
+
+func (g *engineMaven) writeNextMetadata(pomPath string, nextVersion string) error {
+	pomContent, err := g.readFile(pomPath)
+	if err != nil {
+		return err
+	}
+	versionElement, err := mavenVersionElement(pomPath, pomContent)
+	if err != nil {
+		return err
+	}
+	if err := g.writeFile(pomPath, replaceXmlElementText(pomContent, versionElement, nextVersion), 0644); err != nil {
+		return err
+	}
+	return g.writeModules(pomPath, pomContent, versionElement.Text, nextVersion)
+}
+
+// writeModules updates the `<parent><version>` of the modules (recursively) that reference the parent pom, and their
+// `<project><version>` if it is the same as the parent version.
+func (g *engineMaven) writeModules(parentPath string, parentContent []byte, currentVersion string, nextVersion string) error {
+	parentArtifactId, _, err := findXmlElement(parentContent, "project", "artifactId")
+	if err != nil {
+		return err
+	}
+	modules, err := findXmlElements(parentContent, "project", "modules", "module")
+	if err != nil {
+		return err
+	}
+	for _, module := range modules {
+		modulePath := mavenPomPath(path.Join(path.Dir(parentPath), module.Text))
+		if !utils.FileExists(modulePath) {
+			return errors.EngineBuildPackageFailed(fmt.Sprintf("Could not find the %s module (%s)", module.Text, modulePath))
+		}
+		moduleContent, err := g.readFile(modulePath)
+		if err != nil {
+			return err
+		}
+
+		moduleParentArtifactId, _, err := findXmlElement(moduleContent, "project", "parent", "artifactId")
+		if err != nil {
+			return err
+		}
+		moduleParentVersion, ok, err := findXmlElement(moduleContent, "project", "parent", "version")
+		if err != nil {
+			return err
+		} else if !ok || moduleParentArtifactId.Text != parentArtifactId.Text || moduleParentVersion.Text != currentVersion {
+			// the module does not inherit from the bumped pom (eg. an aggregator-only build)
+			continue
+		}
+		updatedContent := replaceXmlElementText(moduleContent, moduleParentVersion, nextVersion)
+
+		// the module version is bumped as well when it is declared explicitly, and the same as the parent version.
+		// Otherwise the nested modules reference the (unchanged) module version.
+		moduleVersion, ok, err := findXmlElement(updatedContent, "project", "version")
+		if err != nil {
+			return err
+		}
+		bumpModule := !ok || moduleVersion.Text == currentVersion
+		if ok && bumpModule {
+			updatedContent = replaceXmlElementText(updatedContent, moduleVersion, nextVersion)
+		}
+		if err := g.writeFile(modulePath, updatedContent, 0644); err != nil {
+			return err
+		}
+		if !bumpModule {
+			continue
+		}
+		if err := g.writeModules(modulePath, updatedContent, currentVersion, nextVersion); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mavenVersionElement returns the `<project><version>` element, or the `<properties>` element it references.
+func mavenVersionElement(pomPath string, pomContent []byte) (xmlElement, error) {
+	versionElement, ok, err := findXmlElement(pomContent, "project", "version")
+	if err != nil {
+		return xmlElement{}, err
+	} else if !ok {
+		return xmlElement{}, errors.EngineBuildPackageFailed(fmt.Sprintf("Could not find <project><version> in %s, versions inherited from the parent pom are not supported", pomPath))
+	}
+	if match := mavenPropertyPattern.FindStringSubmatch(versionElement.Text); match != nil {
+		propertyElement, ok, err := findXmlElement(pomContent, "project", "properties", match[1])
+		if err != nil {
+			return xmlElement{}, err
+		} else if !ok {
+			return xmlElement{}, errors.EngineBuildPackageFailed(fmt.Sprintf("Could not find the %s property referenced by <project><version> in %s", match[1], pomPath))
+		}
+		return propertyElement, nil
+	}
+	return versionElement, nil
+}
+
+// mavenGroupId returns the project groupId, which may be inherited from the parent pom.
+func mavenGroupId(pomContent []byte) (string, error) {
+	for _, elementPath := range [][]string{{"project", "groupId"}, {"project", "parent", "groupId"}} {
+		groupId, ok, err := findXmlElement(pomContent, elementPath...)
+		if err != nil || ok {
+			return groupId.Text, err
+		}
+	}
+	return "", nil
+}
+
+// the version metadata path may be the project directory, or the pom file itself.
+func mavenPomPath(versionMetadataPath string) string {
+	if strings.HasSuffix(versionMetadataPath, ".xml") {
+		return versionMetadataPath
+	}
+	return path.Join(versionMetadataPath, mavenVersionMetadataPath)
+}
@@ -0,0 +1,379 @@
+//go:build maven
+// +build maven
+
+package engine_test
+
+import (
+	"github.com/analogj/go-util/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/packagrio/bumpr/pkg/config"
+	"github.com/packagrio/bumpr/pkg/config/mock"
+	"github.com/packagrio/bumpr/pkg/engine"
+	"github.com/packagrio/go-common/pipeline"
+	"github.com/packagrio/go-common/scm"
+	"github.com/packagrio/go-common/scm/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestEngineMaven_Create(t *testing.T) {
+	//setup
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+
+	testConfig.Set(config.PACKAGR_SCM, "github")
+	testConfig.Set(config.PACKAGR_PACKAGE_TYPE, "maven")
+	pipelineData := new(pipeline.Data)
+	githubScm, err := scm.Create("github", pipelineData, testConfig, &http.Client{})
+	require.NoError(t, err)
+
+	//test
+	mavenEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_MAVEN, pipelineData, testConfig, githubScm)
+
+	//assert
+	require.NoError(t, err)
+	require.NotNil(t, mavenEngine)
+}
+
+// Define the suite, and absorb the built-in basic suite
+// functionality from testify - including a T() method which
+// returns the current testing context
+type EngineMavenTestSuite struct {
+	suite.Suite
+	MockCtrl     *gomock.Controller
+	Scm          *mock_scm.MockInterface
+	Config       *mock_config.MockInterface
+	PipelineData *pipeline.Data
+}
+
+// Make sure that VariableThatShouldStartAtFive is set to five
+// before each test
+func (suite *EngineMavenTestSuite) SetupTest() {
+	suite.MockCtrl = gomock.NewController(suite.T())
+
+	suite.PipelineData = new(pipeline.Data)
+
+	suite.Config = mock_config.NewMockInterface(suite.MockCtrl)
+	suite.Scm = mock_scm.NewMockInterface(suite.MockCtrl)
+
+}
+
+func (suite *EngineMavenTestSuite) TearDownTest() {
+	suite.MockCtrl.Finish()
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestEngineMaven_TestSuite(t *testing.T) {
+	suite.Run(t, new(EngineMavenTestSuite))
+}
+
+func (suite *EngineMavenTestSuite) TestEngineMaven_ValidateTools() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "pom.xml")
+	mavenEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_MAVEN, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	verr := mavenEngine.ValidateTools()
+
+	//assert
+	require.NoError(suite.T(), verr, "should not require the mvn binary")
+}
+
+func (suite *EngineMavenTestSuite) TestEngineMaven_BumpVersion() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "pom.xml")
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_METADATA_PATH).Return("pom.xml").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("release").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
+
+	//copy fixture into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "pom_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "maven", "pom_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	mavenEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_MAVEN, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := mavenEngine.BumpVersion()
+	require.NoError(suite.T(), berr)
+
+	//assert
+	require.Equal(suite.T(), &engine.MavenMetadata{GroupId: "io.acme", ArtifactId: "acme-parent", Version: "1.2.3-SNAPSHOT"}, mavenEngine.GetCurrentMetadata())
+	require.Equal(suite.T(), &engine.MavenMetadata{GroupId: "io.acme", ArtifactId: "acme-parent", Version: "1.2.3"}, mavenEngine.GetNextMetadata(), "should strip the -SNAPSHOT suffix")
+	pomXml, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "pom.xml"))
+	require.NoError(suite.T(), err)
+	expectedPomXml, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "pom.xml.expected"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), string(expectedPomXml), string(pomXml), "should only update the project version, preserving the formatting")
+	corePomXml, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "acme-core", "pom.xml"))
+	require.NoError(suite.T(), err)
+	require.Contains(suite.T(), string(corePomXml), "<artifactId>acme-parent</artifactId>\n        <version>1.2.3</version>\n    </parent>")
+	cliPomXml, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "acme-cli", "pom.xml"))
+	require.NoError(suite.T(), err)
+	require.Contains(suite.T(), string(cliPomXml), "<artifactId>acme-parent</artifactId>\n        <version>1.2.3</version>\n    </parent>")
+}
+
+func (suite *EngineMavenTestSuite) TestEngineMaven_BumpVersion_MissingModule() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "pom.xml")
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_METADATA_PATH).Return("pom.xml").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("minor").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
+
+	//copy fixture into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "pom_missing_module_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "maven", "pom_missing_module_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	mavenEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_MAVEN, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := mavenEngine.BumpVersion()
+
+	//assert
+	require.Error(suite.T(), berr, "should fail when a module is missing")
+}
+
+func (suite *EngineMavenTestSuite) TestEngineMaven_BumpVersion_NestedModules() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "pom.xml")
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_METADATA_PATH).Return("pom.xml").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("major").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
+
+	//copy fixture into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "pom_nested_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "maven", "pom_nested_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	mavenEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_MAVEN, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := mavenEngine.BumpVersion()
+	require.NoError(suite.T(), berr)
+
+	//assert
+	require.Equal(suite.T(), "1.0.0", mavenEngine.GetNextMetadata().(*engine.MavenMetadata).Version)
+	require.Equal(suite.T(), "io.acme", mavenEngine.GetNextMetadata().(*engine.MavenMetadata).GroupId)
+	servicesPomXml, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "services", "pom.xml"))
+	require.NoError(suite.T(), err)
+	require.Contains(suite.T(), string(servicesPomXml), "<version>1.0.0</version></parent>\n  <artifactId>acme-services</artifactId>\n  <version>1.0.0</version>")
+	apiPomXml, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "services", "api", "pom.xml"))
+	require.NoError(suite.T(), err)
+	require.Contains(suite.T(), string(apiPomXml), "<artifactId>acme-services</artifactId><version>1.0.0</version>", "should update nested modules")
+	toolsPomXml, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "tools", "pom.xml"))
+	require.NoError(suite.T(), err)
+	require.Contains(suite.T(), string(toolsPomXml), "<version>1.0.0</version></parent>\n  <artifactId>acme-tools</artifactId>\n  <version>3.1.0</version>", "should not update an independent module version")
+}
+
+func (suite *EngineMavenTestSuite) TestEngineMaven_BumpVersion_Property() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "pom.xml")
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_METADATA_PATH).Return("app").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("patch").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
+
+	//copy fixture into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "pom_property_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "maven", "pom_property_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	mavenEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_MAVEN, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := mavenEngine.BumpVersion()
+	require.NoError(suite.T(), berr)
+
+	//assert
+	require.Equal(suite.T(), "4.0.1", mavenEngine.GetCurrentMetadata().(*engine.MavenMetadata).Version)
+	pomXml, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "app", "pom.xml"))
+	require.NoError(suite.T(), err)
+	expectedPomXml, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "app", "pom.xml.expected"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), string(expectedPomXml), string(pomXml), "should update the referenced property")
+}
+
+func (suite *EngineMavenTestSuite) TestEngineMaven_SetDevelopmentVersion() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "pom.xml").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_METADATA_PATH).Return("pom.xml").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("release").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
+	suite.Config.EXPECT().GetBool(config.PACKAGR_MAVEN_NEXT_DEVELOPMENT_VERSION).Return(false).Times(1)
+	suite.Config.EXPECT().GetBool(config.PACKAGR_MAVEN_NEXT_DEVELOPMENT_VERSION).Return(true).Times(1)
+
+	//copy fixture into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "pom_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "maven", "pom_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	mavenEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_MAVEN, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+	require.NoError(suite.T(), mavenEngine.BumpVersion())
+
+	//test
+	disabledVersion, derr := mavenEngine.(engine.DevelopmentVersionManager).SetDevelopmentVersion("1.2.3")
+
+	//assert
+	require.NoError(suite.T(), derr)
+	require.Empty(suite.T(), disabledVersion, "should be disabled by default")
+	pomXml, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "pom.xml"))
+	require.NoError(suite.T(), err)
+	require.Contains(suite.T(), string(pomXml), "<version>1.2.3</version>")
+
+	//test
+	developmentVersion, serr := mavenEngine.(engine.DevelopmentVersionManager).SetDevelopmentVersion("1.2.3")
+
+	//assert
+	require.NoError(suite.T(), serr)
+	require.Equal(suite.T(), "1.2.4-SNAPSHOT", developmentVersion)
+	pomXml, err = ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "pom.xml"))
+	require.NoError(suite.T(), err)
+	require.Contains(suite.T(), string(pomXml), "<!-- bumped by packagr -->\n    <version>1.2.4-SNAPSHOT</version>")
+	corePomXml, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "acme-core", "pom.xml"))
+	require.NoError(suite.T(), err)
+	require.Contains(suite.T(), string(corePomXml), "<version>1.2.4-SNAPSHOT</version>\n    </parent>")
+}
+
+func (suite *EngineMavenTestSuite) TestEngineMaven_BumpVersion_Prerelease() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "pom.xml")
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_METADATA_PATH).Return("pom.xml").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("prerelease").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_PRERELEASE_ID).Return("rc").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
+
+	//copy fixture into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "pom_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "maven", "pom_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	mavenEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_MAVEN, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := mavenEngine.BumpVersion()
+	require.NoError(suite.T(), berr)
+
+	//assert
+	require.Equal(suite.T(), "1.2.3-rc.1", mavenEngine.GetNextMetadata().(*engine.MavenMetadata).Version, "should start the prereleases of the release version, not increment the SNAPSHOT qualifier")
+	pomXml, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "pom.xml"))
+	require.NoError(suite.T(), err)
+	require.Contains(suite.T(), string(pomXml), "<!-- bumped by packagr -->\n    <version>1.2.3-rc.1</version>")
+}
+
+func (suite *EngineMavenTestSuite) TestEngineMaven_BumpVersion_PrereleaseWithoutId() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "pom.xml")
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_METADATA_PATH).Return("pom.xml").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("prerelease").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_PRERELEASE_ID).Return("").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
+
+	//copy fixture into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "pom_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "maven", "pom_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	mavenEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_MAVEN, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := mavenEngine.BumpVersion()
+
+	//assert
+	require.Error(suite.T(), berr, "should not generate 1.2.3-SNAPSHOT.1")
+	pomXml, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "pom.xml"))
+	require.NoError(suite.T(), err)
+	require.Contains(suite.T(), string(pomXml), "<version>1.2.3-SNAPSHOT</version>")
+}
+
+func (suite *EngineMavenTestSuite) TestEngineMaven_SetDevelopmentVersion_Prerelease() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "pom.xml")
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_METADATA_PATH).Return("pom.xml").MinTimes(1)
+	suite.Config.EXPECT().GetBool(config.PACKAGR_MAVEN_NEXT_DEVELOPMENT_VERSION).Return(true)
+
+	//copy fixture into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "pom_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "maven", "pom_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	mavenEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_MAVEN, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	developmentVersion, serr := mavenEngine.(engine.DevelopmentVersionManager).SetDevelopmentVersion("2.0.0-rc.1")
+
+	//assert
+	require.NoError(suite.T(), serr)
+	require.Equal(suite.T(), "2.0.0-SNAPSHOT", developmentVersion, "should continue the development of the release version")
+	pomXml, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "pom.xml"))
+	require.NoError(suite.T(), err)
+	require.Contains(suite.T(), string(pomXml), "<!-- bumped by packagr -->\n    <version>2.0.0-SNAPSHOT</version>")
+}
+
+func (suite *EngineMavenTestSuite) TestEngineMaven_GetVersion() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "pom.xml")
+	suite.PipelineData.GitLocalPath = path.Join("testdata", "maven", "pom_analogj_test")
+
+	mavenEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_MAVEN, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+	versionReader := mavenEngine.(engine.VersionReader)
+
+	//test
+	projectVersion, perr := versionReader.GetVersion(path.Join(suite.PipelineData.GitLocalPath, "pom.xml"))
+	directoryVersion, derr := versionReader.GetVersion(suite.PipelineData.GitLocalPath)
+	_, merr := versionReader.GetVersion(path.Join(suite.PipelineData.GitLocalPath, "acme-core"))
+
+	//assert
+	require.NoError(suite.T(), perr)
+	require.Equal(suite.T(), "1.2.3-SNAPSHOT", projectVersion)
+	require.NoError(suite.T(), derr)
+	require.Equal(suite.T(), "1.2.3-SNAPSHOT", directoryVersion)
+	require.Error(suite.T(), merr, "should not read a version inherited from the parent pom")
+}
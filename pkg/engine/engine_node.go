@@ -1,7 +1,9 @@
 package engine
 
 import (
+	"bytes"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
 	"github.com/analogj/go-util/utils"
 	"github.com/packagrio/bumpr/pkg/config"
@@ -9,8 +11,7 @@ import (
 	"github.com/packagrio/go-common/metadata"
 	"github.com/packagrio/go-common/pipeline"
 	"github.com/packagrio/go-common/scm"
-	"io/ioutil"
-	"os/exec"
+	"io"
 	"path"
 )
 
@@ -38,14 +39,20 @@ func (g *engineNode) GetCurrentMetadata() interface{} {
 func (g *engineNode) GetNextMetadata() interface{} {
 	return g.NextMetadata
 }
+func (g *engineNode) GetCurrentVersion() string {
+	return g.CurrentMetadata.Version
+}
 
+// ValidateTools is a no-op, package.json & the lock files are updated without the `node` & `npm` binaries.
 func (g *engineNode) ValidateTools() error {
+	return nil
+}
 
-	if _, kerr := exec.LookPath("node"); kerr != nil {
-		return errors.EngineValidateToolError("node binary is missing")
+func (g *engineNode) DetectPackage(gitLocalPath string, configImpl config.Interface) (bool, string) {
+	if utils.FileExists(path.Join(gitLocalPath, "package.json")) {
+		return true, "found package.json"
 	}
-
-	return nil
+	return false, ""
 }
 
 func (g *engineNode) BumpVersion() error {
@@ -70,11 +77,29 @@ func (g *engineNode) SetVersion(versionMetadataPath string, nextVersion string)
 	return g.writeNextMetadata(versionMetadataPath, nextVersion)
 }
 
+// GetVersion reads the version from a package.json file (or the package.json in the specified directory).
+func (g *engineNode) GetVersion(versionMetadataPath string) (string, error) {
+	packagePath := versionMetadataPath
+	if path.Base(versionMetadataPath) != "package.json" {
+		packagePath = path.Join(versionMetadataPath, "package.json")
+	}
+	packageContent, rerr := g.readFile(packagePath)
+	if rerr != nil {
+		return "", rerr
+	}
+
+	packageMetadata := new(metadata.NodeMetadata)
+	if uerr := json.Unmarshal(packageContent, packageMetadata); uerr != nil {
+		return "", uerr
+	}
+	return packageMetadata.Version, nil
+}
+
 //private Helpers
 
 func (g *engineNode) retrieveCurrentMetadata(gitLocalPath string) error {
 	//read package.json file.
-	packageContent, rerr := ioutil.ReadFile(path.Join(gitLocalPath, "package.json"))
+	packageContent, rerr := g.readFile(path.Join(gitLocalPath, "package.json"))
 	if rerr != nil {
 		return rerr
 	}
@@ -88,7 +113,12 @@ func (g *engineNode) retrieveCurrentMetadata(gitLocalPath string) error {
 
 func (g *engineNode) populateNextMetadata() error {
 
-	nextVersion, err := g.GenerateNextVersion(g.CurrentMetadata.Version)
+	currentVersion, err := g.ResolveCurrentVersion(g.CurrentMetadata.Version)
+	if err != nil {
+		return err
+	}
+
+	nextVersion, err := g.GenerateNextVersion(currentVersion)
 	if err != nil {
 		return err
 	}
@@ -99,12 +129,184 @@ func (g *engineNode) populateNextMetadata() error {
 	return nil
 }
 
-func (g *engineNode) writeNextMetadata(gitLocalPath string, nextVersion string) error {
-	// The version will be bumped up via the npm version command.
-	// --no-git-tag-version ensures that we dont create a git commit (which npm will do by default).
-	versionCmd := fmt.Sprintf("npm --no-git-tag-version version %s", nextVersion)
-	if verr := utils.BashCmdExec(versionCmd, gitLocalPath, nil, ""); verr != nil {
-		return errors.EngineTestRunnerError("npm version bump failed")
+func (g *engineNode) writeNextMetadata(versionMetadataPath string, nextVersion string) error {
+	// the version metadata path may be the package directory, or the package.json file itself.
+	packagePath := versionMetadataPath
+	if path.Base(versionMetadataPath) == "package.json" {
+		packagePath = path.Dir(versionMetadataPath)
+	}
+
+	// The version is updated in place (rather than via `npm version`) so that the changes can be staged, and the
+	// existing formatting of the files is preserved.
+	if werr := g.writeJsonVersion(path.Join(packagePath, "package.json"), []string{"version"}, nextVersion); werr != nil {
+		return errors.EngineTestRunnerError(fmt.Sprintf("npm version bump failed: %s", werr))
+	}
+
+	// lock files also store the package version (in lockfileVersion 2+, it is duplicated in the root package entry), it
+	// is omitted by packages without a version.
+	for _, lockFileName := range []string{"package-lock.json", "npm-shrinkwrap.json"} {
+		lockFilePath := path.Join(packagePath, lockFileName)
+		if !utils.FileExists(lockFilePath) {
+			continue
+		}
+		if werr := g.writeJsonVersion(lockFilePath, []string{"version"}, nextVersion); werr != nil && werr != errJsonKeyNotFound {
+			return errors.EngineTestRunnerError(fmt.Sprintf("npm version bump failed for %s: %s", lockFileName, werr))
+		}
+		if werr := g.writeJsonVersion(lockFilePath, []string{"packages", "", "version"}, nextVersion); werr != nil && werr != errJsonKeyNotFound {
+			return errors.EngineTestRunnerError(fmt.Sprintf("npm version bump failed for %s: %s", lockFileName, werr))
+		}
+	}
+	return nil
+}
+
+var errJsonKeyNotFound = stderrors.New("key not found")
+
+func (g *engineNode) writeJsonVersion(jsonPath string, keyPath []string, nextVersion string) error {
+	jsonContent, rerr := g.readFile(jsonPath)
+	if rerr != nil {
+		return rerr
+	}
+	updatedContent, jerr := replaceJsonStringValue(jsonContent, keyPath, nextVersion)
+	if jerr != nil {
+		return jerr
+	}
+	return g.writeFile(jsonPath, updatedContent, 0644)
+}
+
+// replaceJsonStringValue replaces the string value at the key path (eg. packages -> "" -> version) without
+// re-serializing (and reformatting) the json document.
+func replaceJsonStringValue(jsonContent []byte, keyPath []string, value string) ([]byte, error) {
+	type jsonFrame struct {
+		isObject  bool
+		expectKey bool
+		key       string
+	}
+	stack := []*jsonFrame{}
+
+	matchesKeyPath := func() bool {
+		if len(stack) != len(keyPath) {
+			return false
+		}
+		for ndx, frame := range stack {
+			if !frame.isObject || frame.key != keyPath[ndx] {
+				return false
+			}
+		}
+		return true
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(jsonContent))
+	for {
+		// the decoder offset is just past the previous token, only whitespace & separators precede the next one.
+		tokenOffset := int(decoder.InputOffset())
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, errJsonKeyNotFound
+		} else if err != nil {
+			return nil, err
+		}
+
+		var parent *jsonFrame
+		if len(stack) > 0 {
+			parent = stack[len(stack)-1]
+		}
+
+		if delim, ok := token.(json.Delim); ok {
+			if delim == '{' || delim == '[' {
+				if parent != nil && parent.isObject {
+					parent.expectKey = true
+				}
+				stack = append(stack, &jsonFrame{isObject: delim == '{', expectKey: true})
+			} else {
+				stack = stack[:len(stack)-1]
+			}
+			continue
+		}
+
+		if parent != nil && parent.isObject && parent.expectKey {
+			parent.key = token.(string)
+			parent.expectKey = false
+			continue
+		}
+
+		if _, isString := token.(string); isString && matchesKeyPath() {
+			// the value (including its quotes) spans from its opening quote to the decoder offset, the replacement is
+			// encoded so that quotes & control characters are escaped.
+			start := tokenOffset + bytes.IndexByte(jsonContent[tokenOffset:], '"')
+			end := int(decoder.InputOffset())
+			encodedValue, merr := json.Marshal(value)
+			if merr != nil {
+				return nil, merr
+			}
+			updated := append([]byte{}, jsonContent[:start]...)
+			updated = append(updated, encodedValue...)
+			return append(updated, jsonContent[end:]...), nil
+		}
+		if parent != nil && parent.isObject {
+			parent.expectKey = true
+		}
+	}
+}
+
+// sections of package.json that declare dependencies on other packages
+var nodeDependencySections = []string{"dependencies", "devDependencies", "peerDependencies", "optionalDependencies"}
+
+// PackageName returns the package.json name.
+func (g *engineNode) PackageName() (string, error) {
+	packageMetadata := new(metadata.NodeMetadata)
+	if err := g.readPackageJson(packageMetadata); err != nil {
+		return "", err
+	}
+	return packageMetadata.Name, nil
+}
+
+// Dependencies returns the packages listed in any of the package.json dependency sections.
+func (g *engineNode) Dependencies() ([]string, error) {
+	packageDependencies := map[string]map[string]string{}
+	if err := g.readPackageJson(&packageDependencies); err != nil {
+		return nil, err
+	}
+	dependencies := []string{}
+	for _, section := range nodeDependencySections {
+		for dependencyName := range packageDependencies[section] {
+			dependencies = append(dependencies, dependencyName)
+		}
+	}
+	return dependencies, nil
+}
+
+// SetDependencyVersion updates the version specifier of the dependency in every package.json section that lists it,
+// preserving the range operator (eg. `^1.2.3`). Lock files are not updated, they are regenerated by `npm install`.
+func (g *engineNode) SetDependencyVersion(dependencyName string, version string) error {
+	packageDependencies := map[string]map[string]string{}
+	if err := g.readPackageJson(&packageDependencies); err != nil {
+		return err
+	}
+	for _, section := range nodeDependencySections {
+		specifier, ok := packageDependencies[section][dependencyName]
+		if !ok {
+			continue
+		}
+		updatedSpecifier, ok := updateVersionSpecifier(specifier, version)
+		if !ok {
+			continue
+		}
+		if werr := g.writeJsonVersion(path.Join(g.PipelineData.GitLocalPath, "package.json"), []string{section, dependencyName}, updatedSpecifier); werr != nil {
+			return errors.EngineTestRunnerError(fmt.Sprintf("npm dependency update failed: %s", werr))
+		}
+	}
+	return nil
+}
+
+func (g *engineNode) readPackageJson(v interface{}) error {
+	packageContent, rerr := g.readFile(path.Join(g.PipelineData.GitLocalPath, "package.json"))
+	if rerr != nil {
+		return rerr
+	}
+	// sections with unexpected types (eg. a `dependencies` array) are ignored
+	var typeErr *json.UnmarshalTypeError
+	if uerr := json.Unmarshal(packageContent, v); uerr != nil && !stderrors.As(uerr, &typeErr) {
+		return uerr
 	}
 	return nil
 }
@@ -93,6 +93,7 @@ func (suite *EngineNodeTestSuite) TestEngineNode_VersionBump() {
 	//setup
 	//suite.Config.EXPECT().SetDefault(gomock.Any(), gomock.Any()).MinTimes(1)
 	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("patch").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
 
 	//copy cookbook fixture into a temp directory.
 	parentPath, err := ioutil.TempDir("", "")
@@ -110,6 +111,7 @@ func (suite *EngineNodeTestSuite) TestEngineNode_VersionBump() {
 	require.NoError(suite.T(), berr)
 
 	//assert
+	require.Equal(suite.T(), "1.0.8", nodeEngine.GetCurrentVersion())
 	require.Equal(suite.T(), "1.0.9", nodeEngine.GetNextMetadata().(*metadata.NodeMetadata).Version)
 
 }
@@ -137,3 +139,176 @@ func (suite *EngineNodeTestSuite) TestEngineNode_VersionBump_WithoutPackageJson(
 	//assert
 	require.Error(suite.T(), berr, "should return an error")
 }
+
+func (suite *EngineNodeTestSuite) TestEngineNode_VersionBump_WithLockFile() {
+	//setup
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("patch").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
+
+	//copy fixture into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "npm_lockfile_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "node", "npm_lockfile_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	nodeEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_NODE, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := nodeEngine.BumpVersion()
+	require.NoError(suite.T(), berr)
+
+	//assert
+	require.Equal(suite.T(), "1.0.9", nodeEngine.GetNextMetadata().(*metadata.NodeMetadata).Version)
+	packageJson, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "package.json"))
+	require.NoError(suite.T(), err)
+	require.Contains(suite.T(), string(packageJson), `"version": "1.0.9"`)
+	require.Contains(suite.T(), string(packageJson), `"left-pad": "1.3.0"`, "should not modify dependency versions")
+
+	lockFile, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "package-lock.json"))
+	require.NoError(suite.T(), err)
+	expectedLockFile, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "package-lock.json.expected"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), string(expectedLockFile), string(lockFile), "should update the top-level and root package versions only")
+}
+
+func (suite *EngineNodeTestSuite) TestEngineNode_VersionBump_EscapedStrings() {
+	//setup
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("patch").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
+
+	//copy fixture into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "npm_escaped_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "node", "npm_escaped_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	nodeEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_NODE, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := nodeEngine.BumpVersion()
+	require.NoError(suite.T(), berr)
+
+	//assert
+	require.Equal(suite.T(), "1.0.9", nodeEngine.GetNextMetadata().(*metadata.NodeMetadata).Version)
+	for _, fileName := range []string{"package.json", "package-lock.json"} {
+		content, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, fileName))
+		require.NoError(suite.T(), err)
+		expectedContent, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, fileName+".expected"))
+		require.NoError(suite.T(), err)
+		require.Equal(suite.T(), string(expectedContent), string(content), "should replace the whole (escaped) version string of "+fileName)
+	}
+}
+
+func TestEngineNode_VersionBump_LockFileWithoutVersion(t *testing.T) {
+	//setup
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+	testConfig.Set(config.PACKAGR_VERSION_BUMP_TYPE, "minor")
+	pipelineData := new(pipeline.Data)
+	pipelineData.GitLocalPath = t.TempDir()
+	require.NoError(t, ioutil.WriteFile(path.Join(pipelineData.GitLocalPath, "package.json"), []byte(`{"name": "acme-app", "version": "0.4.1", "private": true}`), 0644))
+	lockFile := `{
+  "name": "acme-app",
+  "lockfileVersion": 3,
+  "requires": true,
+  "packages": {
+    "": {
+      "name": "acme-app"
+    }
+  }
+}
+`
+	require.NoError(t, ioutil.WriteFile(path.Join(pipelineData.GitLocalPath, "package-lock.json"), []byte(lockFile), 0644))
+	nodeEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_NODE, pipelineData, testConfig, nil)
+	require.NoError(t, err)
+
+	//test
+	berr := nodeEngine.BumpVersion()
+
+	//assert
+	require.NoError(t, berr, "should not require a version in the lock file")
+	require.Equal(t, "0.5.0", nodeEngine.GetNextMetadata().(*metadata.NodeMetadata).Version)
+	lockContent, err := ioutil.ReadFile(path.Join(pipelineData.GitLocalPath, "package-lock.json"))
+	require.NoError(t, err)
+	require.Equal(t, lockFile, string(lockContent))
+}
+
+func TestEngineNode_Dependencies(t *testing.T) {
+	//setup
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+	pipelineData := new(pipeline.Data)
+	pipelineData.GitLocalPath = t.TempDir()
+	require.NoError(t, ioutil.WriteFile(path.Join(pipelineData.GitLocalPath, "package.json"), []byte(`{
+  "name": "@acme/cli",
+  "version": "0.4.1",
+  "files": ["lib"],
+  "dependencies": {
+    "@acme/sdk": "^1.0.0",
+    "left-pad": "1.3.0"
+  },
+  "devDependencies": {
+    "@acme/sdk": "~1.0.0",
+    "@acme/test-utils": "workspace:*"
+  }
+}
+`), 0644))
+
+	nodeEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_NODE, pipelineData, testConfig, nil)
+	require.NoError(t, err)
+	dependencyManager := nodeEngine.(engine.DependencyManager)
+
+	//test
+	packageName, nerr := dependencyManager.PackageName()
+	dependencies, derr := dependencyManager.Dependencies()
+	require.NoError(t, dependencyManager.SetDependencyVersion("@acme/sdk", "1.1.0"))
+	require.NoError(t, dependencyManager.SetDependencyVersion("@acme/test-utils", "2.0.0"))
+
+	//assert
+	require.NoError(t, nerr)
+	require.Equal(t, "@acme/cli", packageName)
+	require.NoError(t, derr)
+	require.ElementsMatch(t, []string{"@acme/sdk", "left-pad", "@acme/sdk", "@acme/test-utils"}, dependencies)
+	content, err := ioutil.ReadFile(path.Join(pipelineData.GitLocalPath, "package.json"))
+	require.NoError(t, err)
+	require.Equal(t, `{
+  "name": "@acme/cli",
+  "version": "0.4.1",
+  "files": ["lib"],
+  "dependencies": {
+    "@acme/sdk": "^1.1.0",
+    "left-pad": "1.3.0"
+  },
+  "devDependencies": {
+    "@acme/sdk": "~1.1.0",
+    "@acme/test-utils": "workspace:*"
+  }
+}
+`, string(content), "should preserve the range operators, and leave workspace references unchanged")
+}
+
+func TestEngineNode_GetVersion(t *testing.T) {
+	//setup
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+	pipelineData := new(pipeline.Data)
+	pipelineData.GitLocalPath = t.TempDir()
+	require.NoError(t, ioutil.WriteFile(path.Join(pipelineData.GitLocalPath, "package.json"), []byte("{\n  \"name\": \"acme\",\n  \"version\": \"1.8.0\"\n}\n"), 0644))
+	nodeEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_NODE, pipelineData, testConfig, nil)
+	require.NoError(t, err)
+
+	//test
+	version, err := nodeEngine.(engine.VersionReader).GetVersion(path.Join(pipelineData.GitLocalPath, "package.json"))
+
+	//assert
+	require.NoError(t, err)
+	require.Equal(t, "1.8.0", version)
+}
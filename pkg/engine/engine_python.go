@@ -1,15 +1,16 @@
 package engine
 
 import (
+	"fmt"
 	"github.com/analogj/go-util/utils"
 	"github.com/packagrio/bumpr/pkg/config"
 	"github.com/packagrio/go-common/errors"
 	"github.com/packagrio/go-common/metadata"
 	"github.com/packagrio/go-common/pipeline"
 	"github.com/packagrio/go-common/scm"
-	"io/ioutil"
 	"os/exec"
 	"path"
+	"regexp"
 	"strings"
 )
 
@@ -39,6 +40,9 @@ func (g *enginePython) GetCurrentMetadata() interface{} {
 func (g *enginePython) GetNextMetadata() interface{} {
 	return g.NextMetadata
 }
+func (g *enginePython) GetCurrentVersion() string {
+	return g.CurrentMetadata.Version
+}
 
 func (g *enginePython) ValidateTools() error {
 	if _, berr := exec.LookPath("python"); berr != nil {
@@ -48,15 +52,24 @@ func (g *enginePython) ValidateTools() error {
 	return nil
 }
 
+func (g *enginePython) DetectPackage(gitLocalPath string, configImpl config.Interface) (bool, string) {
+	for _, markerFile := range []string{"setup.py", "pyproject.toml"} {
+		if utils.FileExists(path.Join(gitLocalPath, markerFile)) {
+			return true, fmt.Sprintf("found %s", markerFile)
+		}
+	}
+	return false, ""
+}
+
 func (g *enginePython) BumpVersion() error {
-	//validate that the python setup.py file exists
-	if !utils.FileExists(path.Join(g.PipelineData.GitLocalPath, "setup.py")) {
-		return errors.EngineBuildPackageInvalid("setup.py file is required to process Python package")
+	//validate that the python setup.py (or pyproject.toml) file exists
+	if !utils.FileExists(path.Join(g.PipelineData.GitLocalPath, "setup.py")) && !utils.FileExists(path.Join(g.PipelineData.GitLocalPath, "pyproject.toml")) {
+		return errors.EngineBuildPackageInvalid("setup.py or pyproject.toml file is required to process Python package")
 	}
 
 	// check for/create required VERSION file
 	if !utils.FileExists(path.Join(g.PipelineData.GitLocalPath, g.Config.GetString(config.PACKAGR_VERSION_METADATA_PATH))) {
-		ioutil.WriteFile(path.Join(g.PipelineData.GitLocalPath, g.Config.GetString(config.PACKAGR_VERSION_METADATA_PATH)),
+		g.writeFile(path.Join(g.PipelineData.GitLocalPath, g.Config.GetString(config.PACKAGR_VERSION_METADATA_PATH)),
 			[]byte("0.0.0"),
 			0644,
 		)
@@ -90,21 +103,35 @@ func (g *enginePython) SetVersion(versionMetadataPath string, nextVersion string
 	return g.writeNextMetadata(versionMetadataPath, nextVersion)
 }
 
+// GetVersion reads the version from a plain text version file.
+func (g *enginePython) GetVersion(versionMetadataPath string) (string, error) {
+	versionContent, rerr := g.readFile(versionMetadataPath)
+	if rerr != nil {
+		return "", rerr
+	}
+	return strings.TrimSpace(string(versionContent)), nil
+}
+
 //private Helpers
 
 func (g *enginePython) retrieveCurrentMetadata(gitLocalPath string) error {
 	//read metadata.json file.
-	versionContent, rerr := ioutil.ReadFile(path.Join(gitLocalPath, g.Config.GetString(config.PACKAGR_VERSION_METADATA_PATH)))
+	version, rerr := g.GetVersion(path.Join(gitLocalPath, g.Config.GetString(config.PACKAGR_VERSION_METADATA_PATH)))
 	if rerr != nil {
 		return rerr
 	}
-	g.CurrentMetadata.Version = strings.TrimSpace(string(versionContent))
+	g.CurrentMetadata.Version = version
 	return nil
 }
 
 func (g *enginePython) populateNextMetadata() error {
 
-	nextVersion, err := g.GenerateNextVersion(g.CurrentMetadata.Version)
+	currentVersion, err := g.ResolveCurrentVersion(g.CurrentMetadata.Version)
+	if err != nil {
+		return err
+	}
+
+	nextVersion, err := g.GenerateNextVersion(currentVersion)
 	if err != nil {
 		return err
 	}
@@ -115,5 +142,94 @@ func (g *enginePython) populateNextMetadata() error {
 }
 
 func (g *enginePython) writeNextMetadata(gitLocalMetadataPath string, nextVersion string) error {
-	return ioutil.WriteFile(gitLocalMetadataPath, []byte(nextVersion), 0644)
+	return g.writeFile(gitLocalMetadataPath, []byte(nextVersion), 0644)
+}
+
+var (
+	// the `name` argument of setup() in setup.py, or the `name` key in pyproject.toml
+	pythonSetupNamePattern     = regexp.MustCompile(`\bname\s*=\s*["']([^"']+)["']`)
+	pythonPyprojectNamePattern = regexp.MustCompile(`(?m)^\s*name\s*=\s*["']([^"']+)["']`)
+	// a requirements.txt line with a single version clause, eg. `sdk==1.2.3` or `sdk[extra] >= 1.2.3 ; python_version > "3"`
+	pythonRequirementPattern = regexp.MustCompile(`^\s*([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*((?:===|==|>=|~=)\s*[^\s,;#]+)?\s*(?:[,;#<>!@]|$)`)
+	pythonNameSeparators     = regexp.MustCompile(`[-_.]+`)
+)
+
+// PackageName returns the normalized (PEP 503) distribution name, declared in pyproject.toml or setup.py.
+func (g *enginePython) PackageName() (string, error) {
+	for _, candidate := range []struct {
+		fileName string
+		pattern  *regexp.Regexp
+	}{
+		{"pyproject.toml", pythonPyprojectNamePattern},
+		{"setup.py", pythonSetupNamePattern},
+	} {
+		filePath := path.Join(g.PipelineData.GitLocalPath, candidate.fileName)
+		if !utils.FileExists(filePath) {
+			continue
+		}
+		content, err := g.readFile(filePath)
+		if err != nil {
+			return "", err
+		}
+		if match := candidate.pattern.FindSubmatch(content); match != nil {
+			return normalizePythonName(string(match[1])), nil
+		}
+	}
+	return "", nil
+}
+
+// Dependencies returns the (normalized) names of the packages listed in requirements.txt.
+func (g *enginePython) Dependencies() ([]string, error) {
+	lines, err := g.readRequirements()
+	if err != nil {
+		return nil, err
+	}
+	dependencies := []string{}
+	for _, line := range lines {
+		if match := pythonRequirementPattern.FindStringSubmatch(line); match != nil {
+			dependencies = append(dependencies, normalizePythonName(match[1]))
+		}
+	}
+	return dependencies, nil
+}
+
+// SetDependencyVersion updates the version pinned in requirements.txt (eg. `sdk==1.2.3`, `sdk>=1.2.3`). Requirements with
+// multiple version clauses (eg. `sdk>=1.2,<2`) are left unchanged.
+func (g *enginePython) SetDependencyVersion(dependencyName string, version string) error {
+	lines, err := g.readRequirements()
+	if err != nil || len(lines) == 0 {
+		return err
+	}
+	for ndx, line := range lines {
+		match := pythonRequirementPattern.FindStringSubmatchIndex(line)
+		if match == nil || match[4] < 0 || normalizePythonName(line[match[2]:match[3]]) != normalizePythonName(dependencyName) {
+			continue
+		}
+		if strings.HasPrefix(strings.TrimSpace(line[match[5]:]), ",") {
+			continue
+		}
+		updatedClause, ok := updateVersionSpecifier(line[match[4]:match[5]], version)
+		if !ok {
+			continue
+		}
+		lines[ndx] = line[:match[4]] + updatedClause + line[match[5]:]
+	}
+	return g.writeFile(path.Join(g.PipelineData.GitLocalPath, "requirements.txt"), []byte(strings.Join(lines, "")), 0644)
+}
+
+// readRequirements returns the lines of requirements.txt (including line terminators), or nil if the file does not exist.
+func (g *enginePython) readRequirements() ([]string, error) {
+	requirementsPath := path.Join(g.PipelineData.GitLocalPath, "requirements.txt")
+	if !utils.FileExists(requirementsPath) {
+		return nil, nil
+	}
+	content, err := g.readFile(requirementsPath)
+	if err != nil {
+		return nil, err
+	}
+	return strings.SplitAfter(string(content), "\n"), nil
+}
+
+func normalizePythonName(name string) string {
+	return strings.ToLower(pythonNameSeparators.ReplaceAllString(name, "-"))
 }
@@ -95,6 +95,7 @@ func (suite *EnginePythonTestSuite) TestEnginePython_BumpVersion() {
 	//setup
 	suite.Config.EXPECT().SetDefault(gomock.Any(), gomock.Any()).MinTimes(1)
 	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("patch").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
 	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_METADATA_PATH).Return("VERSION").MinTimes(1)
 
 	//copy cookbook fixture into a temp directory.
@@ -120,6 +121,7 @@ func (suite *EnginePythonTestSuite) TestEnginePython_BumpVersion_WithMinimalRepo
 	//setup
 	suite.Config.EXPECT().SetDefault(gomock.Any(), gomock.Any()).MinTimes(1)
 	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("patch").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
 	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_METADATA_PATH).Return("VERSION").MinTimes(1)
 
 	//copy cookbook fixture into a temp directory.
@@ -165,3 +167,63 @@ func (suite *EnginePythonTestSuite) TestEnginePython_BumpVersion_WithoutSetupPy(
 	//assert
 	require.Error(suite.T(), berr, "should return an error")
 }
+
+func TestEnginePython_Dependencies(t *testing.T) {
+	//setup
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+	pipelineData := new(pipeline.Data)
+	pipelineData.GitLocalPath = t.TempDir()
+	require.NoError(t, ioutil.WriteFile(path.Join(pipelineData.GitLocalPath, "setup.py"), []byte(`setup(name="Acme_CLI", version=open("VERSION").read())`), 0644))
+	require.NoError(t, ioutil.WriteFile(path.Join(pipelineData.GitLocalPath, "requirements.txt"), []byte(`# internal
+acme.sdk==1.0.0
+acme-api[async] >= 1.2.0 ; python_version > "3.6"
+acme-models>=1.0,<2
+git+https://github.com/acme/tools.git
+requests
+`), 0644))
+
+	pythonEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_PYTHON, pipelineData, testConfig, nil)
+	require.NoError(t, err)
+	dependencyManager := pythonEngine.(engine.DependencyManager)
+
+	//test
+	packageName, nerr := dependencyManager.PackageName()
+	dependencies, derr := dependencyManager.Dependencies()
+	for _, dependencyName := range []string{"acme-sdk", "acme_api", "acme-models"} {
+		require.NoError(t, dependencyManager.SetDependencyVersion(dependencyName, "1.3.0"))
+	}
+
+	//assert
+	require.NoError(t, nerr)
+	require.Equal(t, "acme-cli", packageName, "should normalize the package name")
+	require.NoError(t, derr)
+	require.Equal(t, []string{"acme-sdk", "acme-api", "acme-models", "requests"}, dependencies)
+	content, err := ioutil.ReadFile(path.Join(pipelineData.GitLocalPath, "requirements.txt"))
+	require.NoError(t, err)
+	require.Equal(t, `# internal
+acme.sdk==1.3.0
+acme-api[async] >= 1.3.0 ; python_version > "3.6"
+acme-models>=1.0,<2
+git+https://github.com/acme/tools.git
+requests
+`, string(content))
+}
+
+func TestEnginePython_GetVersion(t *testing.T) {
+	//setup
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+	pipelineData := new(pipeline.Data)
+	pipelineData.GitLocalPath = t.TempDir()
+	require.NoError(t, ioutil.WriteFile(path.Join(pipelineData.GitLocalPath, "VERSION"), []byte("1.0.2\n"), 0644))
+	pythonEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_PYTHON, pipelineData, testConfig, nil)
+	require.NoError(t, err)
+
+	//test
+	version, err := pythonEngine.(engine.VersionReader).GetVersion(path.Join(pipelineData.GitLocalPath, "VERSION"))
+
+	//assert
+	require.NoError(t, err)
+	require.Equal(t, "1.0.2", version)
+}
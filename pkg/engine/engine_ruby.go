@@ -50,6 +50,9 @@ func (g *engineRuby) GetCurrentMetadata() interface{} {
 func (g *engineRuby) GetNextMetadata() interface{} {
 	return g.NextMetadata
 }
+func (g *engineRuby) GetCurrentVersion() string {
+	return g.CurrentMetadata.Version
+}
 
 func (g *engineRuby) ValidateTools() error {
 	if _, kerr := exec.LookPath("ruby"); kerr != nil {
@@ -59,6 +62,14 @@ func (g *engineRuby) ValidateTools() error {
 	return nil
 }
 
+func (g *engineRuby) DetectPackage(gitLocalPath string, configImpl config.Interface) (bool, string) {
+	gemspecFiles, gerr := filepath.Glob(path.Join(gitLocalPath, "*.gemspec"))
+	if gerr == nil && len(gemspecFiles) > 0 {
+		return true, fmt.Sprintf("found %s", filepath.Base(gemspecFiles[0]))
+	}
+	return false, ""
+}
+
 func (g *engineRuby) BumpVersion() error {
 
 	// bump up the version here.
@@ -92,6 +103,20 @@ func (g *engineRuby) SetVersion(versionMetadataPath string, nextVersion string)
 	return g.writeNextMetadata(versionMetadataPath, nextVersion)
 }
 
+// GetVersion reads the first version string in a ruby file (eg. lib/gem/version.rb), RubyGems prerelease versions are
+// converted to SemVer.
+func (g *engineRuby) GetVersion(versionMetadataPath string) (string, error) {
+	versionrbContent, rerr := g.readFile(versionMetadataPath)
+	if rerr != nil {
+		return "", rerr
+	}
+	version := rubyVersionPattern.FindString(string(versionrbContent))
+	if version == "" {
+		return "", errors.EngineBuildPackageFailed(fmt.Sprintf("Could not find the version in %s", versionMetadataPath))
+	}
+	return g.gemVersionToSemver(version), nil
+}
+
 //private Helpers
 func (g *engineRuby) retrieveCurrentMetadata(gitLocalPath string) error {
 	//read Gemspec file.
@@ -130,7 +155,7 @@ func (g *engineRuby) retrieveCurrentMetadata(gitLocalPath string) error {
 	}
 
 	g.CurrentMetadata.Name = gemspecObj.Name
-	g.CurrentMetadata.Version = gemspecObj.Version.Version
+	g.CurrentMetadata.Version = g.gemVersionToSemver(gemspecObj.Version.Version)
 
 	//ensure that there is a lib/GEMNAME/version.rb file.
 	versionrbPath := path.Join("lib", gemspecObj.Name, "version.rb")
@@ -143,7 +168,12 @@ func (g *engineRuby) retrieveCurrentMetadata(gitLocalPath string) error {
 
 func (g *engineRuby) populateNextMetadata() error {
 
-	nextVersion, err := g.GenerateNextVersion(g.CurrentMetadata.Version)
+	currentVersion, err := g.ResolveCurrentVersion(g.CurrentMetadata.Version)
+	if err != nil {
+		return err
+	}
+
+	nextVersion, err := g.GenerateNextVersion(currentVersion)
 	if err != nil {
 		return err
 	}
@@ -157,11 +187,20 @@ func (g *engineRuby) populateNextMetadata() error {
 func (g *engineRuby) writeNextMetadata(gitLocalMetadataPath string, nextVersion string) error {
 
 	versionrbPath := gitLocalMetadataPath
-	versionrbContent, rerr := ioutil.ReadFile(versionrbPath)
+	versionrbContent, rerr := g.readFile(versionrbPath)
 	if rerr != nil {
 		return rerr
 	}
-	re := regexp.MustCompile(`(\d+)\.(\d+)\.(\d+)`)
-	updatedContent := re.ReplaceAllLiteralString(string(versionrbContent), nextVersion)
-	return ioutil.WriteFile(versionrbPath, []byte(updatedContent), 0644)
+	updatedContent := rubyVersionPattern.ReplaceAllLiteralString(string(versionrbContent), nextVersion)
+	return g.writeFile(versionrbPath, []byte(updatedContent), 0644)
+}
+
+// a semver or RubyGems version (eg. 1.4.0-rc.1, 1.4.0.pre.rc.1)
+var rubyVersionPattern = regexp.MustCompile(`(\d+)\.(\d+)\.(\d+)([-.][0-9A-Za-z][0-9A-Za-z.-]*)?(\+[0-9A-Za-z.-]+)?`)
+
+// RubyGems normalizes prerelease versions (1.4.0-rc.1 is loaded as 1.4.0.pre.rc.1), convert them back to SemVer so that
+// they can be bumped.
+func (g *engineRuby) gemVersionToSemver(gemVersion string) string {
+	re := regexp.MustCompile(`^(\d+\.\d+\.\d+)\.(?:pre\.)?([0-9A-Za-z][0-9A-Za-z.-]*)$`)
+	return re.ReplaceAllString(gemVersion, "$1-$2")
 }
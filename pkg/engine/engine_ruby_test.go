@@ -92,6 +92,7 @@ func (suite *EngineRubyTestSuite) TestEngineRuby_BumpVersion() {
 	//setup
 	//suite.Config.EXPECT().SetDefault(gomock.Any(), gomock.Any()).MinTimes(1)
 	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("patch").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
 
 	//copy cookbook fixture into a temp directory.
 	parentPath, err := ioutil.TempDir("", "")
@@ -117,6 +118,7 @@ func (suite *EngineRubyTestSuite) TestEngineRuby_BumpVersion_WithMinimalGem() {
 	//setup
 	//suite.Config.EXPECT().SetDefault(gomock.Any(), gomock.Any()).MinTimes(1)
 	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("patch").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
 
 	//copy cookbook fixture into a temp directory.
 	parentPath, err := ioutil.TempDir("", "")
@@ -162,3 +164,21 @@ func (suite *EngineRubyTestSuite) TestEngineRuby_BumpVersion_WithoutGemspec() {
 	//assert
 	require.Error(suite.T(), berr, "should return an error")
 }
+
+func TestEngineRuby_GetVersion(t *testing.T) {
+	//setup
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+	pipelineData := new(pipeline.Data)
+	pipelineData.GitLocalPath = t.TempDir()
+	require.NoError(t, ioutil.WriteFile(path.Join(pipelineData.GitLocalPath, "version.rb"), []byte("module Acme\n  VERSION = \"1.4.0.pre.rc.1\"\nend\n"), 0644))
+	rubyEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_RUBY, pipelineData, testConfig, nil)
+	require.NoError(t, err)
+
+	//test
+	version, err := rubyEngine.(engine.VersionReader).GetVersion(path.Join(pipelineData.GitLocalPath, "version.rb"))
+
+	//assert
+	require.NoError(t, err)
+	require.Equal(t, "1.4.0-rc.1", version)
+}
@@ -0,0 +1,447 @@
+package engine
+
+import (
+	"fmt"
+	"github.com/analogj/go-util/utils"
+	"github.com/packagrio/bumpr/pkg/config"
+	"github.com/packagrio/go-common/errors"
+	"github.com/packagrio/go-common/pipeline"
+	"github.com/packagrio/go-common/scm"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const rustVersionMetadataPath = "Cargo.toml"
+
+// RustMetadata is the metadata of a Cargo package (go-common does not define a Rust metadata type).
+type RustMetadata struct {
+	Name    string
+	Version string
+}
+
+// engineRust reads & writes the version of a Cargo package, `[package].version` in Cargo.toml (or
+// `[workspace.package].version` for workspaces). The files are edited in place, so the `cargo` binary is not required.
+type engineRust struct {
+	engineBase
+
+	Scm             scm.Interface //Interface
+	CurrentMetadata *RustMetadata
+	NextMetadata    *RustMetadata
+}
+
+func (g *engineRust) Init(pipelineData *pipeline.Data, configData config.Interface, sourceScm scm.Interface) error {
+	g.Scm = sourceScm
+	g.Config = configData
+	g.PipelineData = pipelineData
+	g.CurrentMetadata = new(RustMetadata)
+	g.NextMetadata = new(RustMetadata)
+
+	//set command defaults (can be overridden by repo/system configuration)
+	g.Config.SetDefault(config.PACKAGR_VERSION_METADATA_PATH, rustVersionMetadataPath)
+	return nil
+}
+
+func (g *engineRust) GetCurrentMetadata() interface{} {
+	return g.CurrentMetadata
+}
+func (g *engineRust) GetNextMetadata() interface{} {
+	return g.NextMetadata
+}
+func (g *engineRust) GetCurrentVersion() string {
+	return g.CurrentMetadata.Version
+}
+
+// ValidateTools is a no-op, Cargo.toml & Cargo.lock are updated without the `cargo` binary.
+func (g *engineRust) ValidateTools() error {
+	return nil
+}
+
+func (g *engineRust) DetectPackage(gitLocalPath string, configImpl config.Interface) (bool, string) {
+	versionMetadataPath := configImpl.GetString(config.PACKAGR_VERSION_METADATA_PATH)
+	if path.Base(versionMetadataPath) != rustVersionMetadataPath {
+		versionMetadataPath = rustVersionMetadataPath
+	}
+	if utils.FileExists(path.Join(gitLocalPath, versionMetadataPath)) {
+		return true, fmt.Sprintf("found %s", versionMetadataPath)
+	}
+	return false, ""
+}
+
+func (g *engineRust) BumpVersion() error {
+	manifestPath := path.Join(g.PipelineData.GitLocalPath, g.Config.GetString(config.PACKAGR_VERSION_METADATA_PATH))
+	if !utils.FileExists(cargoManifestPath(manifestPath)) {
+		return errors.EngineBuildPackageInvalid(fmt.Sprintf("%s file is required to process Rust package", g.Config.GetString(config.PACKAGR_VERSION_METADATA_PATH)))
+	}
+
+	if merr := g.retrieveCurrentMetadata(manifestPath); merr != nil {
+		return merr
+	}
+
+	if perr := g.populateNextMetadata(); perr != nil {
+		return perr
+	}
+
+	if nerr := g.SetVersion(manifestPath, g.NextMetadata.Version); nerr != nil {
+		return nerr
+	}
+
+	return nil
+}
+
+// SetVersion updates the version in Cargo.toml (or the Cargo.toml in the specified directory), and the matching local
+// `[[package]]` entries of the Cargo.lock file (which may be in a parent workspace directory).
+func (g *engineRust) SetVersion(versionMetadataPath string, nextVersion string) error {
+	return g.writeNextMetadata(cargoManifestPath(versionMetadataPath), nextVersion)
+}
+
+// GetVersion reads the `[package].version` (or `[workspace.package].version`) from a Cargo.toml file (or the Cargo.toml
+// in the specified directory).
+func (g *engineRust) GetVersion(versionMetadataPath string) (string, error) {
+	manifest, err := g.readCargoFile(cargoManifestPath(versionMetadataPath))
+	if err != nil {
+		return "", err
+	}
+	versionTable, err := manifest.versionTable()
+	if err != nil {
+		return "", err
+	}
+	version, _, _ := manifest.stringValue(versionTable, "version")
+	return version, nil
+}
+
+//private Helpers
+
+func (g *engineRust) retrieveCurrentMetadata(manifestPath string) error {
+	version, err := g.GetVersion(manifestPath)
+	if err != nil {
+		return err
+	}
+	manifest, err := g.readCargoFile(cargoManifestPath(manifestPath))
+	if err != nil {
+		return err
+	}
+	g.CurrentMetadata.Version = version
+	g.CurrentMetadata.Name, _, _ = manifest.stringValue("package", "name")
+	return nil
+}
+
+func (g *engineRust) populateNextMetadata() error {
+
+	currentVersion, err := g.ResolveCurrentVersion(g.CurrentMetadata.Version)
+	if err != nil {
+		return err
+	}
+
+	nextVersion, err := g.GenerateNextVersion(currentVersion)
+	if err != nil {
+		return err
+	}
+
+	g.NextMetadata.Version = nextVersion
+	g.NextMetadata.Name = g.CurrentMetadata.Name
+	g.PipelineData.ReleaseVersion = g.NextMetadata.Version
+	return nil
+}
+
+func (g *engineRust) writeNextMetadata(manifestPath string, nextVersion string) error {
+	manifest, err := g.readCargoFile(manifestPath)
+	if err != nil {
+		return err
+	}
+	versionTable, err := manifest.versionTable()
+	if err != nil {
+		return err
+	}
+	manifest.setStringValue(versionTable, "version", nextVersion)
+	if werr := g.writeFile(manifestPath, []byte(strings.Join(manifest.lines, "")), 0644); werr != nil {
+		return werr
+	}
+
+	// the packages that use the bumped version: the package itself, or every workspace member that inherits the
+	// workspace version
+	packageNames := map[string]bool{}
+	if versionTable == "package" {
+		packageName, _, _ := manifest.stringValue("package", "name")
+		packageNames[packageName] = true
+	} else {
+		memberManifests, err := g.workspaceMembers(manifest)
+		if err != nil {
+			return err
+		}
+		for _, memberManifest := range append(memberManifests, manifest) {
+			if memberManifest.inheritsWorkspaceVersion() {
+				packageName, _, _ := memberManifest.stringValue("package", "name")
+				packageNames[packageName] = true
+			}
+		}
+	}
+	return g.writeLockFile(path.Dir(manifestPath), packageNames, nextVersion)
+}
+
+// writeLockFile updates the version of the local (without a `source`) `[[package]]` entries of the Cargo.lock file.
+// The lock file of a workspace member is stored in the workspace root, so the parent directories are searched as well
+// (up to the repository root). Packages without a lock file (eg. libraries that don't commit it) are skipped.
+func (g *engineRust) writeLockFile(manifestDir string, packageNames map[string]bool, nextVersion string) error {
+	lockPath := ""
+	for dir := manifestDir; lockPath == ""; dir = path.Dir(dir) {
+		if utils.FileExists(path.Join(dir, "Cargo.lock")) {
+			lockPath = path.Join(dir, "Cargo.lock")
+		} else if dir == path.Dir(dir) || utils.FileExists(path.Join(dir, ".git")) {
+			return nil
+		}
+	}
+
+	lockFile, err := g.readCargoFile(lockPath)
+	if err != nil {
+		return err
+	}
+	for _, table := range lockFile.tables {
+		if !table.array || table.name != "package" {
+			continue
+		}
+		packageName, _, _ := lockFile.tableStringValue(table, "name")
+		if _, _, hasSource := lockFile.tableStringValue(table, "source"); hasSource || !packageNames[packageName] {
+			continue
+		}
+		lockFile.setTableStringValue(table, "version", nextVersion)
+	}
+	return g.writeFile(lockPath, []byte(strings.Join(lockFile.lines, "")), 0644)
+}
+
+// workspaceMembers reads the manifest of every `[workspace].members` package (globs are expanded, the workspace root &
+// the `[workspace].exclude` paths are excluded).
+func (g *engineRust) workspaceMembers(manifest *cargoFile) ([]*cargoFile, error) {
+	workspaceDir := path.Dir(manifest.path)
+	excludeDirs := []string{}
+	for _, exclude := range manifest.stringArray("workspace", "exclude") {
+		excludeDirs = append(excludeDirs, path.Join(workspaceDir, exclude))
+	}
+
+	memberManifests := []*cargoFile{}
+	for _, member := range manifest.stringArray("workspace", "members") {
+		memberDirs, err := filepath.Glob(path.Join(workspaceDir, member))
+		if err != nil {
+			return nil, err
+		}
+		for _, memberDir := range memberDirs {
+			memberPath := path.Join(memberDir, rustVersionMetadataPath)
+			if memberPath == manifest.path || isExcludedCargoMember(memberDir, excludeDirs) || !utils.FileExists(memberPath) {
+				continue
+			}
+			memberManifest, err := g.readCargoFile(memberPath)
+			if err != nil {
+				return nil, err
+			}
+			memberManifests = append(memberManifests, memberManifest)
+		}
+	}
+	return memberManifests, nil
+}
+
+// isExcludedCargoMember returns true if the member directory is (or is nested in) an excluded directory.
+func isExcludedCargoMember(memberDir string, excludeDirs []string) bool {
+	for _, excludeDir := range excludeDirs {
+		if memberDir == excludeDir || strings.HasPrefix(memberDir, excludeDir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *engineRust) readCargoFile(filePath string) (*cargoFile, error) {
+	content, err := g.readFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return parseCargoFile(filePath, string(content)), nil
+}
+
+// the version metadata path may be the package directory, or the Cargo.toml file itself.
+func cargoManifestPath(versionMetadataPath string) string {
+	if strings.HasSuffix(versionMetadataPath, ".toml") {
+		return versionMetadataPath
+	}
+	return path.Join(versionMetadataPath, rustVersionMetadataPath)
+}
+
+var (
+	// a table header (eg. `[workspace.package]`) or an array of tables header (eg. `[[package]]`), with an optional comment
+	cargoTableHeaderPattern = regexp.MustCompile(`^\s*(\[\[?)\s*([A-Za-z0-9_.-]+(?:\s*\.\s*[A-Za-z0-9_-]+)*)\s*\]\]?\s*(?:#.*)?$`)
+	// a version inherited from the workspace, eg. `version.workspace = true` or `version = { workspace = true }`
+	cargoInheritedVersionPattern = regexp.MustCompile(`^\s*version\s*(?:\.\s*workspace\s*=\s*true|=\s*\{[^}]*\bworkspace\s*=\s*true)`)
+	cargoArrayStringPattern      = regexp.MustCompile(`"([^"]*)"|'([^']*)'`)
+)
+
+// cargoFile is a Cargo.toml or Cargo.lock file, split into lines (including line terminators) so that values can be
+// replaced without reformatting the file. Only the subset of TOML used by Cargo files is supported: string values are
+// read from `key = "value"` lines of a table.
+type cargoFile struct {
+	path   string
+	lines  []string
+	tables []cargoTable
+	// multiline is true for the lines inside a multi-line string (after its opening delimiter), they are not keys
+	multiline []bool
+}
+
+// cargoTable is the line range of a table body (the lines after its header), the root table has an empty name.
+type cargoTable struct {
+	name  string
+	array bool
+	start int
+	end   int
+}
+
+func parseCargoFile(filePath string, content string) *cargoFile {
+	file := &cargoFile{path: filePath, lines: strings.SplitAfter(content, "\n")}
+	file.multiline = make([]bool, len(file.lines))
+	table := cargoTable{}
+	multilineDelimiter := ""
+	for ndx, line := range file.lines {
+		// table headers & keys are not recognized inside multi-line strings
+		if multilineDelimiter != "" {
+			file.multiline[ndx] = true
+			if strings.Count(line, multilineDelimiter)%2 == 1 {
+				multilineDelimiter = ""
+			}
+			continue
+		}
+		if match := cargoTableHeaderPattern.FindStringSubmatch(line); match != nil {
+			table.end = ndx
+			file.tables = append(file.tables, table)
+			table = cargoTable{name: strings.Join(strings.Fields(match[2]), ""), array: match[1] == "[[", start: ndx + 1}
+			continue
+		}
+		for _, delimiter := range []string{`"""`, `'''`} {
+			if strings.Count(line, delimiter)%2 == 1 {
+				multilineDelimiter = delimiter
+				break
+			}
+		}
+	}
+	table.end = len(file.lines)
+	file.tables = append(file.tables, table)
+	return file
+}
+
+// table returns the first (non array) table with the name.
+func (f *cargoFile) table(name string) (cargoTable, bool) {
+	for _, table := range f.tables {
+		if table.name == name && !table.array {
+			return table, true
+		}
+	}
+	return cargoTable{}, false
+}
+
+// versionTable returns the table that stores the version: `package`, or `workspace.package` when the package inherits
+// the workspace version (or the manifest is a virtual workspace manifest).
+func (f *cargoFile) versionTable() (string, error) {
+	if _, _, ok := f.stringValue("package", "version"); ok {
+		return "package", nil
+	}
+	if _, _, ok := f.stringValue("workspace.package", "version"); ok {
+		return "workspace.package", nil
+	}
+	if f.inheritsWorkspaceVersion() {
+		return "", errors.EngineBuildPackageFailed(fmt.Sprintf("%s inherits the workspace version, use the workspace Cargo.toml as the version file", f.path))
+	}
+	return "", errors.EngineBuildPackageFailed(fmt.Sprintf("Could not find [package].version or [workspace.package].version in %s", f.path))
+}
+
+// inheritsWorkspaceVersion returns true if the `[package]` version is inherited from the workspace.
+func (f *cargoFile) inheritsWorkspaceVersion() bool {
+	table, ok := f.table("package")
+	if !ok {
+		return false
+	}
+	for ndx := table.start; ndx < table.end; ndx++ {
+		if !f.multiline[ndx] && cargoInheritedVersionPattern.MatchString(f.lines[ndx]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *cargoFile) stringValue(tableName string, key string) (string, int, bool) {
+	table, ok := f.table(tableName)
+	if !ok {
+		return "", -1, false
+	}
+	return f.tableStringValue(table, key)
+}
+
+// tableStringValue returns the string value of the key, and the index of its line.
+func (f *cargoFile) tableStringValue(table cargoTable, key string) (string, int, bool) {
+	pattern := cargoStringValuePattern(key)
+	for ndx := table.start; ndx < table.end; ndx++ {
+		if f.multiline[ndx] {
+			continue
+		}
+		if match := pattern.FindStringSubmatch(f.lines[ndx]); match != nil {
+			return match[2] + match[3], ndx, true
+		}
+	}
+	return "", -1, false
+}
+
+func (f *cargoFile) setStringValue(tableName string, key string, value string) {
+	if table, ok := f.table(tableName); ok {
+		f.setTableStringValue(table, key, value)
+	}
+}
+
+// setTableStringValue replaces the string value of the key, preserving its quotes & any trailing comment.
+func (f *cargoFile) setTableStringValue(table cargoTable, key string, value string) {
+	_, ndx, ok := f.tableStringValue(table, key)
+	if !ok {
+		return
+	}
+	match := cargoStringValuePattern(key).FindStringSubmatchIndex(f.lines[ndx])
+	start, end := match[4], match[5]
+	if start < 0 {
+		start, end = match[6], match[7]
+	}
+	f.lines[ndx] = f.lines[ndx][:start] + value + f.lines[ndx][end:]
+}
+
+// stringArray returns the strings of an array value (eg. `members = ["crates/*"]`), which may span multiple lines.
+func (f *cargoFile) stringArray(tableName string, key string) []string {
+	table, ok := f.table(tableName)
+	if !ok {
+		return nil
+	}
+	keyPattern := regexp.MustCompile(`^\s*` + regexp.QuoteMeta(key) + `\s*=\s*\[`)
+	for ndx := table.start; ndx < table.end; ndx++ {
+		location := keyPattern.FindStringIndex(f.lines[ndx])
+		if location == nil || f.multiline[ndx] {
+			continue
+		}
+		values := []string{}
+		line := f.lines[ndx][location[1]:]
+		for {
+			// comments are removed, they may contain quotes or brackets
+			if commentNdx := strings.Index(line, "#"); commentNdx >= 0 {
+				line = line[:commentNdx]
+			}
+			closingNdx := strings.Index(line, "]")
+			if closingNdx >= 0 {
+				line = line[:closingNdx]
+			}
+			for _, match := range cargoArrayStringPattern.FindAllStringSubmatch(line, -1) {
+				values = append(values, match[1]+match[2])
+			}
+			ndx++
+			if closingNdx >= 0 || ndx >= table.end {
+				return values
+			}
+			line = f.lines[ndx]
+		}
+	}
+	return nil
+}
+
+func cargoStringValuePattern(key string) *regexp.Regexp {
+	return regexp.MustCompile(`^(\s*` + regexp.QuoteMeta(key) + `\s*=\s*)(?:"([^"]*)"|'([^']*)')`)
+}
@@ -0,0 +1,219 @@
+//go:build rust
+// +build rust
+
+package engine_test
+
+import (
+	"github.com/analogj/go-util/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/packagrio/bumpr/pkg/changeset"
+	"github.com/packagrio/bumpr/pkg/config"
+	"github.com/packagrio/bumpr/pkg/config/mock"
+	"github.com/packagrio/bumpr/pkg/engine"
+	"github.com/packagrio/go-common/pipeline"
+	"github.com/packagrio/go-common/scm"
+	"github.com/packagrio/go-common/scm/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestEngineRust_Create(t *testing.T) {
+	//setup
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+
+	testConfig.Set(config.PACKAGR_SCM, "github")
+	testConfig.Set(config.PACKAGR_PACKAGE_TYPE, "rust")
+	pipelineData := new(pipeline.Data)
+	githubScm, err := scm.Create("github", pipelineData, testConfig, &http.Client{})
+	require.NoError(t, err)
+
+	//test
+	rustEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_RUST, pipelineData, testConfig, githubScm)
+
+	//assert
+	require.NoError(t, err)
+	require.NotNil(t, rustEngine)
+}
+
+// Define the suite, and absorb the built-in basic suite
+// functionality from testify - including a T() method which
+// returns the current testing context
+type EngineRustTestSuite struct {
+	suite.Suite
+	MockCtrl     *gomock.Controller
+	Scm          *mock_scm.MockInterface
+	Config       *mock_config.MockInterface
+	PipelineData *pipeline.Data
+}
+
+// Make sure that VariableThatShouldStartAtFive is set to five
+// before each test
+func (suite *EngineRustTestSuite) SetupTest() {
+	suite.MockCtrl = gomock.NewController(suite.T())
+
+	suite.PipelineData = new(pipeline.Data)
+
+	suite.Config = mock_config.NewMockInterface(suite.MockCtrl)
+	suite.Scm = mock_scm.NewMockInterface(suite.MockCtrl)
+
+}
+
+func (suite *EngineRustTestSuite) TearDownTest() {
+	suite.MockCtrl.Finish()
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestEngineRust_TestSuite(t *testing.T) {
+	suite.Run(t, new(EngineRustTestSuite))
+}
+
+func (suite *EngineRustTestSuite) TestEngineRust_ValidateTools() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "Cargo.toml")
+	rustEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_RUST, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	verr := rustEngine.ValidateTools()
+
+	//assert
+	require.NoError(suite.T(), verr, "should not require the cargo binary")
+}
+
+func (suite *EngineRustTestSuite) TestEngineRust_BumpVersion() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "Cargo.toml")
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_METADATA_PATH).Return("Cargo.toml").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("minor").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
+
+	//copy fixture into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "cargo_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "rust", "cargo_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	rustEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_RUST, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := rustEngine.BumpVersion()
+	require.NoError(suite.T(), berr)
+
+	//assert
+	require.Equal(suite.T(), &engine.RustMetadata{Name: "acme-cli", Version: "0.4.1"}, rustEngine.GetCurrentMetadata())
+	require.Equal(suite.T(), &engine.RustMetadata{Name: "acme-cli", Version: "0.5.0"}, rustEngine.GetNextMetadata())
+	require.Equal(suite.T(), "0.4.1", rustEngine.GetCurrentVersion())
+	cargoToml, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "Cargo.toml"))
+	require.NoError(suite.T(), err)
+	expectedCargoToml, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "Cargo.toml.expected"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), string(expectedCargoToml), string(cargoToml), "should only update the package version, preserving the comments & ignoring multi-line strings")
+	cargoLock, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "Cargo.lock"))
+	require.NoError(suite.T(), err)
+	expectedCargoLock, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "Cargo.lock.expected"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), string(expectedCargoLock), string(cargoLock), "should only update the package version, not the registry packages")
+}
+
+func (suite *EngineRustTestSuite) TestEngineRust_BumpVersion_Workspace() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "Cargo.toml")
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_METADATA_PATH).Return("Cargo.toml").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_BUMP_TYPE).Return("release").MinTimes(1)
+	suite.Config.EXPECT().GetString(config.PACKAGR_VERSION_SOURCE).Return("file").MinTimes(1)
+
+	//copy fixture into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "cargo_workspace_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "rust", "cargo_workspace_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	rustEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_RUST, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := rustEngine.BumpVersion()
+	require.NoError(suite.T(), berr)
+
+	//assert
+	require.Equal(suite.T(), "2.0.0-rc.1", rustEngine.GetCurrentMetadata().(*engine.RustMetadata).Version)
+	cargoToml, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "Cargo.toml"))
+	require.NoError(suite.T(), err)
+	expectedCargoToml, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "Cargo.toml.expected"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), string(expectedCargoToml), string(cargoToml), "should update the workspace version, preserving the quotes & ignoring multi-line strings")
+	cargoLock, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "Cargo.lock"))
+	require.NoError(suite.T(), err)
+	expectedCargoLock, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "Cargo.lock.expected"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), string(expectedCargoLock), string(cargoLock), "should update the members that inherit the workspace version, not the excluded packages")
+}
+
+func (suite *EngineRustTestSuite) TestEngineRust_SetVersion_WorkspaceMember() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "Cargo.toml")
+
+	//copy fixture into a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(parentPath)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "cargo_workspace_analogj_test")
+	cerr := utils.CopyDir(path.Join("testdata", "rust", "cargo_workspace_analogj_test"), suite.PipelineData.GitLocalPath)
+	require.NoError(suite.T(), cerr)
+
+	rustEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_RUST, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+	changeSet := changeset.New()
+	rustEngine.SetChangeSet(changeSet)
+
+	//test
+	serr := rustEngine.SetVersion(path.Join(suite.PipelineData.GitLocalPath, "crates", "util"), "0.2.0")
+	require.NoError(suite.T(), serr)
+
+	//assert
+	require.NoError(suite.T(), changeSet.Commit())
+	cargoToml, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "crates", "util", "Cargo.toml"))
+	require.NoError(suite.T(), err)
+	require.Contains(suite.T(), string(cargoToml), "version = \"0.2.0\"\n")
+	cargoLock, err := ioutil.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "Cargo.lock"))
+	require.NoError(suite.T(), err)
+	require.Contains(suite.T(), string(cargoLock), "name = \"acme-util\"\nversion = \"0.2.0\"\n", "should update the lock file of the workspace root")
+	require.Contains(suite.T(), string(cargoLock), "name = \"acme-core\"\nversion = \"2.0.0-rc.1\"\n")
+}
+
+func (suite *EngineRustTestSuite) TestEngineRust_GetVersion() {
+	//setup
+	suite.Config.EXPECT().SetDefault(config.PACKAGR_VERSION_METADATA_PATH, "Cargo.toml")
+	suite.PipelineData.GitLocalPath = path.Join("testdata", "rust", "cargo_workspace_analogj_test")
+
+	rustEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_RUST, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+	versionReader := rustEngine.(engine.VersionReader)
+
+	//test
+	workspaceVersion, werr := versionReader.GetVersion(path.Join(suite.PipelineData.GitLocalPath, "Cargo.toml"))
+	packageVersion, perr := versionReader.GetVersion(path.Join(suite.PipelineData.GitLocalPath, "crates", "util"))
+	_, ierr := versionReader.GetVersion(path.Join(suite.PipelineData.GitLocalPath, "crates", "cli", "Cargo.toml"))
+
+	//assert
+	require.NoError(suite.T(), werr)
+	require.Equal(suite.T(), "2.0.0-rc.1", workspaceVersion)
+	require.NoError(suite.T(), perr)
+	require.Equal(suite.T(), "0.1.0", packageVersion)
+	require.Error(suite.T(), ierr, "should not read the version of a package that inherits the workspace version")
+}
@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"github.com/packagrio/bumpr/pkg/config"
+	"github.com/packagrio/go-common/metadata"
+	"github.com/packagrio/go-common/pipeline"
+	"github.com/packagrio/go-common/scm"
+)
+
+// engineTag uses the latest semver git tag as the version source of truth. No files are modified, the next version is
+// only exported via the `release_version` output.
+type engineTag struct {
+	engineBase
+
+	Scm             scm.Interface //Interface
+	CurrentMetadata *metadata.GenericMetadata
+	NextMetadata    *metadata.GenericMetadata
+}
+
+func (g *engineTag) Init(pipelineData *pipeline.Data, configData config.Interface, sourceScm scm.Interface) error {
+	g.Scm = sourceScm
+	g.Config = configData
+	g.PipelineData = pipelineData
+	g.CurrentMetadata = new(metadata.GenericMetadata)
+	g.NextMetadata = new(metadata.GenericMetadata)
+
+	return nil
+}
+
+func (g *engineTag) GetCurrentMetadata() interface{} {
+	return g.CurrentMetadata
+}
+func (g *engineTag) GetNextMetadata() interface{} {
+	return g.NextMetadata
+}
+func (g *engineTag) GetCurrentVersion() string {
+	return g.CurrentMetadata.Version
+}
+
+func (g *engineTag) ValidateTools() error {
+	return nil
+}
+
+func (g *engineTag) DetectPackage(gitLocalPath string, configImpl config.Interface) (bool, string) {
+	// the tag engine can't be detected from the repository contents, it must be selected explicitly.
+	return false, ""
+}
+
+func (g *engineTag) BumpVersion() error {
+	if merr := g.retrieveCurrentMetadata(g.PipelineData.GitLocalPath); merr != nil {
+		return merr
+	}
+
+	if perr := g.populateNextMetadata(); perr != nil {
+		return perr
+	}
+
+	return nil
+}
+
+// SetVersion is a no-op, tags are created by the release step (or an SCM), not by bumpr.
+func (g *engineTag) SetVersion(versionMetadataPath string, nextVersion string) error {
+	return nil
+}
+
+//private Helpers
+
+func (g *engineTag) retrieveCurrentMetadata(gitLocalPath string) error {
+	tagVersion, err := g.RetrieveTagVersion()
+	if err != nil {
+		return err
+	}
+	g.CurrentMetadata.Version = tagVersion
+	return nil
+}
+
+func (g *engineTag) populateNextMetadata() error {
+
+	nextVersion, err := g.GenerateNextVersion(g.CurrentMetadata.Version)
+	if err != nil {
+		return err
+	}
+
+	g.NextMetadata.Version = nextVersion
+	g.PipelineData.ReleaseVersion = g.NextMetadata.Version
+	return nil
+}
@@ -0,0 +1,173 @@
+//go:build tag
+// +build tag
+
+package engine_test
+
+import (
+	"github.com/analogj/go-util/utils"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/golang/mock/gomock"
+	"github.com/packagrio/bumpr/pkg/config"
+	"github.com/packagrio/bumpr/pkg/engine"
+	"github.com/packagrio/go-common/metadata"
+	"github.com/packagrio/go-common/pipeline"
+	"github.com/packagrio/go-common/scm/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+// Define the suite, and absorb the built-in basic suite
+// functionality from testify - including a T() method which
+// returns the current testing context
+type EngineTagTestSuite struct {
+	suite.Suite
+	MockCtrl     *gomock.Controller
+	Scm          *mock_scm.MockInterface
+	Config       config.Interface
+	PipelineData *pipeline.Data
+	Repo         *git.Repository
+}
+
+// Make sure that VariableThatShouldStartAtFive is set to five
+// before each test
+func (suite *EngineTagTestSuite) SetupTest() {
+	suite.MockCtrl = gomock.NewController(suite.T())
+
+	suite.PipelineData = new(pipeline.Data)
+
+	testConfig, err := config.Create()
+	require.NoError(suite.T(), err)
+	testConfig.Set(config.PACKAGR_SCM, "github")
+	testConfig.Set(config.PACKAGR_PACKAGE_TYPE, "tag")
+	suite.Config = testConfig
+	suite.Scm = mock_scm.NewMockInterface(suite.MockCtrl)
+
+	//create a git repository in a temp directory.
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(suite.T(), err)
+	suite.PipelineData.GitParentPath = parentPath
+	suite.PipelineData.GitLocalPath = path.Join(parentPath, "tag_analogj_test")
+	suite.Repo, err = git.PlainInit(suite.PipelineData.GitLocalPath, false)
+	require.NoError(suite.T(), err)
+}
+
+func (suite *EngineTagTestSuite) TearDownTest() {
+	suite.MockCtrl.Finish()
+	os.RemoveAll(suite.PipelineData.GitParentPath)
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestEngineTag_TestSuite(t *testing.T) {
+	suite.Run(t, new(EngineTagTestSuite))
+}
+
+// commits the current state of the working directory, and optionally tags the commit.
+func (suite *EngineTagTestSuite) commitAndTag(tagName string) {
+	workTree, err := suite.Repo.Worktree()
+	require.NoError(suite.T(), err)
+	require.NoError(suite.T(), ioutil.WriteFile(path.Join(suite.PipelineData.GitLocalPath, "commit.txt"), []byte(tagName), 0644))
+	_, err = workTree.Add(".")
+	require.NoError(suite.T(), err)
+	hash, err := workTree.Commit("commit "+tagName, &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(suite.T(), err)
+	if tagName != "" {
+		_, err = suite.Repo.CreateTag(tagName, hash, nil)
+		require.NoError(suite.T(), err)
+	}
+}
+
+func (suite *EngineTagTestSuite) TestEngineTag_BumpVersion() {
+	//setup
+	suite.commitAndTag("v2.7.0")
+	suite.commitAndTag("v2.7.1")
+	suite.commitAndTag("")
+
+	tagEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_TAG, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := tagEngine.BumpVersion()
+	require.NoError(suite.T(), berr)
+
+	//assert
+	require.Equal(suite.T(), "2.7.1", tagEngine.GetCurrentMetadata().(*metadata.GenericMetadata).Version)
+	require.Equal(suite.T(), "2.7.2", tagEngine.GetNextMetadata().(*metadata.GenericMetadata).Version)
+	require.Equal(suite.T(), "2.7.2", suite.PipelineData.ReleaseVersion)
+}
+
+func (suite *EngineTagTestSuite) TestEngineTag_BumpVersion_WithPrefix() {
+	//setup
+	suite.Config.Set(config.PACKAGR_VERSION_TAG_PREFIX, "api/v")
+	suite.Config.Set(config.PACKAGR_VERSION_BUMP_TYPE, "minor")
+	suite.commitAndTag("api/v1.0.0")
+	suite.commitAndTag("v3.0.0")
+
+	tagEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_TAG, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := tagEngine.BumpVersion()
+	require.NoError(suite.T(), berr)
+
+	//assert
+	require.Equal(suite.T(), "1.1.0", tagEngine.GetNextMetadata().(*metadata.GenericMetadata).Version)
+}
+
+func (suite *EngineTagTestSuite) TestEngineTag_BumpVersion_WithoutTags() {
+	//setup
+	suite.commitAndTag("")
+
+	tagEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_TAG, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := tagEngine.BumpVersion()
+	require.NoError(suite.T(), berr)
+
+	//assert
+	require.Equal(suite.T(), "0.0.1", tagEngine.GetNextMetadata().(*metadata.GenericMetadata).Version)
+}
+
+func (suite *EngineTagTestSuite) TestEngineTag_SetVersion() {
+	//setup
+	tagEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_TAG, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	serr := tagEngine.SetVersion(suite.PipelineData.GitLocalPath, "1.0.0")
+
+	//assert
+	require.NoError(suite.T(), serr)
+}
+
+func (suite *EngineTagTestSuite) TestEngineGeneric_BumpVersion_WithTagVersionSource() {
+	//setup
+	suite.Config.Set(config.PACKAGR_VERSION_SOURCE, "tag")
+	cerr := utils.CopyFile(path.Join("testdata", "generic", "generic_template_analogj_test", "VERSION"), path.Join(suite.PipelineData.GitLocalPath, "VERSION"))
+	require.NoError(suite.T(), cerr)
+	suite.Config.Set(config.PACKAGR_GENERIC_VERSION_TEMPLATE, "%d.%d.%d")
+	suite.commitAndTag("v1.4.2")
+
+	genericEngine, err := engine.Create(engine.PACKAGR_ENGINE_TYPE_GENERIC, suite.PipelineData, suite.Config, suite.Scm)
+	require.NoError(suite.T(), err)
+
+	//test
+	berr := genericEngine.BumpVersion()
+	require.NoError(suite.T(), berr)
+
+	//assert
+	require.Equal(suite.T(), "0.0.1", genericEngine.GetCurrentMetadata().(*metadata.GenericMetadata).Version)
+	require.Equal(suite.T(), "1.4.3", genericEngine.GetNextMetadata().(*metadata.GenericMetadata).Version, "should bump the tag version, not the file version")
+	generated, err := os.ReadFile(path.Join(suite.PipelineData.GitLocalPath, "VERSION"))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), "1.4.3", string(generated))
+}
@@ -9,7 +9,19 @@ import (
 )
 
 func Create(engineType string, pipelineData *pipeline.Data, configImpl config.Interface, sourceImpl scm.Interface) (Interface, error) {
+	eng, err := newEngine(engineType)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := eng.Init(pipelineData, configImpl, sourceImpl); err != nil {
+		return nil, err
+	}
+	return eng, nil
+}
 
+// newEngine returns an uninitialized engine of the specified type
+func newEngine(engineType string) (Interface, error) {
 	var eng Interface
 
 	switch engineType {
@@ -19,18 +31,26 @@ func Create(engineType string, pipelineData *pipeline.Data, configImpl config.In
 		eng = new(engineGeneric)
 	case PACKAGR_ENGINE_TYPE_GOLANG:
 		eng = new(engineGolang)
+	case PACKAGR_ENGINE_TYPE_DOTNET:
+		eng = new(engineDotnet)
+	case PACKAGR_ENGINE_TYPE_GRADLE:
+		eng = new(engineGradle)
+	case PACKAGR_ENGINE_TYPE_HELM:
+		eng = new(engineHelm)
+	case PACKAGR_ENGINE_TYPE_MAVEN:
+		eng = new(engineMaven)
 	case PACKAGR_ENGINE_TYPE_NODE:
 		eng = new(engineNode)
 	case PACKAGR_ENGINE_TYPE_PYTHON:
 		eng = new(enginePython)
 	case PACKAGR_ENGINE_TYPE_RUBY:
 		eng = new(engineRuby)
+	case PACKAGR_ENGINE_TYPE_RUST:
+		eng = new(engineRust)
+	case PACKAGR_ENGINE_TYPE_TAG:
+		eng = new(engineTag)
 	default:
 		return nil, errors.EngineUnspecifiedError(fmt.Sprintf("Unknown Engine Type: %s", engineType))
 	}
-
-	if err := eng.Init(pipelineData, configImpl, sourceImpl); err != nil {
-		return nil, err
-	}
 	return eng, nil
 }
@@ -34,3 +34,8 @@ func TestEngineRuby(t *testing.T) {
 	eng := new(engineRuby)
 	require.Implements(t, (*Interface)(nil), eng, "should implement the Engine interface")
 }
+
+func TestEngineTag(t *testing.T) {
+	eng := new(engineTag)
+	require.Implements(t, (*Interface)(nil), eng, "should implement the Engine interface")
+}
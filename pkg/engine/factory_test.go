@@ -74,6 +74,54 @@ func (suite *FactoryTestSuite) TestCreate_Golang() {
 	require.NotNil(suite.T(), testEngine)
 }
 
+func (suite *FactoryTestSuite) TestCreate_Dotnet() {
+	//setup
+	suite.Config.EXPECT().SetDefault(gomock.Any(), gomock.Any()).MinTimes(1)
+
+	//test
+	testEngine, cerr := engine.Create("dotnet", suite.PipelineData, suite.Config, suite.Scm)
+
+	//assert
+	require.NoError(suite.T(), cerr)
+	require.NotNil(suite.T(), testEngine)
+}
+
+func (suite *FactoryTestSuite) TestCreate_Gradle() {
+	//setup
+	suite.Config.EXPECT().SetDefault(gomock.Any(), gomock.Any()).AnyTimes()
+
+	//test
+	testEngine, cerr := engine.Create("gradle", suite.PipelineData, suite.Config, suite.Scm)
+
+	//assert
+	require.NoError(suite.T(), cerr)
+	require.NotNil(suite.T(), testEngine)
+}
+
+func (suite *FactoryTestSuite) TestCreate_Helm() {
+	//setup
+	suite.Config.EXPECT().SetDefault(gomock.Any(), gomock.Any()).MinTimes(1)
+
+	//test
+	testEngine, cerr := engine.Create("helm", suite.PipelineData, suite.Config, suite.Scm)
+
+	//assert
+	require.NoError(suite.T(), cerr)
+	require.NotNil(suite.T(), testEngine)
+}
+
+func (suite *FactoryTestSuite) TestCreate_Maven() {
+	//setup
+	suite.Config.EXPECT().SetDefault(gomock.Any(), gomock.Any()).MinTimes(1)
+
+	//test
+	testEngine, cerr := engine.Create("maven", suite.PipelineData, suite.Config, suite.Scm)
+
+	//assert
+	require.NoError(suite.T(), cerr)
+	require.NotNil(suite.T(), testEngine)
+}
+
 func (suite *FactoryTestSuite) TestCreate_Node() {
 	//setup
 	//suite.Config.EXPECT().SetDefault(gomock.Any(), gomock.Any()).MinTimes(1)
@@ -110,6 +158,18 @@ func (suite *FactoryTestSuite) TestCreate_Ruby() {
 	require.NotNil(suite.T(), testEngine)
 }
 
+func (suite *FactoryTestSuite) TestCreate_Rust() {
+	//setup
+	suite.Config.EXPECT().SetDefault(gomock.Any(), gomock.Any()).MinTimes(1)
+
+	//test
+	testEngine, cerr := engine.Create("rust", suite.PipelineData, suite.Config, suite.Scm)
+
+	//assert
+	require.NoError(suite.T(), cerr)
+	require.NotNil(suite.T(), testEngine)
+}
+
 func (suite *FactoryTestSuite) TestCreate_Generic() {
 	//setup
 	suite.Config.EXPECT().SetDefault(gomock.Any(), gomock.Any()).MinTimes(1)
@@ -122,6 +182,15 @@ func (suite *FactoryTestSuite) TestCreate_Generic() {
 	require.NotNil(suite.T(), testEngine)
 }
 
+func (suite *FactoryTestSuite) TestCreate_Tag() {
+	//test
+	testEngine, cerr := engine.Create("tag", suite.PipelineData, suite.Config, suite.Scm)
+
+	//assert
+	require.NoError(suite.T(), cerr)
+	require.NotNil(suite.T(), testEngine)
+}
+
 // In order for 'go test' to run this suite, we need to create
 // a normal test function and pass our suite to suite.Run
 func TestFactoryTestSuite(t *testing.T) {
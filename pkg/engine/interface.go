@@ -1,6 +1,7 @@
 package engine
 
 import (
+	"github.com/packagrio/bumpr/pkg/changeset"
 	"github.com/packagrio/bumpr/pkg/config"
 	"github.com/packagrio/go-common/pipeline"
 	"github.com/packagrio/go-common/scm"
@@ -14,16 +15,66 @@ type Interface interface {
 	// Validate that required executables are available for the following build/test/package/etc steps
 	ValidateTools() error
 
+	// Detect if the repository contains a package of this engine type (via marker files), and explain why.
+	// Called before Init, so implementations must not depend on engine state.
+	DetectPackage(gitLocalPath string, configImpl config.Interface) (bool, string)
+
 	BumpVersion() error
 	SetVersion(versionMetadataPath string, nextVersion string) error
 
+	// Route file modifications through a change set (used for dry runs), instead of writing directly to disk
+	SetChangeSet(changeSet *changeset.ChangeSet)
+
 	GetCurrentMetadata() interface{}
 	GetNextMetadata() interface{}
+
+	// the version of the current metadata (read from the version file by BumpVersion)
+	GetCurrentVersion() string
+}
+
+// DependencyManager is implemented by engines that can read & update the dependencies declared by a package (eg. go.mod
+// `require`, package.json `dependencies`), used to bump dependent packages in monorepo mode. Called after Init.
+type DependencyManager interface {
+	// the name used by other packages to depend on this package (eg. the go module path), empty if it has none
+	PackageName() (string, error)
+
+	// the names of the packages this package depends on
+	Dependencies() ([]string, error)
+
+	// update the version of a dependency. Version specifiers that can't be updated safely (eg. ranges, local paths) are
+	// left unchanged.
+	SetDependencyVersion(dependencyName string, version string) error
+}
+
+// VersionReader is implemented by engines that can read the version from a specific version file, the counterpart of
+// SetVersion (eg. to verify the `addl_version_metadata_paths` files). Called after Init.
+type VersionReader interface {
+	GetVersion(versionMetadataPath string) (string, error)
+}
+
+// CurrentVersionResolver is implemented by engines that resolve the version to bump from the `version_source` (eg. the
+// latest git tag), which may differ from the version in the version file. Called after BumpVersion.
+type CurrentVersionResolver interface {
+	ResolvedCurrentVersion() string
+}
+
+// DevelopmentVersionManager is implemented by engines with a development version convention (eg. the maven `-SNAPSHOT`
+// suffix). Called after the release version is written, committed & tagged.
+type DevelopmentVersionManager interface {
+	// set the version files to the development version following the released version (eg. `1.2.4-SNAPSHOT` after
+	// `1.2.3`), returns the development version, or an empty string if the step is disabled.
+	SetDevelopmentVersion(releaseVersion string) (string, error)
 }
 
 const PACKAGR_ENGINE_TYPE_CHEF = "chef"
+const PACKAGR_ENGINE_TYPE_DOTNET = "dotnet"
 const PACKAGR_ENGINE_TYPE_GENERIC = "generic"
 const PACKAGR_ENGINE_TYPE_GOLANG = "golang"
+const PACKAGR_ENGINE_TYPE_GRADLE = "gradle"
+const PACKAGR_ENGINE_TYPE_HELM = "helm"
+const PACKAGR_ENGINE_TYPE_MAVEN = "maven"
 const PACKAGR_ENGINE_TYPE_NODE = "node"
 const PACKAGR_ENGINE_TYPE_PYTHON = "python"
 const PACKAGR_ENGINE_TYPE_RUBY = "ruby"
+const PACKAGR_ENGINE_TYPE_RUST = "rust"
+const PACKAGR_ENGINE_TYPE_TAG = "tag"
@@ -0,0 +1 @@
+Console.WriteLine("2.0.0");
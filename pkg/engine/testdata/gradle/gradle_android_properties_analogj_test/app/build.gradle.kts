@@ -0,0 +1,10 @@
+android {
+    namespace = "io.acme.app"
+
+    defaultConfig {
+        applicationId = "io.acme.app"
+        minSdk = 26
+        versionCode = 41
+        versionName = "2.3.0"
+    }
+}
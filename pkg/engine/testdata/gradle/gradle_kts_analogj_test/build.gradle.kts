@@ -0,0 +1,2 @@
+group = "io.acme"
+version = "3.0.0-rc.1"
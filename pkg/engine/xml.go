@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// xmlElement is the location of the text content of an element, so that it can be replaced without re-serializing (and
+// reformatting) the xml document.
+type xmlElement struct {
+	// the text content, without the surrounding whitespace
+	Text string
+
+	// the byte offsets of the (trimmed) text content, or of the whole tag for a self-closing element (eg. `<version/>`)
+	start int
+	end   int
+
+	// the raw (qualified) name of a self-closing element, which is expanded when its text is replaced
+	selfClosing string
+}
+
+// findXmlElements returns the text content of every element at the path (eg. project -> version), matched by local name.
+// Elements that contain child elements or comments are ignored, self-closing elements have an empty text content.
+func findXmlElements(content []byte, elementPath ...string) ([]xmlElement, error) {
+	elements := []xmlElement{}
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	decoder.Strict = false
+	stack := []string{}
+	tagStart := -1
+	matchStart := -1
+	matchDepth := 0
+	for {
+		offset := int(decoder.InputOffset())
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return elements, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		switch element := token.(type) {
+		case xml.StartElement:
+			stack = append(stack, element.Name.Local)
+			if matchStart >= 0 {
+				// the matched element has child elements
+				matchStart = -1
+			} else if xmlPathEqual(stack, elementPath) {
+				tagStart = offset
+				matchStart = int(decoder.InputOffset())
+				matchDepth = len(stack)
+			}
+		case xml.EndElement:
+			if matchStart >= 0 && len(stack) == matchDepth {
+				raw := content[matchStart:offset]
+				trimmed := bytes.TrimSpace(raw)
+				if offset == matchStart && bytes.HasSuffix(content[:matchStart], []byte("/>")) {
+					// a self-closing element, the decoder returns the end element without reading any input
+					name := bytes.TrimLeft(content[tagStart:matchStart], "<")
+					if nameEnd := bytes.IndexAny(name, " \t\r\n/"); nameEnd >= 0 {
+						name = name[:nameEnd]
+					}
+					elements = append(elements, xmlElement{start: tagStart, end: matchStart, selfClosing: string(name)})
+				} else if !bytes.ContainsAny(raw, "<") {
+					start := matchStart + bytes.Index(raw, trimmed)
+					elements = append(elements, xmlElement{Text: string(trimmed), start: start, end: start + len(trimmed)})
+				}
+				matchStart = -1
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
+
+// findXmlElement returns the first element at the path.
+func findXmlElement(content []byte, elementPath ...string) (xmlElement, bool, error) {
+	elements, err := findXmlElements(content, elementPath...)
+	if err != nil || len(elements) == 0 {
+		return xmlElement{}, false, err
+	}
+	return elements[0], true, nil
+}
+
+// replaceXmlElementText replaces the text content of the element, the value is escaped. A self-closing element is
+// expanded (eg. `<version/>` to `<version>value</version>`), unless the value is empty.
+func replaceXmlElementText(content []byte, element xmlElement, value string) []byte {
+	if element.selfClosing != "" && value == "" {
+		return content
+	}
+	var escaped bytes.Buffer
+	xml.EscapeText(&escaped, []byte(value))
+	replacement := escaped.String()
+	if element.selfClosing != "" {
+		// the start tag (with its attributes) is kept
+		startTag := bytes.TrimRight(bytes.TrimSuffix(content[element.start:element.end], []byte("/>")), " \t\r\n")
+		replacement = fmt.Sprintf("%s>%s</%s>", startTag, replacement, element.selfClosing)
+	}
+	updated := append([]byte{}, content[:element.start]...)
+	updated = append(updated, replacement...)
+	return append(updated, content[element.end:]...)
+}
+
+func xmlPathEqual(stack []string, elementPath []string) bool {
+	if len(stack) != len(elementPath) {
+		return false
+	}
+	for ndx := range stack {
+		if stack[ndx] != elementPath[ndx] {
+			return false
+		}
+	}
+	return true
+}
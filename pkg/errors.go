@@ -0,0 +1,132 @@
+package pkg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Pipeline steps, used to identify which step of the pipeline failed.
+const (
+	PIPELINE_STEP_PARSE_REPO_CONFIG       = "parse_repo_config"
+	PIPELINE_STEP_CREATE_SCM              = "create_scm"
+	PIPELINE_STEP_RETRIEVE_PAYLOAD        = "retrieve_payload"
+	PIPELINE_STEP_DETERMINE_BUMP_TYPE     = "determine_bump_type"
+	PIPELINE_STEP_DETECT_CHANGED_PACKAGES = "detect_changed_packages"
+	PIPELINE_STEP_DETECT_PACKAGE_TYPE     = "detect_package_type"
+	PIPELINE_STEP_CREATE_ENGINE           = "create_engine"
+	PIPELINE_STEP_LOCKSTEP                = "lockstep"
+	PIPELINE_STEP_DEPENDENCY_GRAPH        = "dependency_graph"
+	PIPELINE_STEP_VALIDATE_TOOLS          = "validate_tools"
+	PIPELINE_STEP_BUMP_VERSION            = "bump_version"
+	PIPELINE_STEP_SET_VERSION             = "set_version"
+	PIPELINE_STEP_CHANGELOG               = "changelog"
+	PIPELINE_STEP_PRE_BUMP_HOOK           = "pre_bump_hook"
+	PIPELINE_STEP_WRITE_FILES             = "write_files"
+	PIPELINE_STEP_POST_BUMP_HOOK          = "post_bump_hook"
+	PIPELINE_STEP_GIT_COMMIT              = "git_commit"
+	PIPELINE_STEP_GIT_TAG                 = "git_tag"
+	PIPELINE_STEP_DEVELOPMENT_VERSION     = "development_version"
+	PIPELINE_STEP_SET_OUTPUT              = "set_output"
+	PIPELINE_STEP_WRITE_REPORT            = "write_report"
+	PIPELINE_STEP_VERIFY                  = "verify"
+	PIPELINE_STEP_CHECK                   = "check"
+	PIPELINE_STEP_INIT                    = "init"
+)
+
+// PipelineError is returned by the Pipeline when a step fails. The underlying error (usually one of the go-common
+// errors types) is available via errors.As/errors.Unwrap.
+type PipelineError struct {
+	Step string
+	Err  error
+}
+
+func (e *PipelineError) Error() string {
+	return fmt.Sprintf("%s failed: %s", e.Step, e.Err)
+}
+
+func (e *PipelineError) Unwrap() error {
+	return e.Err
+}
+
+func newPipelineError(step string, err error) error {
+	return &PipelineError{Step: step, Err: err}
+}
+
+// VersionMismatchError is returned in lockstep mode when the packages have different current versions (and
+// `packages_sync` is disabled).
+type VersionMismatchError struct {
+	// the current version of each package, by package name
+	Versions map[string]string
+}
+
+func (e *VersionMismatchError) Error() string {
+	report := "packages have different current versions (use --sync to realign them):"
+	for _, packageName := range sortedKeys(e.Versions) {
+		report += fmt.Sprintf("\n  %s: %s", packageName, e.Versions[packageName])
+	}
+	return report
+}
+
+// DependencyCycleError is returned in monorepo mode when packages depend on each other, so they can't be bumped in
+// dependency order.
+type DependencyCycleError struct {
+	// the packages forming the cycle, the first package is repeated at the end (eg. a -> b -> a)
+	Packages []string
+}
+
+func (e *DependencyCycleError) Error() string {
+	return fmt.Sprintf("dependency cycle detected between packages: %s", strings.Join(e.Packages, " -> "))
+}
+
+// VersionFileMismatchError is returned by Verify when version files have a different version than the primary version
+// file of their package (and were not fixed).
+type VersionFileMismatchError struct {
+	Files []*VerifyFileResult
+}
+
+func (e *VersionFileMismatchError) Error() string {
+	report := "version files do not match the primary version (use --fix to update them):"
+	for _, fileResult := range e.Files {
+		report += fmt.Sprintf("\n  %s: %s (expected %s)", fileResult.Path, fileResult.Version, fileResult.ExpectedVersion)
+	}
+	return report
+}
+
+// VersionNotBumpedError is returned by Check when the head commit of a pull request has the same (or a lower) version
+// than the base commit.
+type VersionNotBumpedError struct {
+	// the package name, only populated in monorepo mode (`packages`)
+	Package         string
+	PreviousVersion string
+	NextVersion     string
+}
+
+func (e *VersionNotBumpedError) Error() string {
+	report := "version was not bumped"
+	if e.PreviousVersion != e.NextVersion {
+		report = "version went backwards"
+	}
+	if e.Package != "" {
+		report = fmt.Sprintf("package %s %s", e.Package, report)
+	}
+	return fmt.Sprintf("%s (%s -> %s)", report, e.PreviousVersion, e.NextVersion)
+}
+
+// InsufficientBumpError is returned by Check (when `check_bump_type` is enabled) when the version bump of a pull request
+// is smaller than the bump required by its commits or labels.
+type InsufficientBumpError struct {
+	// the package name, only populated in monorepo mode (`packages`)
+	Package          string
+	BumpType         string
+	RequiredBumpType string
+	// why the bump type is required, eg. `1 commit(s) require a minor bump`
+	Reason string
+}
+
+func (e *InsufficientBumpError) Error() string {
+	report := fmt.Sprintf("version bump (%s) is smaller than required (%s): %s", e.BumpType, e.RequiredBumpType, e.Reason)
+	if e.Package != "" {
+		report = fmt.Sprintf("package %s %s", e.Package, report)
+	}
+	return report
+}
@@ -0,0 +1,120 @@
+package git
+
+import (
+	"fmt"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// GitCommit stages the files (relative to repoPath, which may be a subdirectory of the repository) and commits them,
+// returns the sha of the new commit. Only the specified files are staged, other modified files in the worktree are left
+// as is. The commit includes the whole index, so it fails if other files are already staged.
+func GitCommit(repoPath string, files []string, message string, author *object.Signature) (string, error) {
+	repo, oerr := git.PlainOpenWithOptions(repoPath, &git.PlainOpenOptions{DetectDotGit: true})
+	if oerr != nil {
+		return "", oerr
+	}
+	workTree, err := repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+	pathPrefix, err := gitPathPrefix(repo, repoPath)
+	if err != nil {
+		return "", err
+	}
+
+	commitPaths := map[string]bool{}
+	for _, file := range files {
+		commitPaths[pathPrefix+filepath.ToSlash(filepath.Clean(file))] = true
+	}
+	status, err := workTree.Status()
+	if err != nil {
+		return "", err
+	}
+	stagedPaths := []string{}
+	for filePath, fileStatus := range status {
+		if fileStatus.Staging != git.Unmodified && fileStatus.Staging != git.Untracked && !commitPaths[filePath] {
+			stagedPaths = append(stagedPaths, filePath)
+		}
+	}
+	if len(stagedPaths) > 0 {
+		sort.Strings(stagedPaths)
+		return "", fmt.Errorf("can't commit the release, other files are already staged (%s), commit or unstage them first", strings.Join(stagedPaths, ", "))
+	}
+
+	for _, file := range files {
+		if _, err := workTree.Add(pathPrefix + file); err != nil {
+			return "", err
+		}
+	}
+	hash, err := workTree.Commit(message, &git.CommitOptions{
+		Author:    author,
+		Committer: author,
+	})
+	if err != nil {
+		return "", err
+	}
+	return hash.String(), nil
+}
+
+// GitCreateTag creates an annotated tag pointing to the revision (eg. a commit sha, or HEAD if empty). Fails if the tag
+// already exists.
+func GitCreateTag(repoPath string, revision string, tagName string, message string, tagger *object.Signature) error {
+	repo, oerr := git.PlainOpenWithOptions(repoPath, &git.PlainOpenOptions{DetectDotGit: true})
+	if oerr != nil {
+		return oerr
+	}
+	if revision == "" {
+		revision = "HEAD"
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return err
+	}
+	_, err = repo.CreateTag(tagName, *hash, &git.CreateTagOptions{
+		Tagger:  tagger,
+		Message: message,
+	})
+	return err
+}
+
+// GitUndoCommit resets the current branch & the index to the parent of the revision, which must be the HEAD commit (eg.
+// a release commit that could not be tagged). The worktree is left as is.
+func GitUndoCommit(repoPath string, revision string) error {
+	repo, oerr := git.PlainOpenWithOptions(repoPath, &git.PlainOpenOptions{DetectDotGit: true})
+	if oerr != nil {
+		return oerr
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return err
+	}
+	if head.Hash().String() != revision {
+		return fmt.Errorf("can't undo commit %s, it is not the HEAD commit", revision)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return err
+	}
+	if commit.NumParents() == 0 {
+		return fmt.Errorf("can't undo commit %s, it is the root commit", revision)
+	}
+	workTree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	return workTree.Reset(&git.ResetOptions{Commit: commit.ParentHashes[0], Mode: git.MixedReset})
+}
+
+// GitDeleteTag deletes the tag (and its annotated tag object).
+func GitDeleteTag(repoPath string, tagName string) error {
+	repo, oerr := git.PlainOpenWithOptions(repoPath, &git.PlainOpenOptions{DetectDotGit: true})
+	if oerr != nil {
+		return oerr
+	}
+	return repo.DeleteTag(tagName)
+}
@@ -0,0 +1,128 @@
+package git_test
+
+import (
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	bumprGit "github.com/packagrio/bumpr/pkg/git"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+func TestGitCommit(t *testing.T) {
+	//setup
+	repoPath := t.TempDir()
+	repo, err := git.PlainInit(repoPath, false)
+	require.NoError(t, err)
+	commitFile(t, repo, repoPath, "initial commit")
+	require.NoError(t, os.MkdirAll(path.Join(repoPath, "sdk"), 0755))
+	require.NoError(t, os.WriteFile(path.Join(repoPath, "sdk", "VERSION"), []byte("1.0.1"), 0644))
+	require.NoError(t, os.WriteFile(path.Join(repoPath, "sdk", "notes.txt"), []byte("unrelated"), 0644))
+	author := &object.Signature{Name: "bot", Email: "bot@example.com", When: time.Now()}
+
+	//test
+	sha, err := bumprGit.GitCommit(path.Join(repoPath, "sdk"), []string{"VERSION"}, "chore(release): 1.0.1", author)
+	require.NoError(t, err)
+
+	//assert
+	commit, err := repo.CommitObject(plumbing.NewHash(sha))
+	require.NoError(t, err)
+	require.Equal(t, "chore(release): 1.0.1", commit.Message)
+	require.Equal(t, "bot", commit.Author.Name)
+	_, ferr := commit.File("sdk/VERSION")
+	require.NoError(t, ferr, "should commit the file relative to the repository root")
+	_, ferr = commit.File("sdk/notes.txt")
+	require.Error(t, ferr, "should only commit the specified files")
+}
+
+func TestGitCommit_StagedChanges(t *testing.T) {
+	//setup
+	repoPath := t.TempDir()
+	repo, err := git.PlainInit(repoPath, false)
+	require.NoError(t, err)
+	head := commitFile(t, repo, repoPath, "initial commit")
+	require.NoError(t, os.WriteFile(path.Join(repoPath, "VERSION"), []byte("1.0.1"), 0644))
+	require.NoError(t, os.WriteFile(path.Join(repoPath, "notes.txt"), []byte("work in progress"), 0644))
+	workTree, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = workTree.Add("notes.txt")
+	require.NoError(t, err)
+	author := &object.Signature{Name: "bot", Email: "bot@example.com", When: time.Now()}
+
+	//test
+	_, err = bumprGit.GitCommit(repoPath, []string{"VERSION"}, "chore(release): 1.0.1", author)
+
+	//assert
+	require.Error(t, err, "should not commit the changes staged by the user")
+	require.Contains(t, err.Error(), "notes.txt")
+	headRef, herr := repo.Head()
+	require.NoError(t, herr)
+	require.Equal(t, head, headRef.Hash(), "should not create a commit")
+}
+
+func TestGitCreateTag(t *testing.T) {
+	//setup
+	repoPath := t.TempDir()
+	repo, err := git.PlainInit(repoPath, false)
+	require.NoError(t, err)
+	head := commitFile(t, repo, repoPath, "initial commit")
+	tagger := &object.Signature{Name: "bot", Email: "bot@example.com", When: time.Now()}
+
+	//test
+	err = bumprGit.GitCreateTag(repoPath, "", "v1.0.0", "release 1.0.0", tagger)
+	require.NoError(t, err)
+	duplicateErr := bumprGit.GitCreateTag(repoPath, head.String(), "v1.0.0", "release 1.0.0", tagger)
+
+	//assert
+	tagRef, err := repo.Tag("v1.0.0")
+	require.NoError(t, err)
+	tagObj, err := repo.TagObject(tagRef.Hash())
+	require.NoError(t, err, "should create an annotated tag")
+	require.Equal(t, head, tagObj.Target)
+	require.Equal(t, "release 1.0.0\n", tagObj.Message)
+	require.Error(t, duplicateErr, "should fail if the tag already exists")
+}
+
+func TestGitUndoCommit(t *testing.T) {
+	//setup
+	repoPath := t.TempDir()
+	repo, err := git.PlainInit(repoPath, false)
+	require.NoError(t, err)
+	parent := commitFile(t, repo, repoPath, "initial commit")
+	head := commitFile(t, repo, repoPath, "release commit")
+
+	//test
+	notHeadErr := bumprGit.GitUndoCommit(repoPath, parent.String())
+	err = bumprGit.GitUndoCommit(repoPath, head.String())
+
+	//assert
+	require.Error(t, notHeadErr, "should only undo the HEAD commit")
+	require.NoError(t, err)
+	headRef, err := repo.Head()
+	require.NoError(t, err)
+	require.Equal(t, parent, headRef.Hash())
+	content, err := os.ReadFile(path.Join(repoPath, "file.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "release commit", string(content), "should not modify the worktree")
+}
+
+func TestGitDeleteTag(t *testing.T) {
+	//setup
+	repoPath := t.TempDir()
+	repo, err := git.PlainInit(repoPath, false)
+	require.NoError(t, err)
+	commitFile(t, repo, repoPath, "initial commit")
+	tagger := &object.Signature{Name: "bot", Email: "bot@example.com", When: time.Now()}
+	require.NoError(t, bumprGit.GitCreateTag(repoPath, "", "v1.0.0", "release 1.0.0", tagger))
+
+	//test
+	err = bumprGit.GitDeleteTag(repoPath, "v1.0.0")
+
+	//assert
+	require.NoError(t, err)
+	_, err = repo.Tag("v1.0.0")
+	require.Error(t, err, "should delete the tag")
+}
@@ -0,0 +1,105 @@
+package git
+
+import (
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// GitChangedFiles returns the files added, modified, renamed or deleted between the merge base of baseSha & headSha and
+// headSha (ie. `git diff base...head`). If headSha is empty, HEAD is used. Paths are relative to repoPath (which may be a
+// subdirectory of the repository), files outside of repoPath are ignored.
+func GitChangedFiles(repoPath string, baseSha string, headSha string) ([]string, error) {
+	repo, oerr := git.PlainOpenWithOptions(repoPath, &git.PlainOpenOptions{DetectDotGit: true})
+	if oerr != nil {
+		return nil, oerr
+	}
+
+	if headSha == "" {
+		headSha = "HEAD"
+	}
+	headCommit, err := gitResolveCommit(repo, headSha)
+	if err != nil {
+		return nil, err
+	}
+	baseCommit, err := gitResolveCommit(repo, baseSha)
+	if err != nil {
+		return nil, err
+	}
+	if mergeBases, merr := baseCommit.MergeBase(headCommit); merr != nil {
+		return nil, merr
+	} else if len(mergeBases) > 0 {
+		baseCommit = mergeBases[0]
+	}
+
+	baseTree, err := baseCommit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	headTree, err := headCommit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	changes, err := object.DiffTree(baseTree, headTree)
+	if err != nil {
+		return nil, err
+	}
+
+	// the diff paths are relative to the repository root
+	pathPrefix, err := gitPathPrefix(repo, repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	changedFiles := map[string]bool{}
+	for _, change := range changes {
+		for _, changedFile := range []string{change.From.Name, change.To.Name} {
+			if changedFile != "" && strings.HasPrefix(changedFile, pathPrefix) {
+				changedFiles[strings.TrimPrefix(changedFile, pathPrefix)] = true
+			}
+		}
+	}
+
+	sortedFiles := []string{}
+	for changedFile := range changedFiles {
+		sortedFiles = append(sortedFiles, changedFile)
+	}
+	sort.Strings(sortedFiles)
+	return sortedFiles, nil
+}
+
+func gitResolveCommit(repo *git.Repository, revision string) (*object.Commit, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, err
+	}
+	return repo.CommitObject(*hash)
+}
+
+// returns the path of repoPath relative to the repository root (with a trailing slash), or an empty string if repoPath is
+// the repository root.
+func gitPathPrefix(repo *git.Repository, repoPath string) (string, error) {
+	workTree, err := repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+	rootPath, err := filepath.EvalSymlinks(workTree.Filesystem.Root())
+	if err != nil {
+		return "", err
+	}
+	absRepoPath, err := filepath.Abs(repoPath)
+	if err != nil {
+		return "", err
+	}
+	if absRepoPath, err = filepath.EvalSymlinks(absRepoPath); err != nil {
+		return "", err
+	}
+	relPath, err := filepath.Rel(rootPath, absRepoPath)
+	if err != nil || relPath == "." {
+		return "", err
+	}
+	return filepath.ToSlash(relPath) + "/", nil
+}
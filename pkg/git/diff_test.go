@@ -0,0 +1,63 @@
+package git_test
+
+import (
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	bumprGit "github.com/packagrio/bumpr/pkg/git"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+// writes the files (creating parent directories), and commits them.
+func commitFiles(t *testing.T, repo *git.Repository, repoPath string, files ...string) plumbing.Hash {
+	workTree, err := repo.Worktree()
+	require.NoError(t, err)
+	for _, filePath := range files {
+		require.NoError(t, os.MkdirAll(path.Dir(path.Join(repoPath, filePath)), 0755))
+		require.NoError(t, os.WriteFile(path.Join(repoPath, filePath), []byte(time.Now().String()), 0644))
+		_, err = workTree.Add(filePath)
+		require.NoError(t, err)
+	}
+	hash, err := workTree.Commit("update files", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+	return hash
+}
+
+func TestGitChangedFiles(t *testing.T) {
+	//setup
+	repoPath := t.TempDir()
+	repo, err := git.PlainInit(repoPath, false)
+	require.NoError(t, err)
+	base := commitFiles(t, repo, repoPath, "cli/main.go", "sdk/index.js", "README.md")
+	commitFiles(t, repo, repoPath, "cli/main.go")
+	head := commitFiles(t, repo, repoPath, "cli/pkg/version.go", "README.md")
+
+	//test
+	changedFiles, err := bumprGit.GitChangedFiles(repoPath, base.String(), head.String())
+
+	//assert
+	require.NoError(t, err)
+	require.Equal(t, []string{"README.md", "cli/main.go", "cli/pkg/version.go"}, changedFiles)
+}
+
+func TestGitChangedFiles_Subdirectory(t *testing.T) {
+	//setup
+	repoPath := t.TempDir()
+	repo, err := git.PlainInit(repoPath, false)
+	require.NoError(t, err)
+	base := commitFiles(t, repo, repoPath, "packages/cli/main.go")
+	commitFiles(t, repo, repoPath, "packages/cli/main.go", "README.md")
+
+	//test
+	changedFiles, err := bumprGit.GitChangedFiles(path.Join(repoPath, "packages"), base.String(), "")
+
+	//assert
+	require.NoError(t, err)
+	require.Equal(t, []string{"cli/main.go"}, changedFiles, "should return paths relative to the subdirectory")
+}
@@ -0,0 +1,36 @@
+package git
+
+import (
+	"path"
+	"strings"
+)
+
+// MatchPathGlob reports whether the slash separated filePath matches the pattern. In addition to the path.Match syntax, a
+// `**` path segment matches zero or more directories (eg. `cli/**` matches every file in the cli directory, and
+// `**/*.md` matches markdown files in any directory).
+func MatchPathGlob(pattern string, filePath string) bool {
+	return matchPathSegments(strings.Split(pattern, "/"), strings.Split(filePath, "/"))
+}
+
+func matchPathSegments(patternSegments []string, pathSegments []string) bool {
+	for len(patternSegments) > 0 {
+		if patternSegments[0] == "**" {
+			// try to match the remaining pattern against every suffix of the path
+			for ndx := 0; ndx <= len(pathSegments); ndx++ {
+				if matchPathSegments(patternSegments[1:], pathSegments[ndx:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(pathSegments) == 0 {
+			return false
+		}
+		if matched, err := path.Match(patternSegments[0], pathSegments[0]); err != nil || !matched {
+			return false
+		}
+		patternSegments = patternSegments[1:]
+		pathSegments = pathSegments[1:]
+	}
+	return len(pathSegments) == 0
+}
@@ -0,0 +1,31 @@
+package git_test
+
+import (
+	bumprGit "github.com/packagrio/bumpr/pkg/git"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMatchPathGlob(t *testing.T) {
+	testCases := []struct {
+		pattern  string
+		filePath string
+		expected bool
+	}{
+		{"cli/**", "cli/main.go", true},
+		{"cli/**", "cli/pkg/version/version.go", true},
+		{"cli/**", "clients/main.go", false},
+		{"cli/*.go", "cli/main.go", true},
+		{"cli/*.go", "cli/pkg/version.go", false},
+		{"**/*.md", "README.md", true},
+		{"**/*.md", "docs/guide/install.md", true},
+		{"**/*.md", "docs/guide/install.txt", false},
+		{"go.mod", "go.mod", true},
+		{"go.mod", "cli/go.mod", false},
+		{"shared/**/proto/*.proto", "shared/api/v1/proto/user.proto", true},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.expected, bumprGit.MatchPathGlob(tc.pattern, tc.filePath), "%s should match %s: %v", tc.pattern, tc.filePath, tc.expected)
+	}
+}
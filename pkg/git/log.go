@@ -0,0 +1,93 @@
+package git
+
+import (
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"strings"
+)
+
+// GitCommitsBetween returns the commits reachable from headSha that are not reachable from baseSha (ie. `git log base..head`)
+// If baseSha is empty, all commits reachable from headSha are returned. If headSha is empty, HEAD is used.
+func GitCommitsBetween(repoPath string, baseSha string, headSha string) ([]*object.Commit, error) {
+	repo, oerr := git.PlainOpenWithOptions(repoPath, &git.PlainOpenOptions{DetectDotGit: true})
+	if oerr != nil {
+		return nil, oerr
+	}
+	return gitCommitsBetween(repo, baseSha, headSha, nil)
+}
+
+// GitPathCommitsBetween returns the commits of GitCommitsBetween that change files in repoPath, when it is a subdirectory
+// of the repository (eg. a package of a monorepo).
+func GitPathCommitsBetween(repoPath string, baseSha string, headSha string) ([]*object.Commit, error) {
+	repo, oerr := git.PlainOpenWithOptions(repoPath, &git.PlainOpenOptions{DetectDotGit: true})
+	if oerr != nil {
+		return nil, oerr
+	}
+	pathPrefix, err := gitPathPrefix(repo, repoPath)
+	if err != nil {
+		return nil, err
+	}
+	var pathFilter func(string) bool
+	if pathPrefix != "" {
+		pathFilter = func(changedFile string) bool {
+			return strings.HasPrefix(changedFile, pathPrefix)
+		}
+	}
+	return gitCommitsBetween(repo, baseSha, headSha, pathFilter)
+}
+
+func gitCommitsBetween(repo *git.Repository, baseSha string, headSha string, pathFilter func(string) bool) ([]*object.Commit, error) {
+
+	if headSha == "" {
+		headSha = "HEAD"
+	}
+	head, err := repo.ResolveRevision(plumbing.Revision(headSha))
+	if err != nil {
+		return nil, err
+	}
+
+	excluded := map[plumbing.Hash]bool{}
+	if baseSha != "" {
+		base, err := repo.ResolveRevision(plumbing.Revision(baseSha))
+		if err != nil {
+			return nil, err
+		}
+		if excluded, err = gitReachableCommits(repo, *base); err != nil {
+			return nil, err
+		}
+	}
+
+	logIter, err := repo.Log(&git.LogOptions{
+		From:       *head,
+		Order:      git.LogOrderCommitterTime,
+		PathFilter: pathFilter,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	commits := []*object.Commit{}
+	err = logIter.ForEach(func(c *object.Commit) error {
+		if !excluded[c.Hash] {
+			commits = append(commits, c)
+		}
+		return nil
+	})
+	return commits, err
+}
+
+// returns the set of commit hashes reachable from (and including) the specified commit.
+func gitReachableCommits(repo *git.Repository, from plumbing.Hash) (map[plumbing.Hash]bool, error) {
+	logIter, err := repo.Log(&git.LogOptions{From: from})
+	if err != nil {
+		return nil, err
+	}
+
+	reachable := map[plumbing.Hash]bool{}
+	err = logIter.ForEach(func(c *object.Commit) error {
+		reachable[c.Hash] = true
+		return nil
+	})
+	return reachable, err
+}
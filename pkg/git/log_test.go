@@ -0,0 +1,74 @@
+package git_test
+
+import (
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	bumprGit "github.com/packagrio/bumpr/pkg/git"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+// creates a commit with an empty change to a file in the repository.
+func commitFile(t *testing.T, repo *git.Repository, repoPath string, message string) plumbing.Hash {
+	workTree, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(path.Join(repoPath, "file.txt"), []byte(message), 0644))
+	_, err = workTree.Add("file.txt")
+	require.NoError(t, err)
+	hash, err := workTree.Commit(message, &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+	return hash
+}
+
+func TestGitCommitsBetween(t *testing.T) {
+	//setup
+	repoPath, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(repoPath)
+	repo, err := git.PlainInit(repoPath, false)
+	require.NoError(t, err)
+	commitFile(t, repo, repoPath, "initial commit")
+	base := commitFile(t, repo, repoPath, "feat: base")
+	commitFile(t, repo, repoPath, "fix: first")
+	head := commitFile(t, repo, repoPath, "feat: second")
+
+	//test
+	commits, err := bumprGit.GitCommitsBetween(repoPath, base.String(), head.String())
+	require.NoError(t, err)
+	allCommits, err := bumprGit.GitCommitsBetween(repoPath, "", "")
+	require.NoError(t, err)
+
+	//assert
+	require.Len(t, commits, 2, "should exclude commits reachable from base")
+	require.Equal(t, head, commits[0].Hash)
+	require.Len(t, allCommits, 4, "should return all commits when base is empty")
+}
+
+func TestGitPathCommitsBetween(t *testing.T) {
+	//setup
+	repoPath := t.TempDir()
+	repo, err := git.PlainInit(repoPath, false)
+	require.NoError(t, err)
+	base := commitFiles(t, repo, repoPath, "cli/VERSION", "sdk/VERSION")
+	commitFiles(t, repo, repoPath, "sdk/index.js")
+	cliCommit := commitFiles(t, repo, repoPath, "cli/main.go")
+	commitFiles(t, repo, repoPath, "README.md")
+
+	//test
+	cliCommits, err := bumprGit.GitPathCommitsBetween(path.Join(repoPath, "cli"), base.String(), "")
+	require.NoError(t, err)
+	allCommits, err := bumprGit.GitPathCommitsBetween(repoPath, base.String(), "")
+	require.NoError(t, err)
+
+	//assert
+	require.Len(t, cliCommits, 1, "should only return the commits changing the files of the subdirectory")
+	require.Equal(t, cliCommit, cliCommits[0].Hash)
+	require.Len(t, allCommits, 3, "should return all commits for the repository root")
+}
@@ -0,0 +1,97 @@
+package git
+
+import (
+	"fmt"
+	"github.com/Masterminds/semver"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"path"
+	"strings"
+)
+
+type SemverTag struct {
+	Name      string
+	Version   *semver.Version
+	CommitSha string
+}
+
+// GitFindLatestSemverTag returns the highest semver tag reachable from headSha (or HEAD if empty).
+// Only tags matching tagGlob (eg. `api/v*`, defaults to tagPrefix + `*`) are considered, and the tagPrefix is removed before
+// parsing the version. When tagPrefix is empty, the literal head of the glob (eg. `api/v`) is used as the prefix. Tags that
+// are not valid semver versions are ignored. Returns nil if no semver tag could be found.
+func GitFindLatestSemverTag(repoPath string, headSha string, tagPrefix string, tagGlob string) (*SemverTag, error) {
+	if tagGlob == "" {
+		tagGlob = tagPrefix + "*"
+	}
+	globPrefix := tagGlobPrefix(tagGlob)
+	if tagPrefix == "" {
+		tagPrefix = globPrefix
+	} else if !strings.HasPrefix(globPrefix, tagPrefix) && !strings.HasPrefix(tagPrefix, globPrefix) {
+		return nil, fmt.Errorf("the tag glob (%s) can't match tags with the prefix %s", tagGlob, tagPrefix)
+	}
+
+	repo, oerr := git.PlainOpenWithOptions(repoPath, &git.PlainOpenOptions{DetectDotGit: true})
+	if oerr != nil {
+		return nil, oerr
+	}
+
+	if headSha == "" {
+		headSha = "HEAD"
+	}
+	head, err := repo.ResolveRevision(plumbing.Revision(headSha))
+	if err != nil {
+		return nil, err
+	}
+	reachable, err := gitReachableCommits(repo, *head)
+	if err != nil {
+		return nil, err
+	}
+
+	tagIter, err := repo.Tags()
+	if err != nil {
+		return nil, err
+	}
+
+	var latest *SemverTag
+	err = tagIter.ForEach(func(ref *plumbing.Reference) error {
+		tagName := ref.Name().Short()
+		if matched, merr := path.Match(tagGlob, tagName); merr != nil {
+			return merr
+		} else if !matched || !strings.HasPrefix(tagName, tagPrefix) {
+			return nil
+		}
+		version, verr := semver.NewVersion(strings.TrimPrefix(tagName, tagPrefix))
+		if verr != nil {
+			return nil
+		}
+
+		commitHash := ref.Hash()
+		if tagObj, terr := repo.TagObject(ref.Hash()); terr == nil {
+			// annotated tag, find the tagged commit
+			commitHash = tagObj.Target
+		} else if terr != plumbing.ErrObjectNotFound {
+			return terr
+		}
+
+		if !reachable[commitHash] {
+			return nil
+		}
+		if latest == nil || version.GreaterThan(latest.Version) {
+			latest = &SemverTag{
+				Name:      tagName,
+				Version:   version,
+				CommitSha: commitHash.String(),
+			}
+		}
+		return nil
+	})
+	return latest, err
+}
+
+// tagGlobPrefix returns the literal head of a glob, before the first special character (eg. `api/v` for `api/v*`).
+func tagGlobPrefix(tagGlob string) string {
+	if ndx := strings.IndexAny(tagGlob, `*?[\`); ndx >= 0 {
+		return tagGlob[:ndx]
+	}
+	return tagGlob
+}
@@ -0,0 +1,115 @@
+package git_test
+
+import (
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	bumprGit "github.com/packagrio/bumpr/pkg/git"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestGitFindLatestSemverTag(t *testing.T) {
+	//setup
+	repoPath, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(repoPath)
+	repo, err := git.PlainInit(repoPath, false)
+	require.NoError(t, err)
+	first := commitFile(t, repo, repoPath, "initial commit")
+	_, err = repo.CreateTag("v1.0.0", first, nil)
+	require.NoError(t, err)
+	second := commitFile(t, repo, repoPath, "feat: second")
+	_, err = repo.CreateTag("v1.1.0", second, &git.CreateTagOptions{
+		Tagger:  &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		Message: "annotated",
+	})
+	require.NoError(t, err)
+	_, err = repo.CreateTag("not-semver", second, nil)
+	require.NoError(t, err)
+	commitFile(t, repo, repoPath, "fix: third")
+
+	//test
+	latestTag, err := bumprGit.GitFindLatestSemverTag(repoPath, "", "", "")
+	require.NoError(t, err)
+	olderTag, err := bumprGit.GitFindLatestSemverTag(repoPath, first.String(), "", "")
+	require.NoError(t, err)
+
+	//assert
+	require.NotNil(t, latestTag)
+	require.Equal(t, "v1.1.0", latestTag.Name)
+	require.Equal(t, second.String(), latestTag.CommitSha, "should resolve annotated tags to the tagged commit")
+	require.Equal(t, "v1.0.0", olderTag.Name, "should ignore tags that are not reachable")
+}
+
+func TestGitFindLatestSemverTag_NoTags(t *testing.T) {
+	//setup
+	repoPath, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(repoPath)
+	repo, err := git.PlainInit(repoPath, false)
+	require.NoError(t, err)
+	commitFile(t, repo, repoPath, "initial commit")
+
+	//test
+	latestTag, err := bumprGit.GitFindLatestSemverTag(repoPath, "", "", "")
+
+	//assert
+	require.NoError(t, err)
+	require.Nil(t, latestTag)
+}
+
+func TestGitFindLatestSemverTag_PrefixAndGlob(t *testing.T) {
+	//setup
+	repoPath, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(repoPath)
+	repo, err := git.PlainInit(repoPath, false)
+	require.NoError(t, err)
+	first := commitFile(t, repo, repoPath, "initial commit")
+	_, err = repo.CreateTag("api/v1.2.0", first, nil)
+	require.NoError(t, err)
+	_, err = repo.CreateTag("web/v2.0.0", first, nil)
+	require.NoError(t, err)
+	_, err = repo.CreateTag("v3.0.0", first, nil)
+	require.NoError(t, err)
+
+	//test
+	apiTag, err := bumprGit.GitFindLatestSemverTag(repoPath, "", "api/v", "")
+	require.NoError(t, err)
+	globTag, err := bumprGit.GitFindLatestSemverTag(repoPath, "", "", "v*")
+	require.NoError(t, err)
+	defaultTag, err := bumprGit.GitFindLatestSemverTag(repoPath, "", "", "")
+	require.NoError(t, err)
+
+	//assert
+	require.Equal(t, "api/v1.2.0", apiTag.Name)
+	require.Equal(t, "1.2.0", apiTag.Version.String(), "should strip the prefix from the version")
+	require.Equal(t, "v3.0.0", globTag.Name)
+	require.Equal(t, "v3.0.0", defaultTag.Name, "should ignore tags that are not valid semver")
+}
+
+func TestGitFindLatestSemverTag_GlobPrefix(t *testing.T) {
+	//setup
+	repoPath := t.TempDir()
+	repo, err := git.PlainInit(repoPath, false)
+	require.NoError(t, err)
+	first := commitFile(t, repo, repoPath, "initial commit")
+	_, err = repo.CreateTag("api/v1.2.0", first, nil)
+	require.NoError(t, err)
+	_, err = repo.CreateTag("v3.0.0", first, nil)
+	require.NoError(t, err)
+
+	//test
+	globTag, gerr := bumprGit.GitFindLatestSemverTag(repoPath, "", "", "api/v*")
+	_, merr := bumprGit.GitFindLatestSemverTag(repoPath, "", "web/v", "api/v*")
+
+	//assert
+	require.NoError(t, gerr)
+	require.NotNil(t, globTag)
+	require.Equal(t, "api/v1.2.0", globTag.Name)
+	require.Equal(t, "1.2.0", globTag.Version.String(), "should strip the literal head of the glob from the version")
+	require.Error(t, merr, "should reject a glob that can't match the prefix")
+}
@@ -0,0 +1,86 @@
+package git
+
+import (
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GitExportTree writes the files of a revision to destPath, read from the git object database (the worktree is not
+// modified). Only the files under repoPath (which may be a subdirectory of the repository) are exported, relative to
+// repoPath. Symlinks & submodules are skipped.
+func GitExportTree(repoPath string, revision string, destPath string) error {
+	repo, oerr := git.PlainOpenWithOptions(repoPath, &git.PlainOpenOptions{DetectDotGit: true})
+	if oerr != nil {
+		return oerr
+	}
+	commit, err := gitResolveCommit(repo, revision)
+	if err != nil {
+		return err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return err
+	}
+	pathPrefix, err := gitPathPrefix(repo, repoPath)
+	if err != nil {
+		return err
+	}
+	if pathPrefix != "" {
+		if tree, err = tree.Tree(strings.TrimSuffix(pathPrefix, "/")); err == object.ErrDirectoryNotFound {
+			// the directory does not exist in this revision
+			return os.MkdirAll(destPath, 0755)
+		} else if err != nil {
+			return err
+		}
+	}
+
+	return tree.Files().ForEach(func(file *object.File) error {
+		if file.Mode == filemode.Symlink {
+			return nil
+		}
+		filePath := filepath.Join(destPath, filepath.FromSlash(file.Name))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return err
+		}
+		content, err := file.Contents()
+		if err != nil {
+			return err
+		}
+		perm := os.FileMode(0644)
+		if file.Mode == filemode.Executable {
+			perm = 0755
+		}
+		return os.WriteFile(filePath, []byte(content), perm)
+	})
+}
+
+// GitPathExists returns true if repoPath (which may be a subdirectory of the repository) exists in the revision, eg.
+// false for a package added after the revision.
+func GitPathExists(repoPath string, revision string) (bool, error) {
+	repo, oerr := git.PlainOpenWithOptions(repoPath, &git.PlainOpenOptions{DetectDotGit: true})
+	if oerr != nil {
+		return false, oerr
+	}
+	commit, err := gitResolveCommit(repo, revision)
+	if err != nil {
+		return false, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return false, err
+	}
+	pathPrefix, err := gitPathPrefix(repo, repoPath)
+	if err != nil || pathPrefix == "" {
+		return err == nil, err
+	}
+	if _, err = tree.Tree(strings.TrimSuffix(pathPrefix, "/")); err == object.ErrDirectoryNotFound {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,55 @@
+package git_test
+
+import (
+	"github.com/go-git/go-git/v5"
+	bumprGit "github.com/packagrio/bumpr/pkg/git"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestGitExportTree(t *testing.T) {
+	//setup
+	repoPath := t.TempDir()
+	repo, err := git.PlainInit(repoPath, false)
+	require.NoError(t, err)
+	base := commitFiles(t, repo, repoPath, "sdk/VERSION", "sdk/lib/index.js", "cli/VERSION")
+	baseContent, err := os.ReadFile(path.Join(repoPath, "sdk", "VERSION"))
+	require.NoError(t, err)
+	commitFiles(t, repo, repoPath, "sdk/VERSION")
+	destPath := t.TempDir()
+
+	//test
+	err = bumprGit.GitExportTree(path.Join(repoPath, "sdk"), base.String(), destPath)
+
+	//assert
+	require.NoError(t, err)
+	content, err := os.ReadFile(path.Join(destPath, "VERSION"))
+	require.NoError(t, err)
+	require.Equal(t, string(baseContent), string(content), "should export the file content of the revision")
+	require.FileExists(t, path.Join(destPath, "lib", "index.js"))
+	require.NoFileExists(t, path.Join(destPath, "cli", "VERSION"), "should only export the files under repoPath")
+}
+
+func TestGitPathExists(t *testing.T) {
+	//setup
+	repoPath := t.TempDir()
+	repo, err := git.PlainInit(repoPath, false)
+	require.NoError(t, err)
+	base := commitFiles(t, repo, repoPath, "sdk/VERSION")
+	commitFiles(t, repo, repoPath, "cli/VERSION")
+
+	//test
+	sdkExists, serr := bumprGit.GitPathExists(path.Join(repoPath, "sdk"), base.String())
+	cliExists, cerr := bumprGit.GitPathExists(path.Join(repoPath, "cli"), base.String())
+	rootExists, rerr := bumprGit.GitPathExists(repoPath, base.String())
+
+	//assert
+	require.NoError(t, serr)
+	require.True(t, sdkExists)
+	require.NoError(t, cerr)
+	require.False(t, cliExists, "should not find a directory added after the revision")
+	require.NoError(t, rerr)
+	require.True(t, rootExists)
+}
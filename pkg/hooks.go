@@ -0,0 +1,56 @@
+package pkg
+
+import (
+	"github.com/packagrio/bumpr/pkg/config"
+	"github.com/packagrio/bumpr/pkg/hooks"
+	"log"
+	"regexp"
+	"strings"
+)
+
+var hookEnvNameRegex = regexp.MustCompile(`[^A-Z0-9]+`)
+
+// runHooks runs the hooks configured for the stage (`hooks.pre_bump` or `hooks.post_bump`) in the working directory. The
+// hook results are added to the run report, stops at the first failing hook. Hooks are not run if no package was bumped.
+func (p *Pipeline) runHooks(stage string, step string, result *Result) error {
+	if result.Skipped {
+		return nil
+	}
+	stageHooks, err := hooks.Parse(p.Config.Get(config.PACKAGR_HOOKS + "." + stage))
+	if err != nil {
+		return newPipelineError(PIPELINE_STEP_PARSE_REPO_CONFIG, err)
+	}
+
+	env := hookEnv(result)
+	for _, hook := range stageHooks {
+		log.Printf("Running %s hook: %s", stage, hook.Command)
+		hookResult, err := hooks.Run(stage, hook, p.Data.GitLocalPath, env)
+		if hookResult != nil {
+			result.Hooks = append(result.Hooks, hookResult)
+			log.Print(hookResult.Output)
+		}
+		if err != nil {
+			return newPipelineError(step, err)
+		}
+	}
+	return nil
+}
+
+// hookEnv returns the versions exported to the hooks. In monorepo mode, the versions of each package are also exported
+// with the package name as a suffix (eg. BUMPR_NEXT_VERSION_MY_SDK).
+func hookEnv(result *Result) []string {
+	env := []string{
+		"BUMPR_CURRENT_VERSION=" + result.PreviousVersion,
+		"BUMPR_NEXT_VERSION=" + result.NextVersion,
+		"BUMPR_BUMP_TYPE=" + result.BumpType,
+	}
+	for _, packageResult := range result.Packages {
+		suffix := strings.Trim(hookEnvNameRegex.ReplaceAllString(strings.ToUpper(packageResult.Name), "_"), "_")
+		env = append(env,
+			"BUMPR_CURRENT_VERSION_"+suffix+"="+packageResult.PreviousVersion,
+			"BUMPR_NEXT_VERSION_"+suffix+"="+packageResult.NextVersion,
+			"BUMPR_BUMP_TYPE_"+suffix+"="+packageResult.BumpType,
+		)
+	}
+	return env
+}
@@ -0,0 +1,136 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const STAGE_PRE_BUMP = "pre_bump"
+const STAGE_POST_BUMP = "post_bump"
+
+// DefaultTimeout is used for hooks that do not specify a timeout.
+const DefaultTimeout = 10 * time.Minute
+
+// Hook is a shell command run before (pre_bump) or after (post_bump) the version files are written.
+type Hook struct {
+	Command string
+	Timeout time.Duration
+}
+
+// Result is the outcome of a hook, included in the run report.
+type Result struct {
+	Stage    string        `json:"stage"`
+	Command  string        `json:"command"`
+	Output   string        `json:"output"`
+	ExitCode int           `json:"exit_code"`
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// Error is returned when a hook fails (non-zero exit code, or timeout).
+type Error struct {
+	Result *Result
+	Err    error
+}
+
+func (e *Error) Error() string {
+	message := fmt.Sprintf("%s hook `%s` failed: %s", e.Result.Stage, e.Result.Command, e.Err)
+	if output := strings.TrimSpace(e.Result.Output); output != "" {
+		message += "\n" + output
+	}
+	return message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Parse the hooks configured for a stage (a list). Each hook is either a command string, or a map with a `command` and
+// an optional `timeout` (eg. `30s`).
+func Parse(rawHooks interface{}) ([]Hook, error) {
+	parsed := []Hook{}
+	if rawHooks == nil {
+		return parsed, nil
+	}
+	hooksValue := reflect.ValueOf(rawHooks)
+	if hooksValue.Kind() != reflect.Slice {
+		return nil, errors.New("hooks must be a list of commands")
+	}
+	for ndx := 0; ndx < hooksValue.Len(); ndx++ {
+		rawHook := hooksValue.Index(ndx).Interface()
+		hook := Hook{Timeout: DefaultTimeout}
+		switch value := rawHook.(type) {
+		case string:
+			hook.Command = value
+		case map[string]interface{}:
+			hook.Command, _ = value["command"].(string)
+			if rawTimeout, ok := value["timeout"]; ok {
+				timeout, err := time.ParseDuration(fmt.Sprint(rawTimeout))
+				if err != nil {
+					return nil, fmt.Errorf("invalid timeout for hook `%s`: %s", hook.Command, err)
+				}
+				hook.Timeout = timeout
+			}
+		case map[interface{}]interface{}:
+			converted := map[string]interface{}{}
+			for key, item := range value {
+				converted[fmt.Sprint(key)] = item
+			}
+			hooks, err := Parse([]map[string]interface{}{converted})
+			if err != nil {
+				return nil, err
+			}
+			hook = hooks[0]
+		}
+		if strings.TrimSpace(hook.Command) == "" {
+			return nil, errors.New("every hook requires a command")
+		}
+		parsed = append(parsed, hook)
+	}
+	return parsed, nil
+}
+
+// Run the hook with `sh -c` in the working directory, with the additional environment variables (KEY=value). The
+// combined stdout & stderr is captured in the result.
+func Run(stage string, hook Hook, workingDir string, env []string) (*Result, error) {
+	result := &Result{Stage: stage, Command: hook.Command}
+
+	// the output is captured in a file (rather than a pipe), so that processes started by the hook that outlive it (and
+	// keep the output open) can't block the hook after a timeout.
+	outputFile, err := os.CreateTemp("", "bumpr-hook-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(outputFile.Name())
+	defer outputFile.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), hook.Timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", hook.Command)
+	cmd.Dir = workingDir
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout = outputFile
+	cmd.Stderr = outputFile
+
+	start := time.Now()
+	runErr := cmd.Run()
+	result.Duration = time.Since(start)
+	if output, err := os.ReadFile(outputFile.Name()); err == nil {
+		result.Output = string(output)
+	}
+	if cmd.ProcessState != nil {
+		result.ExitCode = cmd.ProcessState.ExitCode()
+	}
+
+	if ctx.Err() == context.DeadlineExceeded {
+		return result, &Error{Result: result, Err: fmt.Errorf("timed out after %s", hook.Timeout)}
+	} else if runErr != nil {
+		return result, &Error{Result: result, Err: runErr}
+	}
+	return result, nil
+}
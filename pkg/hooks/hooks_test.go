@@ -0,0 +1,78 @@
+package hooks_test
+
+import (
+	stderrors "errors"
+	"github.com/packagrio/bumpr/pkg/hooks"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	//test
+	parsed, err := hooks.Parse([]interface{}{
+		"go generate ./...",
+		map[string]interface{}{"command": "make client", "timeout": "30s"},
+		map[interface{}]interface{}{"command": "make docs"},
+	})
+	require.NoError(t, err)
+
+	//assert
+	require.Equal(t, []hooks.Hook{
+		{Command: "go generate ./...", Timeout: hooks.DefaultTimeout},
+		{Command: "make client", Timeout: 30 * time.Second},
+		{Command: "make docs", Timeout: hooks.DefaultTimeout},
+	}, parsed)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, rawHooks := range []interface{}{
+		"make client",
+		[]interface{}{map[string]interface{}{"timeout": "30s"}},
+		[]interface{}{map[string]interface{}{"command": "make client", "timeout": "soon"}},
+	} {
+		//test
+		_, err := hooks.Parse(rawHooks)
+
+		//assert
+		require.Error(t, err, rawHooks)
+	}
+}
+
+func TestRun(t *testing.T) {
+	//setup
+	workingDir := t.TempDir()
+
+	//test
+	result, err := hooks.Run(hooks.STAGE_PRE_BUMP, hooks.Hook{Command: "echo $BUMPR_NEXT_VERSION; pwd >&2", Timeout: time.Minute}, workingDir, []string{"BUMPR_NEXT_VERSION=1.3.0"})
+	require.NoError(t, err)
+
+	//assert
+	require.Equal(t, hooks.STAGE_PRE_BUMP, result.Stage)
+	require.Equal(t, 0, result.ExitCode)
+	require.Contains(t, result.Output, "1.3.0\n")
+	require.Contains(t, result.Output, workingDir, "should run in the working directory, and capture stderr")
+}
+
+func TestRun_Failure(t *testing.T) {
+	//test
+	result, err := hooks.Run(hooks.STAGE_POST_BUMP, hooks.Hook{Command: "echo broken; exit 3", Timeout: time.Minute}, t.TempDir(), nil)
+
+	//assert
+	var hookErr *hooks.Error
+	require.True(t, stderrors.As(err, &hookErr))
+	require.Equal(t, 3, result.ExitCode)
+	require.Contains(t, err.Error(), "post_bump hook `echo broken; exit 3` failed")
+	require.Contains(t, err.Error(), "broken", "should include the hook output")
+}
+
+func TestRun_Timeout(t *testing.T) {
+	//test
+	start := time.Now()
+	_, err := hooks.Run(hooks.STAGE_PRE_BUMP, hooks.Hook{Command: "sleep 5 & sleep 10", Timeout: 100 * time.Millisecond}, t.TempDir(), nil)
+
+	//assert
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "timed out after 100ms")
+	require.Less(t, time.Since(start), 3*time.Second, "should not wait for processes started by the hook")
+}
@@ -0,0 +1,308 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/packagrio/bumpr/pkg/changeset"
+	"github.com/packagrio/bumpr/pkg/config"
+	"github.com/packagrio/bumpr/pkg/conventional"
+	"github.com/packagrio/bumpr/pkg/engine"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// initVersionFileEngines are the engines that can update an additional version file, in the order they are tried, with
+// the file names each engine can read.
+var initVersionFileEngines = []struct {
+	engineType string
+	pattern    string
+}{
+	{engine.PACKAGR_ENGINE_TYPE_GOLANG, "*.go"},
+	{engine.PACKAGR_ENGINE_TYPE_NODE, "package.json"},
+	{engine.PACKAGR_ENGINE_TYPE_CHEF, "metadata.rb"},
+	{engine.PACKAGR_ENGINE_TYPE_RUBY, "*.rb"},
+	{engine.PACKAGR_ENGINE_TYPE_RUST, "Cargo.toml"},
+	{engine.PACKAGR_ENGINE_TYPE_MAVEN, "pom.xml"},
+	{engine.PACKAGR_ENGINE_TYPE_GRADLE, "gradle.properties"},
+	{engine.PACKAGR_ENGINE_TYPE_GRADLE, "*.gradle"},
+	{engine.PACKAGR_ENGINE_TYPE_GRADLE, "*.gradle.kts"},
+	{engine.PACKAGR_ENGINE_TYPE_DOTNET, "*.csproj"},
+	{engine.PACKAGR_ENGINE_TYPE_DOTNET, "*.fsproj"},
+	{engine.PACKAGR_ENGINE_TYPE_DOTNET, "*.vbproj"},
+	{engine.PACKAGR_ENGINE_TYPE_DOTNET, "Directory.Build.props"},
+	{engine.PACKAGR_ENGINE_TYPE_HELM, "Chart.yaml"},
+	// a plain text file that only contains the version
+	{engine.PACKAGR_ENGINE_TYPE_PYTHON, "*"},
+}
+
+// initPrimaryVersionFiles are the version files updated by the engines that don't use `version_metadata_path` (or that
+// update other files as well), they are never proposed as additional version files of the same package.
+var initPrimaryVersionFiles = map[string][]string{
+	engine.PACKAGR_ENGINE_TYPE_NODE: {"package.json"},
+	engine.PACKAGR_ENGINE_TYPE_CHEF: {"metadata.rb"},
+	engine.PACKAGR_ENGINE_TYPE_RUBY: {"lib/*/version.rb"},
+	// the version of the project file may be set by the Directory.Build.props file it imports
+	engine.PACKAGR_ENGINE_TYPE_DOTNET: {"Directory.Build.props"},
+}
+
+// directories that are not searched for version files, hidden directories (eg. `.git`) are skipped as well.
+var initSkippedDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// files that are not searched for version files, the engines update the lock files next to each package.json (node) or
+// Cargo.toml (rust).
+var initSkippedFiles = map[string]bool{
+	"package-lock.json":   true,
+	"npm-shrinkwrap.json": true,
+	"Cargo.lock":          true,
+}
+
+// files larger than this are not searched for the version
+const initMaxFileSize = 1024 * 1024
+
+// Init inspects the repository in workingDir and proposes the settings of a new packagr.yml file (`bumpr init`): the
+// package type (detected when `package_type` is `auto`), the version file, and the other files that contain the current
+// version. The existing repo config file is ignored, and no files are modified.
+func (p *Pipeline) Init(workingDir string, configData config.Interface) (*InitResult, error) {
+	p.init(workingDir, configData)
+	result := &InitResult{
+		ConfigPath:        p.Config.GetString(config.PACKAGR_ENGINE_REPO_CONFIG_PATH),
+		PackageType:       p.Config.GetString(config.PACKAGR_PACKAGE_TYPE),
+		PackageTypeReason: "configured",
+		VersionFiles:      []*InitVersionFile{},
+	}
+	if result.PackageType == "auto" {
+		detection, err := engine.Detect(p.Data.GitLocalPath, p.Config)
+		if err != nil {
+			return nil, newPipelineError(PIPELINE_STEP_DETECT_PACKAGE_TYPE, err)
+		}
+		log.Println(detection.Explain())
+		result.PackageType = detection.EngineType
+		result.PackageTypeReason = detection.Reason
+		for _, candidate := range detection.Candidates[1:] {
+			result.Candidates = append(result.Candidates, candidate.EngineType)
+		}
+	}
+
+	readConfig, err := config.Clone(p.Config, map[string]interface{}{
+		config.PACKAGR_PACKAGE_TYPE:      result.PackageType,
+		config.PACKAGR_VERSION_BUMP_TYPE: conventional.BUMP_TYPE_NONE,
+	})
+	if err != nil {
+		return nil, newPipelineError(PIPELINE_STEP_PARSE_REPO_CONFIG, err)
+	}
+	readData, cleanup, err := readOnlyData(p.Data)
+	if err != nil {
+		return nil, newPipelineError(PIPELINE_STEP_CREATE_ENGINE, err)
+	}
+	defer cleanup()
+	readEngine, err := p.newEngine(readData, readConfig, changeset.New())
+	if err != nil {
+		return nil, err
+	}
+	// the default version file is set by the engine during Init, empty if the engine has none (eg. node)
+	result.VersionMetadataPath = readConfig.GetString(config.PACKAGR_VERSION_METADATA_PATH)
+
+	// the version is "bumped" to the current version, in a change set that is discarded.
+	if err := readEngine.BumpVersion(); err != nil {
+		log.Printf("Could not read the current version, other version files will not be proposed: %s", err)
+		return result, nil
+	}
+	result.CurrentVersion = readEngine.GetCurrentVersion()
+	if result.CurrentVersion == "" {
+		return result, nil
+	}
+	if result.VersionFiles, err = p.findVersionFiles(result); err != nil {
+		return nil, newPipelineError(PIPELINE_STEP_INIT, err)
+	}
+	return result, nil
+}
+
+// findVersionFiles searches the repository for text files that contain the current version, and selects the engine that
+// can update each file. Files that no engine can update are returned without an engine, and are not selected.
+func (p *Pipeline) findVersionFiles(result *InitResult) ([]*InitVersionFile, error) {
+	version := strings.TrimPrefix(result.CurrentVersion, "v")
+	// the version must not be part of a longer version (eg. `1.2.3` in `1.2.30` or `0.1.2.3`)
+	versionPattern := regexp.MustCompile(`(?:^|[^0-9A-Za-z.])v?` + regexp.QuoteMeta(version) + `(?:$|[^0-9A-Za-z.]|\.(?:$|[^0-9]))`)
+
+	primaryFiles := append([]string{}, initPrimaryVersionFiles[result.PackageType]...)
+	if result.VersionMetadataPath != "" {
+		primaryFiles = append(primaryFiles, filepath.ToSlash(path.Clean(result.VersionMetadataPath)))
+	}
+
+	versionFiles := []*InitVersionFile{}
+	err := filepath.WalkDir(p.Data.GitLocalPath, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if filePath != p.Data.GitLocalPath && (strings.HasPrefix(entry.Name(), ".") || initSkippedDirs[entry.Name()]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		relPath := p.relativePath(filePath)
+		if !entry.Type().IsRegular() || initSkippedFiles[entry.Name()] || relPath == result.ConfigPath || matchesAny(primaryFiles, relPath) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil || info.Size() > initMaxFileSize {
+			return nil
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		// binary files
+		if bytes.IndexByte(content, 0) != -1 || !versionPattern.Match(content) {
+			return nil
+		}
+
+		versionFile := &InitVersionFile{Path: relPath}
+		versionFile.Engine, err = p.versionFileEngine(filePath, result.CurrentVersion)
+		if err != nil {
+			return err
+		}
+		versionFile.Selected = versionFile.Engine != ""
+		versionFiles = append(versionFiles, versionFile)
+		return nil
+	})
+	return versionFiles, err
+}
+
+// versionFileEngine returns the first engine (in initVersionFileEngines) that reads the current version from the file,
+// or an empty string if no engine does.
+func (p *Pipeline) versionFileEngine(filePath string, currentVersion string) (string, error) {
+	readData, cleanup, err := readOnlyData(p.Data)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+	for _, versionFileEngine := range initVersionFileEngines {
+		if matched, _ := path.Match(versionFileEngine.pattern, filepath.Base(filePath)); !matched {
+			continue
+		}
+		// engines set their defaults during Init
+		engineConfig, err := config.Clone(p.Config, nil)
+		if err != nil {
+			return "", err
+		}
+		engineData := *readData
+		fileEngine, err := engine.Create(versionFileEngine.engineType, &engineData, engineConfig, p.Scm)
+		if err != nil {
+			return "", err
+		}
+		versionReader, ok := fileEngine.(engine.VersionReader)
+		if !ok {
+			continue
+		}
+		if version, err := versionReader.GetVersion(filePath); err == nil && versionsEqual(version, currentVersion) {
+			return versionFileEngine.engineType, nil
+		}
+	}
+	return "", nil
+}
+
+// WriteRepoConfig writes the packagr.yml file proposed by Init to the working directory. Fails if the file already
+// exists, unless force is enabled.
+func WriteRepoConfig(workingDir string, result *InitResult, force bool) error {
+	configPath := path.Join(workingDir, result.ConfigPath)
+	if _, err := os.Stat(configPath); err == nil && !force {
+		return newPipelineError(PIPELINE_STEP_WRITE_FILES, fmt.Errorf("%s already exists, use --force to overwrite it", result.ConfigPath))
+	}
+	if err := os.WriteFile(configPath, []byte(RenderRepoConfig(result)), 0644); err != nil {
+		return newPipelineError(PIPELINE_STEP_WRITE_FILES, err)
+	}
+	return nil
+}
+
+// RenderRepoConfig generates a commented packagr.yml file from the settings proposed by Init. Only the selected version
+// files are added to `addl_version_metadata_paths`, the others are listed in a comment.
+func RenderRepoConfig(result *InitResult) string {
+	var out strings.Builder
+	out.WriteString("# generated by `bumpr init`, see the README for every available setting.\n")
+
+	reason := result.PackageTypeReason
+	if len(result.Candidates) > 0 {
+		reason += fmt.Sprintf(", other candidates: %s", strings.Join(result.Candidates, ", "))
+	}
+	fmt.Fprintf(&out, "\n# the type of package (%s)\n", reason)
+	fmt.Fprintf(&out, "package_type: %s\n", yamlString(result.PackageType))
+
+	if result.VersionMetadataPath != "" {
+		out.WriteString("\n# the file that stores the version")
+		if result.CurrentVersion != "" {
+			fmt.Fprintf(&out, " (current version: %s)", result.CurrentVersion)
+		}
+		fmt.Fprintf(&out, "\nversion_metadata_path: %s\n", yamlString(result.VersionMetadataPath))
+	}
+
+	out.WriteString("\n# the part of the version to bump: major, minor, patch, or auto to determine it from the Conventional Commits\n")
+	out.WriteString("# version_bump_type: patch\n")
+
+	selectedFiles := map[string][]string{}
+	otherFiles := []*InitVersionFile{}
+	for _, versionFile := range result.VersionFiles {
+		if versionFile.Selected && versionFile.Engine != "" {
+			selectedFiles[versionFile.Engine] = append(selectedFiles[versionFile.Engine], versionFile.Path)
+		} else {
+			otherFiles = append(otherFiles, versionFile)
+		}
+	}
+	if len(selectedFiles) > 0 {
+		engineTypes := []string{}
+		for engineType := range selectedFiles {
+			engineTypes = append(engineTypes, engineType)
+		}
+		sort.Strings(engineTypes)
+
+		out.WriteString("\n# other files that contain the version, they are bumped to the same version (`bumpr verify` checks they match)\n")
+		out.WriteString("addl_version_metadata_paths:\n")
+		for _, engineType := range engineTypes {
+			fmt.Fprintf(&out, "  %s:\n", engineType)
+			for _, filePath := range selectedFiles[engineType] {
+				fmt.Fprintf(&out, "    - %s\n", yamlString(filePath))
+			}
+		}
+	}
+	if len(otherFiles) > 0 {
+		fmt.Fprintf(&out, "\n# these files also contain the current version (%s), but are not updated:\n", result.CurrentVersion)
+		for _, versionFile := range otherFiles {
+			if versionFile.Engine != "" {
+				fmt.Fprintf(&out, "#   - %s (%s)\n", versionFile.Path, versionFile.Engine)
+			} else {
+				fmt.Fprintf(&out, "#   - %s\n", versionFile.Path)
+			}
+		}
+	}
+	return out.String()
+}
+
+var yamlPlainPattern = regexp.MustCompile(`^[A-Za-z0-9_./-]+$`)
+
+// yamlString quotes the value, unless it can be written as a plain YAML scalar
+func yamlString(value string) string {
+	if yamlPlainPattern.MatchString(value) {
+		return value
+	}
+	return strconv.Quote(value)
+}
+
+// matchesAny returns true if the slash separated path matches any of the patterns.
+func matchesAny(patterns []string, filePath string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, filePath); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,303 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Masterminds/semver"
+	"github.com/packagrio/bumpr/pkg/changeset"
+	"github.com/packagrio/bumpr/pkg/config"
+	"github.com/packagrio/bumpr/pkg/conventional"
+	"github.com/packagrio/bumpr/pkg/git"
+	"github.com/packagrio/go-common/pipeline"
+	"log"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LoadPackages returns the packages configured for monorepo mode (the `packages` list), or an empty list when the
+// repository contains a single package.
+func (p *Pipeline) LoadPackages() ([]config.Package, error) {
+	packages := []config.Package{}
+	if !p.Config.IsSet(config.PACKAGR_PACKAGES) {
+		return packages, nil
+	}
+	if err := p.Config.UnmarshalKey(config.PACKAGR_PACKAGES, &packages); err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for _, packageConfig := range packages {
+		if packageConfig.Name == "" || packageConfig.Path == "" {
+			return nil, errors.New("every entry in `packages` requires a name and path")
+		}
+		if names[packageConfig.Name] {
+			return nil, fmt.Errorf("package name %s is used by multiple entries in `packages`", packageConfig.Name)
+		}
+		names[packageConfig.Name] = true
+
+		if relPath := filepath.Clean(packageConfig.Path); filepath.IsAbs(relPath) || relPath == ".." || strings.HasPrefix(relPath, "../") {
+			return nil, fmt.Errorf("package %s path (%s) must be relative to the repository root", packageConfig.Name, packageConfig.Path)
+		}
+	}
+	return packages, nil
+}
+
+// bumpPackages bumps every package in the `packages` list (monorepo mode). Each package is bumped with its own (isolated)
+// pipeline data & configuration.
+func (p *Pipeline) bumpPackages(packages []config.Package, changeSet *changeset.ChangeSet, result *Result) error {
+	changedPackages, err := p.changedPackages(packages)
+	if err != nil {
+		return newPipelineError(PIPELINE_STEP_DETECT_CHANGED_PACKAGES, err)
+	}
+
+	packagesData, packagesSettings, err := p.packagesSettings(packages)
+	if err != nil {
+		return err
+	}
+	skipReasons := map[string]string{}
+	for _, packageConfig := range packages {
+		if changedPackages != nil && !changedPackages[packageConfig.Name] {
+			skipReasons[packageConfig.Name] = "no package files changed"
+		}
+	}
+
+	// the package type is resolved when the engine is created (eg. `auto`), keep the configured value for the report
+	packageTypes := []string{}
+	for _, packageSettings := range packagesSettings {
+		packageTypes = append(packageTypes, packageSettings.GetString(config.PACKAGR_PACKAGE_TYPE))
+	}
+
+	lockstep := p.Config.GetBool(config.PACKAGR_PACKAGES_LOCKSTEP)
+	if lockstep {
+		lockstepVersion, bumpTypeReason, err := p.prepareLockstep(packages, packagesData, packagesSettings, skipReasons)
+		if err != nil {
+			return err
+		}
+		result.PreviousVersion = lockstepVersion
+		result.BumpTypeReason = bumpTypeReason
+	}
+
+	// packages are bumped after their dependencies, so that dependents can be bumped too.
+	graph := &dependencyGraph{dependencies: make([][]int, len(packages))}
+	for ndx := range packages {
+		graph.order = append(graph.order, ndx)
+	}
+	if p.Config.GetBool(config.PACKAGR_PACKAGES_BUMP_DEPENDENTS) {
+		if graph, err = p.buildDependencyGraph(packages, packagesData, packagesSettings); err != nil {
+			return err
+		}
+	}
+
+	result.Skipped = true
+	result.Packages = make([]*PackageResult, len(packages))
+	for _, ndx := range graph.order {
+		packageConfig := packages[ndx]
+
+		// the new version of the internal dependencies that were bumped, by dependency name
+		bumpedDependencies := map[string]string{}
+		bumpedDependencyPackages := []string{}
+		for _, dependencyNdx := range graph.dependencies[ndx] {
+			if dependencyResult := result.Packages[dependencyNdx]; !dependencyResult.Skipped {
+				bumpedDependencies[graph.dependencyNames[dependencyNdx]] = dependencyResult.NextVersion
+				bumpedDependencyPackages = append(bumpedDependencyPackages, dependencyResult.Name)
+			}
+		}
+		skipReason := skipReasons[packageConfig.Name]
+		bumpedForDependencies := false
+		if len(bumpedDependencies) > 0 && skipReason != "" {
+			log.Printf("Package %s would be skipped (%s), but its dependencies were bumped", packageConfig.Name, skipReason)
+			skipReason = ""
+			bumpedForDependencies = true
+		}
+
+		log.Printf("Bumping package %s (%s)", packageConfig.Name, packageConfig.Path)
+		packageResult, err := p.bumpPackage(packageConfig.Name, packagesData[ndx], packagesSettings[ndx], changeSet, skipReason, bumpedDependencyPackages)
+		if err != nil {
+			return err
+		}
+		if bumpedForDependencies {
+			packageResult.BumpTypeReason = fmt.Sprintf("dependencies were bumped (%s)", strings.Join(bumpedDependencyPackages, ", "))
+		}
+		if len(bumpedDependencies) > 0 {
+			if err := p.updateDependencies(packagesData[ndx], packagesSettings[ndx], changeSet, bumpedDependencies); err != nil {
+				return err
+			}
+			packageResult.UpdatedDependencies = bumpedDependencyPackages
+		}
+		packageResult.Name = packageConfig.Name
+		packageResult.Path = packageConfig.Path
+		packageResult.PackageType = packageTypes[ndx]
+		result.Packages[ndx] = packageResult
+		result.Skipped = result.Skipped && packageResult.Skipped
+
+		if lockstep {
+			// every package is bumped to the same version
+			result.NextVersion = packageResult.NextVersion
+			result.BumpType = packageResult.BumpType
+		}
+	}
+	return nil
+}
+
+// packagesSettings returns the (isolated) pipeline data & configuration of every package, the package settings override
+// the repository configuration.
+func (p *Pipeline) packagesSettings(packages []config.Package) ([]*pipeline.Data, []config.Interface, error) {
+	packagesData := []*pipeline.Data{}
+	packagesSettings := []config.Interface{}
+	for _, packageConfig := range packages {
+		packageData := *p.Data
+		packageData.GitLocalPath = path.Join(p.Data.GitLocalPath, packageConfig.Path)
+		packageData.ReleaseVersion = ""
+		packagesData = append(packagesData, &packageData)
+
+		overrides := packageConfig.Overrides()
+		// packages released independently are tagged `<name>/<git_tag_prefix><version>`, so by default only the tags of
+		// the package are used to find its latest version.
+		if !p.Config.GetBool(config.PACKAGR_PACKAGES_LOCKSTEP) && !p.Config.IsSet(config.PACKAGR_VERSION_TAG_PREFIX) && !p.Config.IsSet(config.PACKAGR_VERSION_TAG_GLOB) {
+			overrides[config.PACKAGR_VERSION_TAG_PREFIX] = fmt.Sprintf("%s/%s", packageConfig.Name, p.Config.GetString(config.PACKAGR_GIT_TAG_PREFIX))
+		}
+		packageSettings, err := config.Clone(p.Config, overrides)
+		if err != nil {
+			return nil, nil, newPipelineError(PIPELINE_STEP_PARSE_REPO_CONFIG, err)
+		}
+		// the development version is only set (& committed) after the release of a single package.
+		if packageSettings.GetBool(config.PACKAGR_MAVEN_NEXT_DEVELOPMENT_VERSION) {
+			return nil, nil, newPipelineError(PIPELINE_STEP_PARSE_REPO_CONFIG, fmt.Errorf("`%s` is not supported with `packages` (package %s)", config.PACKAGR_MAVEN_NEXT_DEVELOPMENT_VERSION, packageConfig.Name))
+		}
+		packagesSettings = append(packagesSettings, packageSettings)
+	}
+	return packagesData, packagesSettings, nil
+}
+
+// prepareLockstep configures the packages to be bumped together (lockstep mode). The current version of every package is
+// retrieved, and the highest version is used as the current version of all packages, so that they are bumped to the same
+// next version. Returns a *VersionMismatchError if the current versions differ, unless `packages_sync` is enabled. The
+// reason for the shared bump type is also returned.
+func (p *Pipeline) prepareLockstep(packages []config.Package, packagesData []*pipeline.Data, packagesSettings []config.Interface, skipReasons map[string]string) (string, string, error) {
+	// packages are released together, if any package changed, they are all bumped.
+	if len(skipReasons) < len(packages) {
+		for packageName := range skipReasons {
+			delete(skipReasons, packageName)
+		}
+	}
+
+	// the bump type is determined once, for all packages
+	bumpType := p.Config.GetString(config.PACKAGR_VERSION_BUMP_TYPE)
+	bumpTypeReason := fmt.Sprintf("%s: %s", config.PACKAGR_VERSION_BUMP_TYPE, bumpType)
+	if bumpType == "auto" && len(skipReasons) == 0 {
+		bumpTypeResult, err := p.determineBumpType(p.Data, p.Config)
+		if err != nil {
+			return "", "", newPipelineError(PIPELINE_STEP_DETERMINE_BUMP_TYPE, err)
+		}
+		log.Printf("bump type: %s (determined from %d commit(s))", bumpTypeResult.BumpType, len(bumpTypeResult.Commits))
+		bumpType = bumpTypeResult.BumpType
+		bumpTypeReason = fmt.Sprintf("%d commit(s) require a %s bump", len(bumpTypeResult.Commits), bumpType)
+		if bumpType == conventional.BUMP_TYPE_NONE {
+			for _, packageConfig := range packages {
+				skipReasons[packageConfig.Name] = "no commits require a version bump"
+			}
+		}
+	}
+
+	currentVersions := map[string]string{}
+	var lockstepVersion *semver.Version
+	for ndx, packageConfig := range packages {
+		currentVersion, err := p.currentVersion(packagesData[ndx], packagesSettings[ndx])
+		if err != nil {
+			return "", "", err
+		}
+		version, err := semver.NewVersion(currentVersion)
+		if err != nil {
+			return "", "", newPipelineError(PIPELINE_STEP_LOCKSTEP, fmt.Errorf("package %s has an invalid version (%s): %s", packageConfig.Name, currentVersion, err))
+		}
+		currentVersions[packageConfig.Name] = currentVersion
+		if lockstepVersion == nil || version.GreaterThan(lockstepVersion) {
+			lockstepVersion = version
+		}
+	}
+
+	for _, currentVersion := range currentVersions {
+		if version, _ := semver.NewVersion(currentVersion); version.Equal(lockstepVersion) {
+			continue
+		}
+		if !p.Config.GetBool(config.PACKAGR_PACKAGES_SYNC) {
+			return "", "", newPipelineError(PIPELINE_STEP_LOCKSTEP, &VersionMismatchError{Versions: currentVersions})
+		}
+		log.Printf("Packages have different current versions, realigning them to %s", lockstepVersion.Original())
+		break
+	}
+
+	for _, packageSettings := range packagesSettings {
+		packageSettings.Set(config.PACKAGR_VERSION_SOURCE, "manual")
+		packageSettings.Set(config.PACKAGR_VERSION_CURRENT, lockstepVersion.Original())
+		if len(skipReasons) == 0 {
+			packageSettings.Set(config.PACKAGR_VERSION_BUMP_TYPE, bumpType)
+		}
+	}
+	return lockstepVersion.Original(), bumpTypeReason, nil
+}
+
+// currentVersion returns the current version of a package, without modifying any files.
+func (p *Pipeline) currentVersion(packageData *pipeline.Data, packageConfig config.Interface) (string, error) {
+	readConfig, err := config.Clone(packageConfig, map[string]interface{}{
+		config.PACKAGR_VERSION_BUMP_TYPE: conventional.BUMP_TYPE_NONE,
+	})
+	if err != nil {
+		return "", newPipelineError(PIPELINE_STEP_PARSE_REPO_CONFIG, err)
+	}
+	readData, cleanup, err := readOnlyData(packageData)
+	if err != nil {
+		return "", newPipelineError(PIPELINE_STEP_CREATE_ENGINE, err)
+	}
+	defer cleanup()
+
+	// the version is "bumped" to the current version, in a change set that is discarded.
+	readEngine, err := p.createEngine(readData, readConfig, changeset.New())
+	if err != nil {
+		return "", err
+	}
+	if err := readEngine.BumpVersion(); err != nil {
+		return "", newPipelineError(PIPELINE_STEP_BUMP_VERSION, err)
+	}
+	return readData.ReleaseVersion, nil
+}
+
+// changedPackages returns the names of the packages with files changed between the base & head commits, or nil if every
+// package should be bumped (`packages_changed_only` is disabled, a shared path changed, or there is no base commit/tag).
+func (p *Pipeline) changedPackages(packages []config.Package) (map[string]bool, error) {
+	if !p.Config.GetBool(config.PACKAGR_PACKAGES_CHANGED_ONLY) {
+		return nil, nil
+	}
+
+	baseSha, headSha, err := p.commitRange(p.Data, p.Config)
+	if err != nil {
+		return nil, err
+	} else if baseSha == "" {
+		log.Println("No base commit or tag found, bumping all packages")
+		return nil, nil
+	}
+
+	changedFiles, err := git.GitChangedFiles(p.Data.GitLocalPath, baseSha, headSha)
+	if err != nil {
+		return nil, err
+	}
+
+	changedPackages := map[string]bool{}
+	for _, changedFile := range changedFiles {
+		for _, sharedGlob := range p.Config.GetStringSlice(config.PACKAGR_PACKAGES_SHARED_PATHS) {
+			if git.MatchPathGlob(sharedGlob, changedFile) {
+				log.Printf("Shared path changed (%s), bumping all packages", changedFile)
+				return nil, nil
+			}
+		}
+		for _, packageConfig := range packages {
+			for _, packageGlob := range packageConfig.PathGlobs() {
+				if git.MatchPathGlob(packageGlob, changedFile) {
+					changedPackages[packageConfig.Name] = true
+				}
+			}
+		}
+	}
+	return changedPackages, nil
+}
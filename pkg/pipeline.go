@@ -1,11 +1,18 @@
 package pkg
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/analogj/go-util/utils"
+	"github.com/packagrio/bumpr/pkg/changeset"
 	"github.com/packagrio/bumpr/pkg/config"
+	"github.com/packagrio/bumpr/pkg/conventional"
 	"github.com/packagrio/bumpr/pkg/engine"
+	"github.com/packagrio/bumpr/pkg/git"
+	"github.com/packagrio/bumpr/pkg/hooks"
 	"github.com/packagrio/go-common/pipeline"
 	"github.com/packagrio/go-common/scm"
 	"log"
@@ -13,6 +20,9 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 )
 
 type Pipeline struct {
@@ -22,80 +32,406 @@ type Pipeline struct {
 	Engine engine.Interface
 }
 
+// Start runs the pipeline in the current working directory, using the SCM specified in the configuration. The result
+// of the run is printed to stdout, as text or as a JSON report (`output: json`).
 func (p *Pipeline) Start(configData config.Interface) error {
-	// Initialize Pipeline.
-	p.Config = configData
-	p.Data = new(pipeline.Data)
-
 	//by default the current working directory is the local directory to execute in
-	cwdPath, _ := os.Getwd()
-	p.Data.GitLocalPath = cwdPath
-	p.Data.GitParentPath = filepath.Dir(cwdPath)
+	cwdPath, err := os.Getwd()
+	if err != nil {
+		return newPipelineError(PIPELINE_STEP_PARSE_REPO_CONFIG, err)
+	}
+	p.init(cwdPath, configData)
 
-	//Parse Repo config if present.
+	//Parse Repo config if present, the SCM can be specified in the repo config.
 	if err := p.ParseRepoConfig(); err != nil {
-		return err
+		return newPipelineError(PIPELINE_STEP_PARSE_REPO_CONFIG, err)
 	}
 
 	sourceScm, err := scm.Create(p.Config.GetString(config.PACKAGR_SCM), p.Data, p.Config, &http.Client{})
 	if err != nil {
-		fmt.Printf("FATAL: %+v\n", err)
-		os.Exit(1)
+		return newPipelineError(PIPELINE_STEP_CREATE_SCM, err)
 	}
 	p.Scm = sourceScm
 
-	payload, err := p.Scm.RetrievePayload()
+	outputMode := p.Config.GetString(config.PACKAGR_OUTPUT)
+	if outputMode != "text" && outputMode != "json" {
+		return newPipelineError(PIPELINE_STEP_PARSE_REPO_CONFIG, fmt.Errorf("unknown output mode %q, must be text or json", outputMode))
+	}
+
+	result, err := p.run()
 	if err != nil {
+		// the partial result of a failed run (eg. the output of a failing hook) is still written to the report
+		if reportPath := p.Config.GetString(config.PACKAGR_REPORT_PATH); reportPath != "" && result != nil {
+			if rerr := writeReport(reportPath, result); rerr != nil {
+				log.Printf("failed to write the run report: %s", rerr)
+			}
+		}
 		return err
 	}
+
+	if reportPath := p.Config.GetString(config.PACKAGR_REPORT_PATH); reportPath != "" {
+		if err := writeReport(reportPath, result); err != nil {
+			return newPipelineError(PIPELINE_STEP_WRITE_REPORT, err)
+		}
+	}
+
+	if outputMode == "json" {
+		return printReport(os.Stdout, result)
+	}
+	printResult(os.Stdout, result)
+	return nil
+}
+
+// Run bumps the version of the package in workingDir, and returns the result of the run. Unlike Start, Run does not
+// print anything to stdout, and the caller is responsible for creating the SCM. Failures are returned as a *PipelineError,
+// hook failures also return the partial result (including the output of the failing hook).
+func (p *Pipeline) Run(workingDir string, configData config.Interface, sourceScm scm.Interface) (*Result, error) {
+	p.init(workingDir, configData)
+	if err := p.ParseRepoConfig(); err != nil {
+		return nil, newPipelineError(PIPELINE_STEP_PARSE_REPO_CONFIG, err)
+	}
+	p.Scm = sourceScm
+	return p.run()
+}
+
+func (p *Pipeline) init(workingDir string, configData config.Interface) {
+	p.Config = configData
+	p.Data = new(pipeline.Data)
+	p.Data.GitLocalPath = workingDir
+	p.Data.GitParentPath = filepath.Dir(workingDir)
+}
+
+func (p *Pipeline) run() (*Result, error) {
+	result := &Result{
+		ChangedFiles: []string{},
+		Files:        []*FileResult{},
+		Outputs:      map[string]string{},
+		StartedAt:    time.Now(),
+	}
+	defer func() {
+		result.Duration = time.Since(result.StartedAt)
+	}()
+
+	payload, err := p.Scm.RetrievePayload()
+	if err != nil {
+		return nil, newPipelineError(PIPELINE_STEP_RETRIEVE_PAYLOAD, err)
+	}
 	p.Data.GitHeadInfo = payload.Head
 	p.Data.GitBaseInfo = payload.Base
 
-	bumpEngine, err := engine.Create(
-		p.Config.GetString(config.PACKAGR_PACKAGE_TYPE),
-		p.Data, p.Config, sourceScm)
+	packages, err := p.LoadPackages()
 	if err != nil {
-		fmt.Printf("FATAL: %+v\n", err)
-		os.Exit(1)
+		return nil, newPipelineError(PIPELINE_STEP_PARSE_REPO_CONFIG, err)
 	}
 
-	if err := bumpEngine.ValidateTools(); err != nil {
-		fmt.Printf("FATAL: %+v\n", err)
-		os.Exit(1)
+	//all file modifications are held in memory until every engine has staged its changes, they are then committed
+	//together (or discarded during a dry run), so that a failing engine never leaves the repo partially bumped.
+	changeSet := changeset.New()
+
+	if len(packages) == 0 {
+		packageResult, err := p.bumpPackage("", p.Data, p.Config, changeSet, "", nil)
+		if err != nil {
+			return nil, err
+		}
+		result.PackageType = packageResult.PackageType
+		result.Engine = packageResult.Engine
+		result.BumpType = packageResult.BumpType
+		result.BumpTypeReason = packageResult.BumpTypeReason
+		result.PreviousVersion = packageResult.PreviousVersion
+		result.NextVersion = packageResult.NextVersion
+		result.Skipped = packageResult.Skipped
+		if result.Skipped {
+			return result, nil
+		}
+	} else {
+		//monorepo mode, each package is bumped with its own (isolated) pipeline data & configuration
+		if err := p.bumpPackages(packages, changeSet, result); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, change := range changeSet.Changes() {
+		relPath := p.relativePath(change.Path)
+		result.ChangedFiles = append(result.ChangedFiles, relPath)
+		fileResult := &FileResult{Path: relPath, AfterSha256: sha256Hex(change.After)}
+		if change.Existed {
+			fileResult.BeforeSha256 = sha256Hex(change.Before)
+		}
+		result.Files = append(result.Files, fileResult)
+	}
+
+	if p.Config.GetBool(config.PACKAGR_DRY_RUN) {
+		result.DryRun = true
+		result.Diff = changeSet.UnifiedDiff(p.Data.GitLocalPath)
+		if len(packages) == 0 {
+			// the development version is previewed on top of the release changes
+			developmentChangeSet, developmentVersion, err := p.stageDevelopmentVersion(changeSet, result.NextVersion)
+			if err != nil {
+				return nil, err
+			} else if developmentVersion != "" {
+				result.DevelopmentVersion = developmentVersion
+				result.Diff += developmentChangeSet.UnifiedDiff(p.Data.GitLocalPath)
+			}
+		}
+		return result, nil
+	}
+
+	if err := p.checkGitRelease(result); err != nil {
+		return nil, err
+	}
+	// the partial result is returned with hook failures, so that the report includes the output of the failing hook.
+	if err := p.runHooks(hooks.STAGE_PRE_BUMP, PIPELINE_STEP_PRE_BUMP_HOOK, result); err != nil {
+		return result, err
+	}
+	// fails if a pre_bump hook modified a file that has staged changes, rather than overwriting the hook's changes.
+	if err := changeSet.Commit(); err != nil {
+		return result, newPipelineError(PIPELINE_STEP_WRITE_FILES, err)
+	}
+	if err := p.runHooks(hooks.STAGE_POST_BUMP, PIPELINE_STEP_POST_BUMP_HOOK, result); err != nil {
+		// the version changes are reverted, so that the bump can be retried once the hook is fixed.
+		if rerr := changeSet.Revert(); rerr != nil {
+			return result, newPipelineError(PIPELINE_STEP_POST_BUMP_HOOK, fmt.Errorf("%s (rollback failed: %s)", errors.Unwrap(err), rerr))
+		}
+		return result, err
+	}
+
+	if err := p.gitRelease(result); err != nil {
+		// the tags, the release commit & the version changes are reverted, so that the release can be retried.
+		return nil, p.rollbackRelease(result, changeSet, err)
+	}
+	if len(packages) == 0 {
+		if err := p.setDevelopmentVersion(result); err != nil {
+			return nil, err
+		}
+	}
+
+	//notify the SCM after the run is complete.
+	changedFiles, err := json.Marshal(result.ChangedFiles)
+	if err != nil {
+		return nil, newPipelineError(PIPELINE_STEP_SET_OUTPUT, err)
+	}
+	outputs := map[string]string{
+		"changed_files": string(changedFiles),
+	}
+	if len(packages) == 0 || p.Config.GetBool(config.PACKAGR_PACKAGES_LOCKSTEP) {
+		p.Data.ReleaseVersion = result.NextVersion
+		outputs["release_version"] = result.NextVersion
+		outputs["previous_version"] = result.PreviousVersion
+		outputs["bump_type"] = result.BumpType
+	}
+	if result.DevelopmentVersion != "" {
+		outputs["development_version"] = result.DevelopmentVersion
+	}
+	for _, packageResult := range result.Packages {
+		// skipped packages export their current version
+		outputs["release_version_"+packageResult.Name] = packageResult.NextVersion
+		outputs["previous_version_"+packageResult.Name] = packageResult.PreviousVersion
+		outputs["bump_type_"+packageResult.Name] = packageResult.BumpType
+	}
+	for _, outputName := range sortedKeys(outputs) {
+		if err := p.Scm.SetOutput(outputName, outputs[outputName]); err != nil {
+			return nil, newPipelineError(PIPELINE_STEP_SET_OUTPUT, err)
+		}
+		result.Outputs[outputName] = outputs[outputName]
+	}
+	return result, nil
+}
+
+// bumpPackage stages the version bump of a single package (rooted at packageData.GitLocalPath) in the change set, the
+// package name is empty in single package mode.
+// When a skipReason is specified the package is skipped, but its current version is still retrieved. When any of the
+// package dependencies were bumped (bumpedDependencies), the package is bumped by at least a patch version.
+func (p *Pipeline) bumpPackage(packageName string, packageData *pipeline.Data, packageConfig config.Interface, changeSet *changeset.ChangeSet, skipReason string, bumpedDependencies []string) (*PackageResult, error) {
+	packageResult := new(PackageResult)
+
+	packageResult.BumpTypeReason = fmt.Sprintf("%s: %s", config.PACKAGR_VERSION_BUMP_TYPE, packageConfig.GetString(config.PACKAGR_VERSION_BUMP_TYPE))
+	if skipReason != "" {
+		log.Printf("%s, skipping", skipReason)
+		packageResult.Skipped = true
+		packageResult.SkipReason = skipReason
+		packageResult.BumpTypeReason = skipReason
+	} else if packageConfig.GetString(config.PACKAGR_VERSION_BUMP_TYPE) == "auto" {
+		bumpTypeResult, err := p.determineBumpType(packageData, packageConfig)
+		if err != nil {
+			return nil, newPipelineError(PIPELINE_STEP_DETERMINE_BUMP_TYPE, err)
+		}
+		log.Printf("bump type: %s (determined from %d commit(s))", bumpTypeResult.BumpType, len(bumpTypeResult.Commits))
+		for _, commit := range bumpTypeResult.Commits {
+			log.Printf("  - %.8s %s", commit.Sha, commit.Subject)
+		}
+		packageResult.BumpTypeReason = fmt.Sprintf("%d commit(s) require a %s bump", len(bumpTypeResult.Commits), bumpTypeResult.BumpType)
+		if bumpTypeResult.BumpType == conventional.BUMP_TYPE_NONE && len(bumpedDependencies) == 0 {
+			packageResult.Skipped = true
+			packageResult.SkipReason = "no commits require a version bump"
+			packageResult.BumpTypeReason = packageResult.SkipReason
+		}
+		packageConfig.Set(config.PACKAGR_VERSION_BUMP_TYPE, bumpTypeResult.BumpType)
+	}
+	if len(bumpedDependencies) > 0 && packageConfig.GetString(config.PACKAGR_VERSION_BUMP_TYPE) == conventional.BUMP_TYPE_NONE {
+		log.Printf("dependencies were bumped (%s), bump type: patch", strings.Join(bumpedDependencies, ", "))
+		packageConfig.Set(config.PACKAGR_VERSION_BUMP_TYPE, conventional.BUMP_TYPE_PATCH)
+		packageResult.BumpTypeReason = fmt.Sprintf("dependencies were bumped (%s)", strings.Join(bumpedDependencies, ", "))
+	}
+
+	engineChangeSet := changeSet
+	if packageResult.Skipped {
+		// the engine still retrieves the current version, but keeps it unchanged. Any file changes are discarded.
+		packageConfig.Set(config.PACKAGR_VERSION_BUMP_TYPE, conventional.BUMP_TYPE_NONE)
+		engineChangeSet = changeset.New()
+	}
+	packageResult.BumpType = packageConfig.GetString(config.PACKAGR_VERSION_BUMP_TYPE)
+
+	packageResult.PackageType = packageConfig.GetString(config.PACKAGR_PACKAGE_TYPE)
+	bumpEngine, err := p.createEngine(packageData, packageConfig, engineChangeSet)
+	if err != nil {
+		return nil, err
+	}
+	packageResult.Engine = packageConfig.GetString(config.PACKAGR_PACKAGE_TYPE)
+	if packageData == p.Data {
+		p.Engine = bumpEngine
 	}
 
 	if err := bumpEngine.BumpVersion(); err != nil {
-		fmt.Printf("FATAL: %+v\n", err)
-		os.Exit(1)
+		return nil, newPipelineError(PIPELINE_STEP_BUMP_VERSION, err)
+	}
+	packageResult.PreviousVersion = previousVersion(bumpEngine, packageConfig)
+	packageResult.NextVersion = packageData.ReleaseVersion
+	if packageResult.Skipped {
+		return packageResult, nil
+	}
+
+	if packageConfig.GetBool(config.PACKAGR_CHANGELOG) {
+		if err := p.writeChangelog(packageName, packageData, packageConfig, changeSet, packageData.ReleaseVersion); err != nil {
+			return nil, newPipelineError(PIPELINE_STEP_CHANGELOG, err)
+		}
 	}
 
 	//find addl version files to bump
-	for engineType, paths := range p.Config.GetStringMap(config.PACKAGR_ADDL_VERSION_METADATA_PATHS) {
+	for engineType, paths := range packageConfig.GetStringMap(config.PACKAGR_ADDL_VERSION_METADATA_PATHS) {
 		//process additional paths, setting version to bumped version
 
-		addlMetadataEngine, err := engine.Create(engineType, p.Data, p.Config, sourceScm)
+		addlMetadataEngine, err := engine.Create(engineType, packageData, packageConfig, p.Scm)
 		if err != nil {
-			fmt.Printf("FATAL: %+v\n", err)
-			os.Exit(1)
+			return nil, newPipelineError(PIPELINE_STEP_CREATE_ENGINE, err)
 		}
+		addlMetadataEngine.SetChangeSet(changeSet)
 		for _, metadataPath := range paths.([]interface{}) {
 			metadataPathStr := metadataPath.(string)
-			err = addlMetadataEngine.SetVersion(path.Join(p.Data.GitLocalPath, metadataPathStr), p.Data.ReleaseVersion)
+			err = addlMetadataEngine.SetVersion(path.Join(packageData.GitLocalPath, metadataPathStr), packageData.ReleaseVersion)
 			if err != nil {
-				fmt.Printf("FATAL: %+v\n", err)
-				os.Exit(1)
+				return nil, newPipelineError(PIPELINE_STEP_SET_VERSION, err)
 			}
 		}
 
 	}
+	return packageResult, nil
+}
 
-	//notify the SCM after the run is complete.
-	if err := p.Scm.SetOutput("release_version", p.Data.ReleaseVersion); err != nil {
-		fmt.Printf("FATAL: %+v\n", err)
-		os.Exit(1)
+// createEngine creates the engine for the package (detecting the package type if required), and validates its tools.
+func (p *Pipeline) createEngine(packageData *pipeline.Data, packageConfig config.Interface, changeSet *changeset.ChangeSet) (engine.Interface, error) {
+	bumpEngine, err := p.newEngine(packageData, packageConfig, changeSet)
+	if err != nil {
+		return nil, err
 	}
-	fmt.Printf("version bumped to %s", p.Data.ReleaseVersion)
-	return nil
+	if err := bumpEngine.ValidateTools(); err != nil {
+		return nil, newPipelineError(PIPELINE_STEP_VALIDATE_TOOLS, err)
+	}
+	return bumpEngine, nil
+}
+
+// newEngine creates the engine for the package (detecting the package type if required), without validating its tools.
+func (p *Pipeline) newEngine(packageData *pipeline.Data, packageConfig config.Interface, changeSet *changeset.ChangeSet) (engine.Interface, error) {
+	if packageConfig.GetString(config.PACKAGR_PACKAGE_TYPE) == "auto" {
+		detection, err := engine.Detect(packageData.GitLocalPath, packageConfig)
+		if err != nil {
+			return nil, newPipelineError(PIPELINE_STEP_DETECT_PACKAGE_TYPE, err)
+		}
+		log.Println(detection.Explain())
+		packageConfig.Set(config.PACKAGR_PACKAGE_TYPE, detection.EngineType)
+	}
+
+	bumpEngine, err := engine.Create(packageConfig.GetString(config.PACKAGR_PACKAGE_TYPE), packageData, packageConfig, p.Scm)
+	if err != nil {
+		return nil, newPipelineError(PIPELINE_STEP_CREATE_ENGINE, err)
+	}
+	bumpEngine.SetChangeSet(changeSet)
+	return bumpEngine, nil
+}
+
+// DetermineBumpType classifies the Conventional Commits between the base and head commits (or since the latest semver
+// tag when there is no base commit) and returns the highest bump type required.
+func (p *Pipeline) DetermineBumpType() (*conventional.Result, error) {
+	return p.determineBumpType(p.Data, p.Config)
+}
+
+func (p *Pipeline) determineBumpType(packageData *pipeline.Data, packageConfig config.Interface) (*conventional.Result, error) {
+	baseSha, headSha, err := p.commitRange(packageData, packageConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	gitCommits, err := git.GitCommitsBetween(packageData.GitLocalPath, baseSha, headSha)
+	if err != nil {
+		return nil, err
+	}
+
+	classifier, err := conventional.NewClassifier(packageConfig.GetStringMapString(config.PACKAGR_VERSION_BUMP_RULES))
+	if err != nil {
+		return nil, err
+	}
+
+	commits := []*conventional.Commit{}
+	for _, gitCommit := range gitCommits {
+		commits = append(commits, conventional.Parse(gitCommit.Hash.String(), gitCommit.Message))
+	}
+	return classifier.ClassifyAll(commits), nil
+}
+
+// commitRange returns the base & head commits of the change. When there is no base commit (ie. not a pull request), the
+// latest semver tag is used instead. The base is empty if neither can be found.
+func (p *Pipeline) commitRange(packageData *pipeline.Data, packageConfig config.Interface) (string, string, error) {
+	var headSha, baseSha string
+	if packageData.GitHeadInfo != nil {
+		headSha = packageData.GitHeadInfo.Sha
+	}
+	if packageData.GitBaseInfo != nil {
+		baseSha = packageData.GitBaseInfo.Sha
+	} else {
+		latestTag, err := git.GitFindLatestSemverTag(packageData.GitLocalPath, headSha,
+			packageConfig.GetString(config.PACKAGR_VERSION_TAG_PREFIX),
+			packageConfig.GetString(config.PACKAGR_VERSION_TAG_GLOB))
+		if err != nil {
+			return "", "", err
+		}
+		if latestTag != nil {
+			log.Printf("Using commits since latest tag (%s)", latestTag.Name)
+			baseSha = latestTag.CommitSha
+		}
+	}
+	return baseSha, headSha, nil
+}
+
+func sha256Hex(content []byte) string {
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := []string{}
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// previousVersion returns the version that was bumped: the latest git tag when the `version_source` is `tag`, otherwise
+// the version read from the version file.
+func previousVersion(bumpEngine engine.Interface, packageConfig config.Interface) string {
+	if resolver, ok := bumpEngine.(engine.CurrentVersionResolver); ok && packageConfig.GetString(config.PACKAGR_VERSION_SOURCE) == engine.PACKAGR_ENGINE_TYPE_TAG {
+		return resolver.ResolvedCurrentVersion()
+	}
+	return bumpEngine.GetCurrentVersion()
 }
 
 func (p *Pipeline) ParseRepoConfig() error {
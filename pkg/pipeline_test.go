@@ -0,0 +1,1379 @@
+package pkg_test
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/golang/mock/gomock"
+	"github.com/packagrio/bumpr/pkg"
+	"github.com/packagrio/bumpr/pkg/config"
+	"github.com/packagrio/go-common/pipeline"
+	"github.com/packagrio/go-common/scm/mock"
+	"github.com/packagrio/go-common/scm/models"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path"
+	"sort"
+	"testing"
+	"time"
+)
+
+func setupPipelineTest(t *testing.T) (string, config.Interface, *mock_scm.MockInterface) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	workingDir := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(workingDir, "VERSION"), []byte(`version := "1.2.3"`), 0644))
+
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+	testConfig.Set(config.PACKAGR_PACKAGE_TYPE, "generic")
+	testConfig.Set(config.PACKAGR_VERSION_BUMP_TYPE, "minor")
+
+	mockScm := mock_scm.NewMockInterface(mockCtrl)
+	mockScm.EXPECT().RetrievePayload().Return(&models.Payload{}, nil)
+	return workingDir, testConfig, mockScm
+}
+
+func TestPipeline_Run(t *testing.T) {
+	//setup
+	workingDir, testConfig, mockScm := setupPipelineTest(t)
+	mockScm.EXPECT().SetOutput("bump_type", "minor").Return(nil)
+	mockScm.EXPECT().SetOutput("changed_files", `["VERSION"]`).Return(nil)
+	mockScm.EXPECT().SetOutput("previous_version", "1.2.3").Return(nil)
+	mockScm.EXPECT().SetOutput("release_version", "1.3.0").Return(nil)
+
+	//test
+	result, err := new(pkg.Pipeline).Run(workingDir, testConfig, mockScm)
+	require.NoError(t, err)
+
+	//assert
+	require.Equal(t, "generic", result.PackageType)
+	require.Equal(t, "generic", result.Engine)
+	require.Equal(t, "1.2.3", result.PreviousVersion)
+	require.Equal(t, "1.3.0", result.NextVersion)
+	require.Equal(t, "minor", result.BumpType)
+	require.Equal(t, "version_bump_type: minor", result.BumpTypeReason)
+	require.Equal(t, []string{"VERSION"}, result.ChangedFiles)
+	require.Equal(t, []*pkg.FileResult{{
+		Path:         "VERSION",
+		BeforeSha256: "7d0e72950cff1564aed3f0e342b51a6fe204dadc31cdbcfae1a75987be698d3e",
+		AfterSha256:  "df38e78236d54f972e8a9db35a2b783b42677c3742c28eaaa9972410d8678fb0",
+	}}, result.Files)
+	require.Equal(t, map[string]string{
+		"bump_type":        "minor",
+		"changed_files":    `["VERSION"]`,
+		"previous_version": "1.2.3",
+		"release_version":  "1.3.0",
+	}, result.Outputs)
+	require.False(t, result.DryRun)
+	require.False(t, result.StartedAt.IsZero())
+
+	content, err := os.ReadFile(path.Join(workingDir, "VERSION"))
+	require.NoError(t, err)
+	require.Equal(t, `version := "1.3.0"`, string(content))
+}
+
+func TestPipeline_Run_DryRun(t *testing.T) {
+	//setup
+	workingDir, testConfig, mockScm := setupPipelineTest(t)
+	testConfig.Set(config.PACKAGR_DRY_RUN, true)
+
+	//test
+	result, err := new(pkg.Pipeline).Run(workingDir, testConfig, mockScm)
+	require.NoError(t, err)
+
+	//assert
+	require.True(t, result.DryRun)
+	require.Equal(t, "1.3.0", result.NextVersion)
+	require.Equal(t, []string{"VERSION"}, result.ChangedFiles)
+	require.Empty(t, result.Outputs, "should not set outputs during a dry run")
+	require.Contains(t, result.Diff, `+version := "1.3.0"`)
+
+	content, err := os.ReadFile(path.Join(workingDir, "VERSION"))
+	require.NoError(t, err)
+	require.Equal(t, `version := "1.2.3"`, string(content), "should not modify files during a dry run")
+}
+
+func TestPipeline_Run_Report(t *testing.T) {
+	//setup
+	workingDir, testConfig, mockScm := setupPipelineTest(t)
+	testConfig.Set(config.PACKAGR_DRY_RUN, true)
+
+	//test
+	result, err := new(pkg.Pipeline).Run(workingDir, testConfig, mockScm)
+	require.NoError(t, err)
+	report, err := json.Marshal(result)
+	require.NoError(t, err)
+
+	//assert
+	var reportData map[string]interface{}
+	require.NoError(t, json.Unmarshal(report, &reportData))
+	require.Equal(t, "generic", reportData["package_type"])
+	require.Equal(t, "generic", reportData["engine"])
+	require.Equal(t, "1.2.3", reportData["previous_version"])
+	require.Equal(t, "1.3.0", reportData["next_version"])
+	require.Equal(t, "minor", reportData["bump_type"])
+	require.Equal(t, "version_bump_type: minor", reportData["bump_type_reason"])
+	require.Equal(t, []interface{}{"VERSION"}, reportData["changed_files"])
+	require.Len(t, reportData["files"], 1)
+	require.Contains(t, reportData["files"].([]interface{})[0], "before_sha256")
+	require.Contains(t, reportData, "started_at")
+	require.Contains(t, reportData, "duration_ns")
+}
+
+func TestPipeline_Run_InvalidPackageType(t *testing.T) {
+	//setup
+	workingDir, testConfig, mockScm := setupPipelineTest(t)
+	testConfig.Set(config.PACKAGR_PACKAGE_TYPE, "unknown")
+
+	//test
+	result, err := new(pkg.Pipeline).Run(workingDir, testConfig, mockScm)
+
+	//assert
+	require.Nil(t, result)
+	var pipelineErr *pkg.PipelineError
+	require.True(t, stderrors.As(err, &pipelineErr), "should return a PipelineError")
+	require.Equal(t, pkg.PIPELINE_STEP_CREATE_ENGINE, pipelineErr.Step)
+}
+
+func TestPipeline_Run_RetrievePayloadError(t *testing.T) {
+	//setup
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+	mockScm := mock_scm.NewMockInterface(mockCtrl)
+	payloadErr := stderrors.New("payload unavailable")
+	mockScm.EXPECT().RetrievePayload().Return(nil, payloadErr)
+
+	//test
+	_, rerr := new(pkg.Pipeline).Run(t.TempDir(), testConfig, mockScm)
+
+	//assert
+	var pipelineErr *pkg.PipelineError
+	require.True(t, stderrors.As(rerr, &pipelineErr))
+	require.Equal(t, pkg.PIPELINE_STEP_RETRIEVE_PAYLOAD, pipelineErr.Step)
+	require.ErrorIs(t, rerr, payloadErr, "should wrap the underlying error")
+}
+
+func TestPipeline_Run_AddlVersionMetadataPathFailure(t *testing.T) {
+	//setup
+	workingDir, testConfig, mockScm := setupPipelineTest(t)
+	require.NoError(t, os.WriteFile(path.Join(workingDir, "version.txt"), []byte(`version := "1.2.3"`), 0644))
+	testConfig.Set(config.PACKAGR_ADDL_VERSION_METADATA_PATHS, map[string]interface{}{
+		"generic": []interface{}{"version.txt"},
+		"node":    []interface{}{"missing"},
+	})
+
+	//test
+	_, err := new(pkg.Pipeline).Run(workingDir, testConfig, mockScm)
+
+	//assert
+	var pipelineErr *pkg.PipelineError
+	require.True(t, stderrors.As(err, &pipelineErr))
+	require.Equal(t, pkg.PIPELINE_STEP_SET_VERSION, pipelineErr.Step)
+	for _, fileName := range []string{"VERSION", "version.txt"} {
+		content, rerr := os.ReadFile(path.Join(workingDir, fileName))
+		require.NoError(t, rerr)
+		require.Equal(t, `version := "1.2.3"`, string(content), "should not modify any file when a bump fails")
+	}
+}
+
+func TestPipeline_Run_AutoPackageType(t *testing.T) {
+	//setup
+	workingDir, testConfig, mockScm := setupPipelineTest(t)
+	testConfig.Set(config.PACKAGR_PACKAGE_TYPE, "auto")
+	testConfig.Set(config.PACKAGR_DRY_RUN, true)
+
+	//test
+	result, err := new(pkg.Pipeline).Run(workingDir, testConfig, mockScm)
+	require.NoError(t, err)
+
+	//assert
+	require.Equal(t, "auto", result.PackageType, "should report the configured package type")
+	require.Equal(t, "generic", result.Engine)
+	require.Equal(t, "1.3.0", result.NextVersion)
+}
+
+func TestPipeline_Run_Packages(t *testing.T) {
+	//setup
+	workingDir, testConfig, mockScm := setupPipelineTest(t)
+	require.NoError(t, os.MkdirAll(path.Join(workingDir, "cli", "pkg", "version"), 0755))
+	require.NoError(t, os.WriteFile(path.Join(workingDir, "cli", "pkg", "version", "version.go"), []byte(`version := "0.4.1"`), 0644))
+	require.NoError(t, os.MkdirAll(path.Join(workingDir, "sdk"), 0755))
+	require.NoError(t, os.WriteFile(path.Join(workingDir, "sdk", "version.txt"), []byte(`version := "2.0.0"`), 0644))
+	require.NoError(t, os.WriteFile(path.Join(workingDir, "packagr.yml"), []byte(`
+packages:
+  - name: cli
+    path: cli
+    package_type: generic
+    version_metadata_path: pkg/version/version.go
+    version_bump_type: patch
+  - name: sdk
+    path: sdk
+    version_metadata_path: version.txt
+    version_bump_type: major
+`), 0644))
+	mockScm.EXPECT().SetOutput("bump_type_cli", "patch").Return(nil)
+	mockScm.EXPECT().SetOutput("bump_type_sdk", "major").Return(nil)
+	mockScm.EXPECT().SetOutput("changed_files", `["cli/pkg/version/version.go","sdk/version.txt"]`).Return(nil)
+	mockScm.EXPECT().SetOutput("previous_version_cli", "0.4.1").Return(nil)
+	mockScm.EXPECT().SetOutput("previous_version_sdk", "2.0.0").Return(nil)
+	mockScm.EXPECT().SetOutput("release_version_cli", "0.4.2").Return(nil)
+	mockScm.EXPECT().SetOutput("release_version_sdk", "3.0.0").Return(nil)
+
+	//test
+	result, err := new(pkg.Pipeline).Run(workingDir, testConfig, mockScm)
+	require.NoError(t, err)
+
+	//assert
+	require.Len(t, result.Packages, 2)
+	require.Equal(t, &pkg.PackageResult{Name: "cli", Path: "cli", PackageType: "generic", Engine: "generic", PreviousVersion: "0.4.1", NextVersion: "0.4.2", BumpType: "patch", BumpTypeReason: "version_bump_type: patch"}, result.Packages[0])
+	require.Equal(t, &pkg.PackageResult{Name: "sdk", Path: "sdk", PackageType: "generic", Engine: "generic", PreviousVersion: "2.0.0", NextVersion: "3.0.0", BumpType: "major", BumpTypeReason: "version_bump_type: major"}, result.Packages[1])
+	require.Equal(t, []string{"cli/pkg/version/version.go", "sdk/version.txt"}, result.ChangedFiles)
+	require.Equal(t, map[string]string{
+		"bump_type_cli":        "patch",
+		"bump_type_sdk":        "major",
+		"changed_files":        `["cli/pkg/version/version.go","sdk/version.txt"]`,
+		"previous_version_cli": "0.4.1",
+		"previous_version_sdk": "2.0.0",
+		"release_version_cli":  "0.4.2",
+		"release_version_sdk":  "3.0.0",
+	}, result.Outputs)
+
+	content, err := os.ReadFile(path.Join(workingDir, "VERSION"))
+	require.NoError(t, err)
+	require.Equal(t, `version := "1.2.3"`, string(content), "should not bump the repository root")
+}
+
+func TestPipeline_Run_Packages_Invalid(t *testing.T) {
+	//setup
+	workingDir, testConfig, mockScm := setupPipelineTest(t)
+	testConfig.Set(config.PACKAGR_PACKAGES, []map[string]interface{}{
+		{"name": "escape", "path": "../other"},
+	})
+
+	//test
+	_, err := new(pkg.Pipeline).Run(workingDir, testConfig, mockScm)
+
+	//assert
+	var pipelineErr *pkg.PipelineError
+	require.True(t, stderrors.As(err, &pipelineErr))
+	require.Equal(t, pkg.PIPELINE_STEP_PARSE_REPO_CONFIG, pipelineErr.Step)
+}
+
+// creates a monorepo with two generic packages (cli & sdk) and commits it, returns the commit sha
+func setupMonorepo(t *testing.T, workingDir string, testConfig config.Interface) (*git.Repository, string) {
+	for _, packageName := range []string{"cli", "sdk"} {
+		require.NoError(t, os.MkdirAll(path.Join(workingDir, packageName), 0755))
+		require.NoError(t, os.WriteFile(path.Join(workingDir, packageName, "VERSION"), []byte(`version := "1.0.0"`), 0644))
+	}
+	testConfig.Set(config.PACKAGR_PACKAGES, []map[string]interface{}{
+		{"name": "cli", "path": "cli"},
+		{"name": "sdk", "path": "sdk"},
+	})
+	testConfig.Set(config.PACKAGR_PACKAGES_CHANGED_ONLY, true)
+	testConfig.Set(config.PACKAGR_PACKAGES_SHARED_PATHS, []string{"shared/**"})
+
+	repo, err := git.PlainInit(workingDir, false)
+	require.NoError(t, err)
+	return repo, commitAll(t, repo)
+}
+
+func commitAll(t *testing.T, repo *git.Repository) string {
+	workTree, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = workTree.Add(".")
+	require.NoError(t, err)
+	hash, err := workTree.Commit("update", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+	return hash.String()
+}
+
+func TestPipeline_Run_PackagesChangedOnly(t *testing.T) {
+	//setup
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	workingDir := t.TempDir()
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+	repo, baseSha := setupMonorepo(t, workingDir, testConfig)
+	require.NoError(t, os.WriteFile(path.Join(workingDir, "sdk", "index.js"), []byte("module.exports = {}"), 0644))
+	headSha := commitAll(t, repo)
+
+	mockScm := mock_scm.NewMockInterface(mockCtrl)
+	mockScm.EXPECT().RetrievePayload().Return(&models.Payload{
+		Base: &pipeline.ScmCommitInfo{Sha: baseSha},
+		Head: &pipeline.ScmCommitInfo{Sha: headSha},
+	}, nil)
+	mockScm.EXPECT().SetOutput("release_version_cli", "1.0.0").Return(nil)
+	mockScm.EXPECT().SetOutput("release_version_sdk", "1.0.1").Return(nil)
+	mockScm.EXPECT().SetOutput(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	//test
+	result, err := new(pkg.Pipeline).Run(workingDir, testConfig, mockScm)
+	require.NoError(t, err)
+
+	//assert
+	require.True(t, result.Packages[0].Skipped, "should skip the unchanged package")
+	require.Equal(t, "1.0.0", result.Packages[0].NextVersion, "should keep the current version of the unchanged package")
+	require.False(t, result.Packages[1].Skipped)
+	require.Equal(t, "1.0.1", result.Packages[1].NextVersion)
+	require.Equal(t, []string{"sdk/VERSION"}, result.ChangedFiles)
+}
+
+func TestPipeline_Run_PackagesChangedOnly_SharedPath(t *testing.T) {
+	//setup
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	workingDir := t.TempDir()
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+	repo, baseSha := setupMonorepo(t, workingDir, testConfig)
+	require.NoError(t, os.MkdirAll(path.Join(workingDir, "shared", "proto"), 0755))
+	require.NoError(t, os.WriteFile(path.Join(workingDir, "shared", "proto", "api.proto"), []byte("syntax = \"proto3\";"), 0644))
+	headSha := commitAll(t, repo)
+
+	mockScm := mock_scm.NewMockInterface(mockCtrl)
+	mockScm.EXPECT().RetrievePayload().Return(&models.Payload{
+		Base: &pipeline.ScmCommitInfo{Sha: baseSha},
+		Head: &pipeline.ScmCommitInfo{Sha: headSha},
+	}, nil)
+	mockScm.EXPECT().SetOutput("release_version_cli", "1.0.1").Return(nil)
+	mockScm.EXPECT().SetOutput("release_version_sdk", "1.0.1").Return(nil)
+	mockScm.EXPECT().SetOutput(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	//test
+	result, err := new(pkg.Pipeline).Run(workingDir, testConfig, mockScm)
+	require.NoError(t, err)
+
+	//assert
+	require.Equal(t, []string{"cli/VERSION", "sdk/VERSION"}, result.ChangedFiles, "should bump every package when a shared path changes")
+}
+
+func setupLockstep(t *testing.T, workingDir string, testConfig config.Interface, versions map[string]string) {
+	packages := []map[string]interface{}{}
+	for _, packageName := range sortedNames(versions) {
+		require.NoError(t, os.MkdirAll(path.Join(workingDir, packageName), 0755))
+		require.NoError(t, os.WriteFile(path.Join(workingDir, packageName, "VERSION"), []byte(`version := "`+versions[packageName]+`"`), 0644))
+		packages = append(packages, map[string]interface{}{"name": packageName, "path": packageName, "package_type": "generic"})
+	}
+	testConfig.Set(config.PACKAGR_PACKAGES, packages)
+	testConfig.Set(config.PACKAGR_PACKAGES_LOCKSTEP, true)
+}
+
+func sortedNames(versions map[string]string) []string {
+	names := []string{}
+	for name := range versions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestPipeline_Run_Lockstep(t *testing.T) {
+	//setup
+	workingDir, testConfig, mockScm := setupPipelineTest(t)
+	setupLockstep(t, workingDir, testConfig, map[string]string{"cli": "1.4.0", "sdk": "1.4.0"})
+	mockScm.EXPECT().SetOutput("release_version", "1.5.0").Return(nil)
+	mockScm.EXPECT().SetOutput("release_version_cli", "1.5.0").Return(nil)
+	mockScm.EXPECT().SetOutput("release_version_sdk", "1.5.0").Return(nil)
+	mockScm.EXPECT().SetOutput(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	//test
+	result, err := new(pkg.Pipeline).Run(workingDir, testConfig, mockScm)
+	require.NoError(t, err)
+
+	//assert
+	require.Equal(t, "1.4.0", result.PreviousVersion)
+	require.Equal(t, "1.5.0", result.NextVersion)
+	require.Equal(t, []string{"cli/VERSION", "sdk/VERSION"}, result.ChangedFiles)
+	require.Equal(t, "1.4.0", result.Outputs["previous_version"])
+	require.Equal(t, "minor", result.Outputs["bump_type"])
+}
+
+func TestPipeline_Run_Lockstep_VersionMismatch(t *testing.T) {
+	//setup
+	workingDir, testConfig, mockScm := setupPipelineTest(t)
+	setupLockstep(t, workingDir, testConfig, map[string]string{"cli": "1.4.0", "sdk": "1.3.2"})
+
+	//test
+	_, err := new(pkg.Pipeline).Run(workingDir, testConfig, mockScm)
+
+	//assert
+	var mismatchErr *pkg.VersionMismatchError
+	require.True(t, stderrors.As(err, &mismatchErr), "should return a VersionMismatchError")
+	require.Equal(t, map[string]string{"cli": "1.4.0", "sdk": "1.3.2"}, mismatchErr.Versions)
+	require.Contains(t, err.Error(), "sdk: 1.3.2")
+
+	content, rerr := os.ReadFile(path.Join(workingDir, "cli", "VERSION"))
+	require.NoError(t, rerr)
+	require.Equal(t, `version := "1.4.0"`, string(content), "should not modify any package")
+}
+
+func TestPipeline_Run_Lockstep_Sync(t *testing.T) {
+	//setup
+	workingDir, testConfig, mockScm := setupPipelineTest(t)
+	setupLockstep(t, workingDir, testConfig, map[string]string{"cli": "1.4.0", "sdk": "1.3.2"})
+	testConfig.Set(config.PACKAGR_PACKAGES_SYNC, true)
+	testConfig.Set(config.PACKAGR_VERSION_BUMP_TYPE, "patch")
+	mockScm.EXPECT().SetOutput(gomock.Any(), "1.4.1").Return(nil).Times(3)
+	mockScm.EXPECT().SetOutput(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	//test
+	result, err := new(pkg.Pipeline).Run(workingDir, testConfig, mockScm)
+	require.NoError(t, err)
+
+	//assert
+	require.Equal(t, "1.4.1", result.NextVersion)
+	require.Equal(t, "1.3.2", result.Packages[1].PreviousVersion)
+	content, rerr := os.ReadFile(path.Join(workingDir, "sdk", "VERSION"))
+	require.NoError(t, rerr)
+	require.Equal(t, `version := "1.4.1"`, string(content), "should realign the package to the highest version")
+}
+
+// creates a monorepo with two go modules (cli & sdk), cli requires sdk. Returns the commit sha.
+func setupGolangMonorepo(t *testing.T, workingDir string, testConfig config.Interface, sdkRequires string) (*git.Repository, string) {
+	goMods := map[string]string{
+		"cli": "module github.com/acme/cli\n\ngo 1.18\n\nrequire github.com/acme/sdk v1.0.0\n",
+		"sdk": "module github.com/acme/sdk\n\ngo 1.18\n" + sdkRequires,
+	}
+	for _, packageName := range []string{"cli", "sdk"} {
+		require.NoError(t, os.MkdirAll(path.Join(workingDir, packageName, "pkg", "version"), 0755))
+		require.NoError(t, os.WriteFile(path.Join(workingDir, packageName, "pkg", "version", "version.go"), []byte("package version\n\nconst VERSION = \"1.0.0\"\n"), 0644))
+		require.NoError(t, os.WriteFile(path.Join(workingDir, packageName, "go.mod"), []byte(goMods[packageName]), 0644))
+	}
+	testConfig.Set(config.PACKAGR_PACKAGES, []map[string]interface{}{
+		{"name": "cli", "path": "cli", "package_type": "golang"},
+		{"name": "sdk", "path": "sdk", "package_type": "golang"},
+	})
+	testConfig.Set(config.PACKAGR_PACKAGES_CHANGED_ONLY, true)
+
+	repo, err := git.PlainInit(workingDir, false)
+	require.NoError(t, err)
+	return repo, commitAll(t, repo)
+}
+
+func TestPipeline_Run_PackagesBumpDependents(t *testing.T) {
+	//setup
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	workingDir := t.TempDir()
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+	repo, baseSha := setupGolangMonorepo(t, workingDir, testConfig, "")
+	require.NoError(t, os.WriteFile(path.Join(workingDir, "sdk", "client.go"), []byte("package sdk\n"), 0644))
+	headSha := commitAll(t, repo)
+
+	mockScm := mock_scm.NewMockInterface(mockCtrl)
+	mockScm.EXPECT().RetrievePayload().Return(&models.Payload{
+		Base: &pipeline.ScmCommitInfo{Sha: baseSha},
+		Head: &pipeline.ScmCommitInfo{Sha: headSha},
+	}, nil)
+	mockScm.EXPECT().SetOutput("release_version_cli", "1.0.1").Return(nil)
+	mockScm.EXPECT().SetOutput("release_version_sdk", "1.0.1").Return(nil)
+	mockScm.EXPECT().SetOutput(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	//test
+	result, err := new(pkg.Pipeline).Run(workingDir, testConfig, mockScm)
+	require.NoError(t, err)
+
+	//assert
+	require.False(t, result.Packages[0].Skipped, "should bump the unchanged package, because its dependency was bumped")
+	require.Equal(t, "1.0.1", result.Packages[0].NextVersion)
+	require.Equal(t, []string{"sdk"}, result.Packages[0].UpdatedDependencies)
+	require.Equal(t, "dependencies were bumped (sdk)", result.Packages[0].BumpTypeReason)
+	require.Equal(t, []string{"cli/go.mod", "cli/pkg/version/version.go", "sdk/pkg/version/version.go"}, result.ChangedFiles)
+
+	content, err := os.ReadFile(path.Join(workingDir, "cli", "go.mod"))
+	require.NoError(t, err)
+	require.Contains(t, string(content), "require github.com/acme/sdk v1.0.1")
+}
+
+func TestPipeline_Run_PackagesBumpDependents_HelmSubchart(t *testing.T) {
+	//setup
+	workingDir, testConfig, mockScm := setupPipelineTest(t)
+	for chartName, chartYaml := range map[string]string{
+		"api":    "name: acme-api\nversion: 0.4.1\ndependencies:\n  - name: acme-worker\n    version: ^0.2.0\n    repository: file://../worker\n",
+		"worker": "name: acme-worker\nversion: 0.2.3\n",
+	} {
+		require.NoError(t, os.MkdirAll(path.Join(workingDir, "charts", chartName), 0755))
+		require.NoError(t, os.WriteFile(path.Join(workingDir, "charts", chartName, "Chart.yaml"), []byte(chartYaml), 0644))
+	}
+	testConfig.Set(config.PACKAGR_PACKAGES, []map[string]interface{}{
+		{"name": "api", "path": "charts/api", "package_type": "helm", "helm_app_version_source": "chart"},
+		{"name": "worker", "path": "charts/worker", "package_type": "helm"},
+	})
+	mockScm.EXPECT().SetOutput("release_version_worker", "0.3.0").Return(nil)
+	mockScm.EXPECT().SetOutput(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	//test
+	result, err := new(pkg.Pipeline).Run(workingDir, testConfig, mockScm)
+	require.NoError(t, err)
+
+	//assert
+	require.Equal(t, "api", result.Packages[0].Name)
+	require.Equal(t, []string{"worker"}, result.Packages[0].UpdatedDependencies)
+	content, err := os.ReadFile(path.Join(workingDir, "charts", "api", "Chart.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, "name: acme-api\nversion: 0.5.0\nappVersion: \"0.5.0\"\ndependencies:\n  - name: acme-worker\n    version: ^0.3.0\n    repository: file://../worker\n", string(content))
+}
+
+func TestPipeline_Run_PackagesBumpDependents_Cycle(t *testing.T) {
+	//setup
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	workingDir := t.TempDir()
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+	_, headSha := setupGolangMonorepo(t, workingDir, testConfig, "\nrequire github.com/acme/cli v1.0.0\n")
+
+	mockScm := mock_scm.NewMockInterface(mockCtrl)
+	mockScm.EXPECT().RetrievePayload().Return(&models.Payload{
+		Head: &pipeline.ScmCommitInfo{Sha: headSha},
+	}, nil)
+
+	//test
+	_, err = new(pkg.Pipeline).Run(workingDir, testConfig, mockScm)
+
+	//assert
+	var cycleErr *pkg.DependencyCycleError
+	require.True(t, stderrors.As(err, &cycleErr), "should return a DependencyCycleError")
+	require.Equal(t, []string{"cli", "sdk", "cli"}, cycleErr.Packages)
+	require.Contains(t, err.Error(), "cli -> sdk -> cli")
+}
+
+func TestPipeline_Run_GitCommitAndTag(t *testing.T) {
+	//setup
+	workingDir, testConfig, mockScm := setupPipelineTest(t)
+	repo, err := git.PlainInit(workingDir, false)
+	require.NoError(t, err)
+	commitAll(t, repo)
+	require.NoError(t, os.WriteFile(path.Join(workingDir, "notes.txt"), []byte("work in progress"), 0644))
+	testConfig.Set(config.PACKAGR_GIT_COMMIT, true)
+	testConfig.Set(config.PACKAGR_GIT_TAG, true)
+	testConfig.Set(config.PACKAGR_ENGINE_GIT_AUTHOR_NAME, "packagrio-bot")
+	testConfig.Set(config.PACKAGR_ENGINE_GIT_AUTHOR_EMAIL, "bot@example.com")
+	mockScm.EXPECT().SetOutput("release_version", "1.3.0").Return(nil)
+	mockScm.EXPECT().SetOutput(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	//test
+	result, err := new(pkg.Pipeline).Run(workingDir, testConfig, mockScm)
+	require.NoError(t, err)
+
+	//assert
+	require.Equal(t, []string{"v1.3.0"}, result.Tags)
+	head, err := repo.Head()
+	require.NoError(t, err)
+	require.Equal(t, result.CommitSha, head.Hash().String())
+	commit, err := repo.CommitObject(head.Hash())
+	require.NoError(t, err)
+	require.Equal(t, "chore(release): 1.3.0", commit.Message)
+	require.Equal(t, "packagrio-bot", commit.Author.Name)
+	_, ferr := commit.File("notes.txt")
+	require.Error(t, ferr, "should only commit the files changed by bumpr")
+
+	tagRef, err := repo.Tag("v1.3.0")
+	require.NoError(t, err)
+	tagObj, err := repo.TagObject(tagRef.Hash())
+	require.NoError(t, err, "should create an annotated tag")
+	require.Equal(t, head.Hash(), tagObj.Target)
+}
+
+func TestPipeline_Run_GitTagFailure(t *testing.T) {
+	//setup
+	workingDir, testConfig, mockScm := setupPipelineTest(t)
+	repo, err := git.PlainInit(workingDir, false)
+	require.NoError(t, err)
+	headSha := commitAll(t, repo)
+	_, err = repo.CreateTag("v1.3.0", plumbing.NewHash(headSha), nil)
+	require.NoError(t, err)
+	testConfig.Set(config.PACKAGR_GIT_COMMIT, true)
+	testConfig.Set(config.PACKAGR_GIT_TAG, true)
+	testConfig.Set(config.PACKAGR_ENGINE_GIT_AUTHOR_NAME, "packagrio-bot")
+	testConfig.Set(config.PACKAGR_ENGINE_GIT_AUTHOR_EMAIL, "bot@example.com")
+
+	//test
+	_, err = new(pkg.Pipeline).Run(workingDir, testConfig, mockScm)
+
+	//assert
+	var pipelineErr *pkg.PipelineError
+	require.True(t, stderrors.As(err, &pipelineErr))
+	require.Equal(t, pkg.PIPELINE_STEP_GIT_TAG, pipelineErr.Step)
+	require.NotContains(t, err.Error(), "rollback failed")
+	head, err := repo.Head()
+	require.NoError(t, err)
+	require.Equal(t, headSha, head.Hash().String(), "should remove the release commit")
+	content, err := os.ReadFile(path.Join(workingDir, "VERSION"))
+	require.NoError(t, err)
+	require.Equal(t, `version := "1.2.3"`, string(content), "should revert the version changes")
+	workTree, err := repo.Worktree()
+	require.NoError(t, err)
+	status, err := workTree.Status()
+	require.NoError(t, err)
+	require.True(t, status.IsClean(), "should leave the worktree & index unmodified")
+}
+
+func TestPipeline_Run_GitTag_Packages(t *testing.T) {
+	//setup
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	workingDir := t.TempDir()
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+	repo, baseSha := setupMonorepo(t, workingDir, testConfig)
+	require.NoError(t, os.WriteFile(path.Join(workingDir, "sdk", "index.js"), []byte("module.exports = {}"), 0644))
+	headSha := commitAll(t, repo)
+	testConfig.Set(config.PACKAGR_GIT_COMMIT, true)
+	testConfig.Set(config.PACKAGR_GIT_TAG, true)
+	testConfig.Set(config.PACKAGR_ENGINE_GIT_AUTHOR_NAME, "packagrio-bot")
+	testConfig.Set(config.PACKAGR_ENGINE_GIT_AUTHOR_EMAIL, "bot@example.com")
+
+	mockScm := mock_scm.NewMockInterface(mockCtrl)
+	mockScm.EXPECT().RetrievePayload().Return(&models.Payload{
+		Base: &pipeline.ScmCommitInfo{Sha: baseSha},
+		Head: &pipeline.ScmCommitInfo{Sha: headSha},
+	}, nil)
+	mockScm.EXPECT().SetOutput(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	//test
+	result, err := new(pkg.Pipeline).Run(workingDir, testConfig, mockScm)
+	require.NoError(t, err)
+
+	//assert
+	require.Equal(t, []string{"sdk/v1.0.1"}, result.Tags, "should only tag the bumped packages")
+	commit, err := repo.CommitObject(plumbing.NewHash(result.CommitSha))
+	require.NoError(t, err)
+	require.Equal(t, "chore(release): sdk@1.0.1", commit.Message)
+}
+
+func TestPipeline_Run_GitCommit_MissingAuthor(t *testing.T) {
+	//setup
+	workingDir, testConfig, mockScm := setupPipelineTest(t)
+	_, err := git.PlainInit(workingDir, false)
+	require.NoError(t, err)
+	testConfig.Set(config.PACKAGR_GIT_COMMIT, true)
+	testConfig.Set(config.PACKAGR_ENGINE_GIT_AUTHOR_NAME, "")
+	testConfig.Set(config.PACKAGR_ENGINE_GIT_AUTHOR_EMAIL, "")
+
+	//test
+	_, err = new(pkg.Pipeline).Run(workingDir, testConfig, mockScm)
+
+	//assert
+	var pipelineErr *pkg.PipelineError
+	require.True(t, stderrors.As(err, &pipelineErr))
+	require.Equal(t, pkg.PIPELINE_STEP_PARSE_REPO_CONFIG, pipelineErr.Step)
+	content, rerr := os.ReadFile(path.Join(workingDir, "VERSION"))
+	require.NoError(t, rerr)
+	require.Equal(t, `version := "1.2.3"`, string(content), "should not modify files when the commit can't be created")
+}
+
+func TestPipeline_Run_DevelopmentVersion(t *testing.T) {
+	//setup
+	workingDir, testConfig, mockScm := setupPipelineTest(t)
+	require.NoError(t, os.WriteFile(path.Join(workingDir, "pom.xml"), []byte("<project>\n  <artifactId>acme</artifactId>\n  <version>1.2.3-SNAPSHOT</version>\n</project>\n"), 0644))
+	repo, err := git.PlainInit(workingDir, false)
+	require.NoError(t, err)
+	commitAll(t, repo)
+	testConfig.Set(config.PACKAGR_PACKAGE_TYPE, "maven")
+	testConfig.Set(config.PACKAGR_VERSION_BUMP_TYPE, "release")
+	testConfig.Set(config.PACKAGR_MAVEN_NEXT_DEVELOPMENT_VERSION, true)
+	testConfig.Set(config.PACKAGR_GIT_COMMIT, true)
+	testConfig.Set(config.PACKAGR_GIT_TAG, true)
+	testConfig.Set(config.PACKAGR_ENGINE_GIT_AUTHOR_NAME, "packagrio-bot")
+	testConfig.Set(config.PACKAGR_ENGINE_GIT_AUTHOR_EMAIL, "bot@example.com")
+	mockScm.EXPECT().SetOutput("release_version", "1.2.3").Return(nil)
+	mockScm.EXPECT().SetOutput("development_version", "1.2.4-SNAPSHOT").Return(nil)
+	mockScm.EXPECT().SetOutput(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	//test
+	result, err := new(pkg.Pipeline).Run(workingDir, testConfig, mockScm)
+	require.NoError(t, err)
+
+	//assert
+	require.Equal(t, "1.2.4-SNAPSHOT", result.DevelopmentVersion)
+	head, err := repo.Head()
+	require.NoError(t, err)
+	require.Equal(t, result.DevelopmentCommitSha, head.Hash().String())
+	commit, err := repo.CommitObject(head.Hash())
+	require.NoError(t, err)
+	require.Equal(t, "chore(release): prepare for next development iteration 1.2.4-SNAPSHOT", commit.Message)
+	require.Equal(t, result.CommitSha, commit.ParentHashes[0].String(), "should commit after the release commit")
+
+	tagRef, err := repo.Tag("v1.2.3")
+	require.NoError(t, err)
+	tagObj, err := repo.TagObject(tagRef.Hash())
+	require.NoError(t, err)
+	tagged, err := repo.CommitObject(tagObj.Target)
+	require.NoError(t, err)
+	pomFile, err := tagged.File("pom.xml")
+	require.NoError(t, err)
+	pomContent, err := pomFile.Contents()
+	require.NoError(t, err)
+	require.Contains(t, pomContent, "<version>1.2.3</version>", "should tag the release version")
+	content, err := os.ReadFile(path.Join(workingDir, "pom.xml"))
+	require.NoError(t, err)
+	require.Contains(t, string(content), "<version>1.2.4-SNAPSHOT</version>")
+}
+
+func TestPipeline_Run_DevelopmentVersion_DryRun(t *testing.T) {
+	//setup
+	workingDir, testConfig, mockScm := setupPipelineTest(t)
+	require.NoError(t, os.WriteFile(path.Join(workingDir, "pom.xml"), []byte("<project>\n  <artifactId>acme</artifactId>\n  <version>1.2.3-SNAPSHOT</version>\n</project>\n"), 0644))
+	testConfig.Set(config.PACKAGR_PACKAGE_TYPE, "maven")
+	testConfig.Set(config.PACKAGR_VERSION_BUMP_TYPE, "release")
+	testConfig.Set(config.PACKAGR_MAVEN_NEXT_DEVELOPMENT_VERSION, true)
+	testConfig.Set(config.PACKAGR_DRY_RUN, true)
+
+	//test
+	result, err := new(pkg.Pipeline).Run(workingDir, testConfig, mockScm)
+	require.NoError(t, err)
+
+	//assert
+	require.Equal(t, "1.2.4-SNAPSHOT", result.DevelopmentVersion)
+	require.Equal(t, `--- a/pom.xml
++++ b/pom.xml
+@@ -1,4 +1,4 @@
+ <project>
+   <artifactId>acme</artifactId>
+-  <version>1.2.3-SNAPSHOT</version>
++  <version>1.2.3</version>
+ </project>
+--- a/pom.xml
++++ b/pom.xml
+@@ -1,4 +1,4 @@
+ <project>
+   <artifactId>acme</artifactId>
+-  <version>1.2.3</version>
++  <version>1.2.4-SNAPSHOT</version>
+ </project>
+`, result.Diff, "should show the development version change after the release change")
+	content, err := os.ReadFile(path.Join(workingDir, "pom.xml"))
+	require.NoError(t, err)
+	require.Contains(t, string(content), "<version>1.2.3-SNAPSHOT</version>", "should not modify files during a dry run")
+}
+
+func TestPipeline_Run_DevelopmentVersion_Packages(t *testing.T) {
+	//setup
+	workingDir, testConfig, mockScm := setupPipelineTest(t)
+	setupMonorepo(t, workingDir, testConfig)
+	testConfig.Set(config.PACKAGR_PACKAGES_CHANGED_ONLY, false)
+	testConfig.Set(config.PACKAGR_MAVEN_NEXT_DEVELOPMENT_VERSION, true)
+
+	//test
+	_, err := new(pkg.Pipeline).Run(workingDir, testConfig, mockScm)
+
+	//assert
+	var pipelineErr *pkg.PipelineError
+	require.True(t, stderrors.As(err, &pipelineErr), "should not silently ignore the development version")
+	require.Equal(t, pkg.PIPELINE_STEP_PARSE_REPO_CONFIG, pipelineErr.Step)
+	require.Contains(t, err.Error(), "maven_next_development_version")
+}
+
+func TestPipeline_Run_VersionSourceTag(t *testing.T) {
+	//setup
+	workingDir, testConfig, mockScm := setupPipelineTest(t)
+	testConfig.Set(config.PACKAGR_VERSION_SOURCE, "tag")
+	repo, err := git.PlainInit(workingDir, false)
+	require.NoError(t, err)
+	_, err = repo.CreateTag("v1.5.0", plumbing.NewHash(commitAll(t, repo)), nil)
+	require.NoError(t, err)
+	mockScm.EXPECT().SetOutput("previous_version", "1.5.0").Return(nil)
+	mockScm.EXPECT().SetOutput("release_version", "1.6.0").Return(nil)
+	mockScm.EXPECT().SetOutput(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	//test
+	result, err := new(pkg.Pipeline).Run(workingDir, testConfig, mockScm)
+	require.NoError(t, err)
+
+	//assert
+	require.Equal(t, "1.5.0", result.PreviousVersion, "should report the tag version that was bumped, not the file version")
+	require.Equal(t, "1.6.0", result.NextVersion)
+}
+
+func TestPipeline_NextVersion_PackageTags(t *testing.T) {
+	//setup
+	workingDir := t.TempDir()
+	for _, packageName := range []string{"api", "web"} {
+		require.NoError(t, os.MkdirAll(path.Join(workingDir, packageName), 0755))
+		require.NoError(t, os.WriteFile(path.Join(workingDir, packageName, "VERSION"), []byte(`version := "0.0.1"`), 0644))
+	}
+	repo, err := git.PlainInit(workingDir, false)
+	require.NoError(t, err)
+	headSha := commitAll(t, repo)
+	for _, tagName := range []string{"api/v1.2.0", "web/v2.0.0", "v3.0.0"} {
+		_, err = repo.CreateTag(tagName, plumbing.NewHash(headSha), nil)
+		require.NoError(t, err)
+	}
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+	testConfig.Set(config.PACKAGR_VERSION_BUMP_TYPE, "minor")
+	testConfig.Set(config.PACKAGR_VERSION_SOURCE, "tag")
+	testConfig.Set(config.PACKAGR_PACKAGES, []map[string]interface{}{
+		{"name": "api", "path": "api"},
+		{"name": "web", "path": "web"},
+	})
+
+	//test
+	result, err := new(pkg.Pipeline).NextVersion(workingDir, testConfig)
+	require.NoError(t, err)
+
+	//assert
+	require.Equal(t, "1.2.0", result.Packages[0].CurrentVersion, "should use the tags of the package by default")
+	require.Equal(t, "1.3.0", result.Packages[0].NextVersion)
+	require.Equal(t, "2.0.0", result.Packages[1].CurrentVersion)
+	require.Equal(t, "2.1.0", result.Packages[1].NextVersion)
+}
+
+func setupChangelog(t *testing.T, workingDir string, testConfig config.Interface) {
+	repo, err := git.PlainInit(workingDir, false)
+	require.NoError(t, err)
+	workTree, err := repo.Worktree()
+	require.NoError(t, err)
+	tagSha := commitAll(t, repo)
+	_, err = repo.CreateTag("v1.2.3", plumbing.NewHash(tagSha), nil)
+	require.NoError(t, err)
+	for _, message := range []string{"feat(api): add retries", "fix: handle empty responses", "chore: update dependencies"} {
+		_, err = workTree.Commit(message, &git.CommitOptions{
+			Author:            &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+			AllowEmptyCommits: true,
+		})
+		require.NoError(t, err)
+	}
+	testConfig.Set(config.PACKAGR_CHANGELOG, true)
+}
+
+func TestPipeline_Run_Changelog(t *testing.T) {
+	//setup
+	workingDir, testConfig, mockScm := setupPipelineTest(t)
+	setupChangelog(t, workingDir, testConfig)
+	mockScm.EXPECT().SetOutput("release_version", "1.3.0").Return(nil)
+	mockScm.EXPECT().SetOutput(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	//test
+	result, err := new(pkg.Pipeline).Run(workingDir, testConfig, mockScm)
+	require.NoError(t, err)
+
+	//assert
+	require.Equal(t, []string{"CHANGELOG.md", "VERSION"}, result.ChangedFiles)
+	content, err := os.ReadFile(path.Join(workingDir, "CHANGELOG.md"))
+	require.NoError(t, err)
+	require.Contains(t, string(content), "# Changelog\n\n## [1.3.0] - "+time.Now().Format("2006-01-02"))
+	require.Contains(t, string(content), "### Features\n\n- **api:** add retries")
+	require.Contains(t, string(content), "### Bug Fixes\n\n- handle empty responses")
+	require.NotContains(t, string(content), "update dependencies")
+}
+
+func TestPipeline_Run_Changelog_ExistingVersion(t *testing.T) {
+	//setup
+	workingDir, testConfig, mockScm := setupPipelineTest(t)
+	changelogContent := "# Changelog\n\n## [1.3.0] - 2020-05-17\n\n- hand written notes\n"
+	require.NoError(t, os.WriteFile(path.Join(workingDir, "CHANGELOG.md"), []byte(changelogContent), 0644))
+	setupChangelog(t, workingDir, testConfig)
+	testConfig.Set(config.PACKAGR_DRY_RUN, true)
+
+	//test
+	result, err := new(pkg.Pipeline).Run(workingDir, testConfig, mockScm)
+	require.NoError(t, err)
+
+	//assert
+	require.Equal(t, []string{"VERSION"}, result.ChangedFiles, "should not modify a changelog that already contains the version")
+}
+
+func TestPipeline_Run_Changelog_GitTagPrefix(t *testing.T) {
+	//setup
+	workingDir, testConfig, mockScm := setupPipelineTest(t)
+	testConfig.Set(config.PACKAGR_CHANGELOG, true)
+	testConfig.Set(config.PACKAGR_GIT_TAG_PREFIX, "release-")
+	repo, err := git.PlainInit(workingDir, false)
+	require.NoError(t, err)
+	workTree, err := repo.Worktree()
+	require.NoError(t, err)
+	commitAll(t, repo)
+	for _, message := range []string{"feat: released feature", "fix: handle empty responses"} {
+		hash, err := workTree.Commit(message, &git.CommitOptions{
+			Author:            &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+			AllowEmptyCommits: true,
+		})
+		require.NoError(t, err)
+		if message == "feat: released feature" {
+			_, err = repo.CreateTag("release-1.2.3", hash, nil)
+			require.NoError(t, err)
+		}
+	}
+	mockScm.EXPECT().SetOutput(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	//test
+	_, err = new(pkg.Pipeline).Run(workingDir, testConfig, mockScm)
+	require.NoError(t, err)
+
+	//assert
+	content, err := os.ReadFile(path.Join(workingDir, "CHANGELOG.md"))
+	require.NoError(t, err)
+	require.Contains(t, string(content), "### Bug Fixes\n\n- handle empty responses")
+	require.NotContains(t, string(content), "released feature", "should list the commits since the tag with the git_tag_prefix")
+}
+
+func TestPipeline_Run_Changelog_Packages(t *testing.T) {
+	//setup
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	workingDir := t.TempDir()
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+	repo, baseSha := setupMonorepo(t, workingDir, testConfig)
+	testConfig.Set(config.PACKAGR_CHANGELOG, true)
+	for _, tagName := range []string{"cli/v1.0.0", "sdk/v1.0.0"} {
+		_, err = repo.CreateTag(tagName, plumbing.NewHash(baseSha), nil)
+		require.NoError(t, err)
+	}
+	workTree, err := repo.Worktree()
+	require.NoError(t, err)
+	for filePath, message := range map[string]string{"sdk/index.js": "fix(sdk): handle errors", "cli/main.go": "feat(cli): add flag"} {
+		require.NoError(t, os.WriteFile(path.Join(workingDir, filePath), []byte(message), 0644))
+		_, err = workTree.Add(filePath)
+		require.NoError(t, err)
+		_, err = workTree.Commit(message, &git.CommitOptions{
+			Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		})
+		require.NoError(t, err)
+	}
+
+	mockScm := mock_scm.NewMockInterface(mockCtrl)
+	mockScm.EXPECT().RetrievePayload().Return(&models.Payload{}, nil)
+	mockScm.EXPECT().SetOutput(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	//test
+	_, err = new(pkg.Pipeline).Run(workingDir, testConfig, mockScm)
+	require.NoError(t, err)
+
+	//assert
+	content, err := os.ReadFile(path.Join(workingDir, "sdk", "CHANGELOG.md"))
+	require.NoError(t, err)
+	require.Contains(t, string(content), "### Bug Fixes\n\n- **sdk:** handle errors")
+	require.NotContains(t, string(content), "add flag", "should only list the commits changing the package files")
+	content, err = os.ReadFile(path.Join(workingDir, "cli", "CHANGELOG.md"))
+	require.NoError(t, err)
+	require.Contains(t, string(content), "### Features\n\n- **cli:** add flag")
+	require.NotContains(t, string(content), "handle errors")
+}
+
+func TestPipeline_Run_Hooks(t *testing.T) {
+	//setup
+	workingDir, testConfig, mockScm := setupPipelineTest(t)
+	testConfig.Set(config.PACKAGR_HOOKS, map[string]interface{}{
+		"pre_bump":  []interface{}{"echo $BUMPR_CURRENT_VERSION $BUMPR_NEXT_VERSION $BUMPR_BUMP_TYPE > hook.txt; cat VERSION"},
+		"post_bump": []interface{}{map[string]interface{}{"command": "cat VERSION", "timeout": "30s"}},
+	})
+	mockScm.EXPECT().SetOutput("release_version", "1.3.0").Return(nil)
+	mockScm.EXPECT().SetOutput(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	//test
+	result, err := new(pkg.Pipeline).Run(workingDir, testConfig, mockScm)
+	require.NoError(t, err)
+
+	//assert
+	content, err := os.ReadFile(path.Join(workingDir, "hook.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "1.2.3 1.3.0 minor\n", string(content))
+	require.Len(t, result.Hooks, 2)
+	require.Equal(t, `version := "1.2.3"`, result.Hooks[0].Output, "should run pre_bump hooks before writing the version files")
+	require.Equal(t, `version := "1.3.0"`, result.Hooks[1].Output, "should run post_bump hooks after writing the version files")
+}
+
+func TestPipeline_Run_PostBumpHookFailure(t *testing.T) {
+	//setup
+	workingDir, testConfig, mockScm := setupPipelineTest(t)
+	testConfig.Set(config.PACKAGR_HOOKS, map[string]interface{}{
+		"post_bump": []interface{}{"echo client generation failed; exit 1"},
+	})
+
+	//test
+	_, err := new(pkg.Pipeline).Run(workingDir, testConfig, mockScm)
+
+	//assert
+	var pipelineErr *pkg.PipelineError
+	require.True(t, stderrors.As(err, &pipelineErr))
+	require.Equal(t, pkg.PIPELINE_STEP_POST_BUMP_HOOK, pipelineErr.Step)
+	require.Contains(t, err.Error(), "client generation failed")
+	content, rerr := os.ReadFile(path.Join(workingDir, "VERSION"))
+	require.NoError(t, rerr)
+	require.Equal(t, `version := "1.2.3"`, string(content), "should roll back the version changes")
+}
+
+func TestPipeline_Run_PreBumpHookFailure(t *testing.T) {
+	//setup
+	workingDir, testConfig, mockScm := setupPipelineTest(t)
+	testConfig.Set(config.PACKAGR_HOOKS, map[string]interface{}{
+		"pre_bump": []interface{}{"echo lint failed; exit 1"},
+	})
+
+	//test
+	result, err := new(pkg.Pipeline).Run(workingDir, testConfig, mockScm)
+
+	//assert
+	var pipelineErr *pkg.PipelineError
+	require.True(t, stderrors.As(err, &pipelineErr))
+	require.Equal(t, pkg.PIPELINE_STEP_PRE_BUMP_HOOK, pipelineErr.Step)
+	require.NotNil(t, result, "should return the partial result")
+	require.Len(t, result.Hooks, 1)
+	require.Equal(t, "lint failed\n", result.Hooks[0].Output)
+	require.Equal(t, 1, result.Hooks[0].ExitCode)
+}
+
+func TestPipeline_Run_PreBumpHookModifiesVersionFile(t *testing.T) {
+	//setup
+	workingDir, testConfig, mockScm := setupPipelineTest(t)
+	testConfig.Set(config.PACKAGR_HOOKS, map[string]interface{}{
+		"pre_bump": []interface{}{`echo 'version := "1.2.4"' > VERSION`},
+	})
+
+	//test
+	_, err := new(pkg.Pipeline).Run(workingDir, testConfig, mockScm)
+
+	//assert
+	var pipelineErr *pkg.PipelineError
+	require.True(t, stderrors.As(err, &pipelineErr))
+	require.Equal(t, pkg.PIPELINE_STEP_WRITE_FILES, pipelineErr.Step)
+	content, rerr := os.ReadFile(path.Join(workingDir, "VERSION"))
+	require.NoError(t, rerr)
+	require.Equal(t, "version := \"1.2.4\"\n", string(content), "should not silently discard the changes made by the hook")
+}
+
+func TestPipeline_CurrentVersion(t *testing.T) {
+	//setup
+	workingDir := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(workingDir, "VERSION"), []byte(`version := "1.2.3"`), 0644))
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+	testConfig.Set(config.PACKAGR_PACKAGE_TYPE, "auto")
+
+	//test
+	result, err := new(pkg.Pipeline).CurrentVersion(workingDir, testConfig)
+	require.NoError(t, err)
+
+	//assert
+	require.Equal(t, &pkg.VersionResult{PackageType: "auto", Engine: "generic", CurrentVersion: "1.2.3"}, result)
+	content, err := os.ReadFile(path.Join(workingDir, "VERSION"))
+	require.NoError(t, err)
+	require.Equal(t, `version := "1.2.3"`, string(content), "should not modify any file")
+}
+
+func TestPipeline_CurrentVersion_DoesNotCreateDirectories(t *testing.T) {
+	//setup
+	parentPath := t.TempDir()
+	workingDir := path.Join(parentPath, "cli")
+	require.NoError(t, os.MkdirAll(path.Join(workingDir, "pkg", "version"), 0755))
+	require.NoError(t, os.WriteFile(path.Join(workingDir, "pkg", "version", "version.go"), []byte("package version\n\nconst VERSION = \"1.0.0\"\n"), 0644))
+	require.NoError(t, os.WriteFile(path.Join(workingDir, "go.mod"), []byte("module github.com/acme/cli\n\ngo 1.18\n"), 0644))
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+	testConfig.Set(config.PACKAGR_PACKAGE_TYPE, "golang")
+
+	//test
+	result, err := new(pkg.Pipeline).CurrentVersion(workingDir, testConfig)
+	require.NoError(t, err)
+
+	//assert
+	require.Equal(t, "1.0.0", result.CurrentVersion)
+	entries, err := os.ReadDir(parentPath)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "should not create the golang GOPATH directories next to the package")
+}
+
+func TestPipeline_NextVersion(t *testing.T) {
+	//setup
+	workingDir := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(workingDir, "VERSION"), []byte(`version := "1.2.3"`), 0644))
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+	testConfig.Set(config.PACKAGR_VERSION_BUMP_TYPE, "major")
+
+	//test
+	result, err := new(pkg.Pipeline).NextVersion(workingDir, testConfig)
+	require.NoError(t, err)
+
+	//assert
+	require.Equal(t, &pkg.VersionResult{PackageType: "generic", Engine: "generic", CurrentVersion: "1.2.3", NextVersion: "2.0.0", BumpType: "major"}, result)
+	content, err := os.ReadFile(path.Join(workingDir, "VERSION"))
+	require.NoError(t, err)
+	require.Equal(t, `version := "1.2.3"`, string(content), "should not modify any file")
+}
+
+func TestPipeline_NextVersion_Lockstep(t *testing.T) {
+	//setup
+	workingDir := t.TempDir()
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+	testConfig.Set(config.PACKAGR_VERSION_BUMP_TYPE, "minor")
+	testConfig.Set(config.PACKAGR_PACKAGES_SYNC, true)
+	setupLockstep(t, workingDir, testConfig, map[string]string{"cli": "1.4.0", "sdk": "1.3.2"})
+
+	//test
+	result, err := new(pkg.Pipeline).NextVersion(workingDir, testConfig)
+	require.NoError(t, err)
+
+	//assert
+	require.Equal(t, "1.4.0", result.CurrentVersion)
+	require.Equal(t, "1.5.0", result.NextVersion)
+	require.Len(t, result.Packages, 2)
+	require.Equal(t, "sdk", result.Packages[1].Name)
+	require.Equal(t, "1.3.2", result.Packages[1].CurrentVersion, "should report the current version of each package")
+	require.Equal(t, "1.5.0", result.Packages[1].NextVersion)
+}
+
+func setupVerify(t *testing.T) (string, config.Interface) {
+	workingDir := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(workingDir, "VERSION"), []byte(`version := "1.8.2"`), 0644))
+	require.NoError(t, os.WriteFile(path.Join(workingDir, "package.json"), []byte("{\n  \"name\": \"acme\",\n  \"version\": \"1.8.0\"\n}\n"), 0644))
+	require.NoError(t, os.WriteFile(path.Join(workingDir, "version.txt"), []byte(`version := "1.8.2"`), 0644))
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+	testConfig.Set(config.PACKAGR_ADDL_VERSION_METADATA_PATHS, map[string]interface{}{
+		"node":    []interface{}{"package.json"},
+		"generic": []interface{}{"version.txt"},
+	})
+	return workingDir, testConfig
+}
+
+func TestPipeline_Verify(t *testing.T) {
+	//setup
+	workingDir, testConfig := setupVerify(t)
+
+	//test
+	result, err := new(pkg.Pipeline).Verify(workingDir, testConfig, false)
+
+	//assert
+	var mismatchErr *pkg.VersionFileMismatchError
+	require.True(t, stderrors.As(err, &mismatchErr), "should return a VersionFileMismatchError")
+	require.Contains(t, err.Error(), "package.json: 1.8.0 (expected 1.8.2)")
+	require.Equal(t, []*pkg.VerifyFileResult{
+		{Path: "VERSION", Engine: "generic", Version: "1.8.2", Primary: true},
+		{Path: "version.txt", Engine: "generic", Version: "1.8.2"},
+		{Path: "package.json", Engine: "node", Version: "1.8.0", ExpectedVersion: "1.8.2"},
+	}, result.Files)
+	content, err := os.ReadFile(path.Join(workingDir, "package.json"))
+	require.NoError(t, err)
+	require.Contains(t, string(content), `"version": "1.8.0"`, "should not modify any file")
+}
+
+func TestPipeline_Verify_Fix(t *testing.T) {
+	//setup
+	workingDir, testConfig := setupVerify(t)
+
+	//test
+	result, err := new(pkg.Pipeline).Verify(workingDir, testConfig, true)
+
+	//assert
+	require.NoError(t, err)
+	require.True(t, result.Files[2].Fixed)
+	content, err := os.ReadFile(path.Join(workingDir, "package.json"))
+	require.NoError(t, err)
+	require.Equal(t, "{\n  \"name\": \"acme\",\n  \"version\": \"1.8.2\"\n}\n", string(content), "should update the lagging file")
+	content, err = os.ReadFile(path.Join(workingDir, "VERSION"))
+	require.NoError(t, err)
+	require.Equal(t, `version := "1.8.2"`, string(content), "should not modify the primary version file")
+}
+
+func setupInit(t *testing.T) (string, config.Interface) {
+	workingDir := t.TempDir()
+	files := map[string]string{
+		"VERSION":                      `version := "1.8.2"`,
+		"web/package.json":             "{\n  \"name\": \"acme-web\",\n  \"version\": \"1.8.2\"\n}\n",
+		"web/package-lock.json":        "{\n  \"name\": \"acme-web\",\n  \"version\": \"1.8.2\"\n}\n",
+		"version.txt":                  "1.8.2\n",
+		"docs/install.md":              "pip install acme==1.8.2.\n",
+		"docs/changes.md":              "released 1.8.20 and 0.1.8.2\n",
+		"node_modules/x/package.json":  "{\n  \"version\": \"1.8.2\"\n}\n",
+		".github/workflows/release.md": "1.8.2\n",
+	}
+	for filePath, content := range files {
+		require.NoError(t, os.MkdirAll(path.Dir(path.Join(workingDir, filePath)), 0755))
+		require.NoError(t, os.WriteFile(path.Join(workingDir, filePath), []byte(content), 0644))
+	}
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+	testConfig.Set(config.PACKAGR_PACKAGE_TYPE, "auto")
+	return workingDir, testConfig
+}
+
+func TestPipeline_Init(t *testing.T) {
+	//setup
+	workingDir, testConfig := setupInit(t)
+
+	//test
+	result, err := new(pkg.Pipeline).Init(workingDir, testConfig)
+
+	//assert
+	require.NoError(t, err)
+	require.Equal(t, "generic", result.PackageType)
+	require.Equal(t, "found VERSION", result.PackageTypeReason)
+	require.Equal(t, "VERSION", result.VersionMetadataPath)
+	require.Equal(t, "1.8.2", result.CurrentVersion)
+	require.Equal(t, []*pkg.InitVersionFile{
+		{Path: "docs/install.md"},
+		{Path: "version.txt", Engine: "python", Selected: true},
+		{Path: "web/package.json", Engine: "node", Selected: true},
+	}, result.VersionFiles, "should skip hidden & dependency directories, lock files and longer versions")
+	require.NoFileExists(t, path.Join(workingDir, "packagr.yml"))
+}
+
+func TestPipeline_Init_WriteRepoConfig(t *testing.T) {
+	//setup
+	workingDir, testConfig := setupInit(t)
+	result, err := new(pkg.Pipeline).Init(workingDir, testConfig)
+	require.NoError(t, err)
+	result.VersionFiles[1].Selected = false
+
+	//test
+	err = pkg.WriteRepoConfig(workingDir, result, false)
+
+	//assert
+	require.NoError(t, err)
+	writtenConfig, err := config.Create()
+	require.NoError(t, err)
+	require.NoError(t, writtenConfig.ReadConfig(path.Join(workingDir, "packagr.yml")))
+	require.Equal(t, "generic", writtenConfig.GetString(config.PACKAGR_PACKAGE_TYPE))
+	require.Equal(t, "VERSION", writtenConfig.GetString(config.PACKAGR_VERSION_METADATA_PATH))
+	require.Equal(t, map[string]interface{}{"node": []interface{}{"web/package.json"}}, writtenConfig.GetStringMap(config.PACKAGR_ADDL_VERSION_METADATA_PATHS))
+	content, err := os.ReadFile(path.Join(workingDir, "packagr.yml"))
+	require.NoError(t, err)
+	require.Contains(t, string(content), "#   - docs/install.md\n#   - version.txt (python)\n", "should list the files that are not updated")
+
+	_, err = new(pkg.Pipeline).Verify(workingDir, writtenConfig, false)
+	require.NoError(t, err, "the written config should pass verify")
+	require.Error(t, pkg.WriteRepoConfig(workingDir, result, false), "should not overwrite the config file")
+	require.NoError(t, pkg.WriteRepoConfig(workingDir, result, true))
+}
+
+// creates a pull request (base & head commits) that changes the version from baseVersion to headVersion
+func setupCheck(t *testing.T, baseVersion string, headVersion string, headMessage string) (string, config.Interface, *mock_scm.MockInterface) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	workingDir := t.TempDir()
+	repo, err := git.PlainInit(workingDir, false)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path.Join(workingDir, "VERSION"), []byte(`version := "`+baseVersion+`"`), 0644))
+	baseSha := commitAll(t, repo)
+	require.NoError(t, os.WriteFile(path.Join(workingDir, "VERSION"), []byte(`version := "`+headVersion+`"`), 0644))
+	workTree, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = workTree.Add(".")
+	require.NoError(t, err)
+	headHash, err := workTree.Commit(headMessage, &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+	// the worktree is not used to read the versions
+	require.NoError(t, os.WriteFile(path.Join(workingDir, "VERSION"), []byte("not a version"), 0644))
+
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+	mockScm := mock_scm.NewMockInterface(mockCtrl)
+	mockScm.EXPECT().RetrievePayload().Return(&models.Payload{
+		Base: &pipeline.ScmCommitInfo{Sha: baseSha},
+		Head: &pipeline.ScmCommitInfo{Sha: headHash.String()},
+	}, nil)
+	return workingDir, testConfig, mockScm
+}
+
+func TestPipeline_Check(t *testing.T) {
+	//setup
+	workingDir, testConfig, mockScm := setupCheck(t, "1.2.3", "1.3.0", "feat: add endpoint")
+	testConfig.Set(config.PACKAGR_CHECK_BUMP_TYPE, true)
+
+	//test
+	result, err := new(pkg.Pipeline).Check(workingDir, testConfig, mockScm)
+
+	//assert
+	require.NoError(t, err)
+	require.Equal(t, "1.2.3", result.PreviousVersion)
+	require.Equal(t, "1.3.0", result.NextVersion)
+	require.Equal(t, "minor", result.BumpType)
+	require.Equal(t, "minor", result.RequiredBumpType)
+	require.Equal(t, "1 commit(s) require a minor bump", result.RequiredBumpTypeReason)
+}
+
+func TestPipeline_Check_NotBumped(t *testing.T) {
+	//setup
+	workingDir, testConfig, mockScm := setupCheck(t, "1.2.3", "1.2.3", "fix: typo")
+
+	//test
+	result, err := new(pkg.Pipeline).Check(workingDir, testConfig, mockScm)
+
+	//assert
+	var notBumpedErr *pkg.VersionNotBumpedError
+	require.True(t, stderrors.As(err, &notBumpedErr), "should return a VersionNotBumpedError")
+	require.EqualError(t, notBumpedErr, "version was not bumped (1.2.3 -> 1.2.3)")
+	require.Equal(t, "none", result.BumpType)
+}
+
+func TestPipeline_Check_Backwards(t *testing.T) {
+	//setup
+	workingDir, testConfig, mockScm := setupCheck(t, "1.2.3", "1.2.0", "fix: typo")
+
+	//test
+	_, err := new(pkg.Pipeline).Check(workingDir, testConfig, mockScm)
+
+	//assert
+	var notBumpedErr *pkg.VersionNotBumpedError
+	require.True(t, stderrors.As(err, &notBumpedErr), "should return a VersionNotBumpedError")
+	require.EqualError(t, notBumpedErr, "version went backwards (1.2.3 -> 1.2.0)")
+}
+
+func TestPipeline_Check_InsufficientBump(t *testing.T) {
+	//setup
+	workingDir, testConfig, mockScm := setupCheck(t, "1.2.3", "1.2.4", "fix: typo")
+	testConfig.Set(config.PACKAGR_CHECK_BUMP_TYPE, true)
+	testConfig.Set(config.PACKAGR_CHECK_LABELS, []string{"bug", "semver:major"})
+
+	//test
+	result, err := new(pkg.Pipeline).Check(workingDir, testConfig, mockScm)
+
+	//assert
+	var insufficientErr *pkg.InsufficientBumpError
+	require.True(t, stderrors.As(err, &insufficientErr), "should return an InsufficientBumpError")
+	require.Equal(t, "patch", insufficientErr.BumpType)
+	require.Equal(t, "major", insufficientErr.RequiredBumpType)
+	require.Equal(t, "the semver:major label requires a major bump", result.RequiredBumpTypeReason)
+}
+
+func TestPipeline_Check_NewPackage(t *testing.T) {
+	//setup
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	workingDir := t.TempDir()
+	testConfig, err := config.Create()
+	require.NoError(t, err)
+	repo, baseSha := setupMonorepo(t, workingDir, testConfig)
+	require.NoError(t, os.MkdirAll(path.Join(workingDir, "api"), 0755))
+	require.NoError(t, os.WriteFile(path.Join(workingDir, "api", "VERSION"), []byte(`version := "0.1.0"`), 0644))
+	testConfig.Set(config.PACKAGR_PACKAGES, []map[string]interface{}{
+		{"name": "api", "path": "api"},
+		{"name": "cli", "path": "cli"},
+		{"name": "sdk", "path": "sdk"},
+	})
+	headSha := commitAll(t, repo)
+
+	mockScm := mock_scm.NewMockInterface(mockCtrl)
+	mockScm.EXPECT().RetrievePayload().Return(&models.Payload{
+		Base: &pipeline.ScmCommitInfo{Sha: baseSha},
+		Head: &pipeline.ScmCommitInfo{Sha: headSha},
+	}, nil)
+
+	//test
+	result, err := new(pkg.Pipeline).Check(workingDir, testConfig, mockScm)
+
+	//assert
+	require.NoError(t, err, "should not compare the version of a package missing at the base commit")
+	require.True(t, result.Packages[0].NewPackage)
+	require.Equal(t, "", result.Packages[0].PreviousVersion)
+	require.Equal(t, "0.1.0", result.Packages[0].NextVersion)
+	require.True(t, result.Packages[1].Skipped)
+	require.True(t, result.Packages[2].Skipped)
+}
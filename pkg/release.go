@@ -0,0 +1,240 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/packagrio/bumpr/pkg/changeset"
+	"github.com/packagrio/bumpr/pkg/config"
+	"github.com/packagrio/bumpr/pkg/engine"
+	"github.com/packagrio/bumpr/pkg/git"
+	"log"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// releaseMessageData is the data available to the `git_commit_message` template.
+type releaseMessageData struct {
+	// the released version. In monorepo mode (unless lockstep is enabled) the commit message lists the `<name>@<version>`
+	// of every bumped package, and each tag message the version of its package.
+	Version string
+	Tag     string
+
+	// the bumped packages (monorepo mode)
+	Packages []*PackageResult
+}
+
+// gitRelease commits the files changed by the run (`git_commit`) and creates an annotated tag for the released
+// version(s) (`git_tag`). The author is configured via `PACKAGR_ENGINE_GIT_AUTHOR_NAME` & `PACKAGR_ENGINE_GIT_AUTHOR_EMAIL`.
+func (p *Pipeline) gitRelease(result *Result) error {
+	gitCommit := p.Config.GetBool(config.PACKAGR_GIT_COMMIT)
+	gitTag := p.Config.GetBool(config.PACKAGR_GIT_TAG)
+	if !gitCommit && !gitTag {
+		return nil
+	}
+
+	author, messageTemplate, err := p.gitReleaseSettings()
+	if err != nil {
+		return err
+	}
+
+	bumpedPackages := []*PackageResult{}
+	for _, packageResult := range result.Packages {
+		if !packageResult.Skipped {
+			bumpedPackages = append(bumpedPackages, packageResult)
+		}
+	}
+
+	// the release commit, or HEAD when the files are not committed
+	revision := ""
+	if gitCommit {
+		commitData := releaseMessageData{Version: result.NextVersion, Packages: bumpedPackages}
+		if len(result.Packages) > 0 && !p.Config.GetBool(config.PACKAGR_PACKAGES_LOCKSTEP) {
+			packageVersions := []string{}
+			for _, packageResult := range bumpedPackages {
+				packageVersions = append(packageVersions, fmt.Sprintf("%s@%s", packageResult.Name, packageResult.NextVersion))
+			}
+			commitData.Version = strings.Join(packageVersions, ", ")
+		}
+		message, err := renderReleaseMessage(messageTemplate, commitData)
+		if err != nil {
+			return newPipelineError(PIPELINE_STEP_GIT_COMMIT, err)
+		}
+		if revision, err = git.GitCommit(p.Data.GitLocalPath, result.ChangedFiles, message, author); err != nil {
+			return newPipelineError(PIPELINE_STEP_GIT_COMMIT, err)
+		}
+		log.Printf("Committed %d file(s): %.8s %s", len(result.ChangedFiles), revision, message)
+		result.CommitSha = revision
+	}
+
+	if !gitTag {
+		return nil
+	}
+	tags := []releaseMessageData{}
+	if len(result.Packages) == 0 || p.Config.GetBool(config.PACKAGR_PACKAGES_LOCKSTEP) {
+		tags = append(tags, releaseMessageData{Version: result.NextVersion, Tag: p.releaseTagPrefix("") + result.NextVersion, Packages: bumpedPackages})
+	} else {
+		for _, packageResult := range bumpedPackages {
+			tags = append(tags, releaseMessageData{
+				Version:  packageResult.NextVersion,
+				Tag:      p.releaseTagPrefix(packageResult.Name) + packageResult.NextVersion,
+				Packages: []*PackageResult{packageResult},
+			})
+		}
+	}
+	for _, tagData := range tags {
+		message, err := renderReleaseMessage(messageTemplate, tagData)
+		if err != nil {
+			return newPipelineError(PIPELINE_STEP_GIT_TAG, err)
+		}
+		if err := git.GitCreateTag(p.Data.GitLocalPath, revision, tagData.Tag, message, author); err != nil {
+			return newPipelineError(PIPELINE_STEP_GIT_TAG, fmt.Errorf("could not create tag %s: %s", tagData.Tag, err))
+		}
+		log.Printf("Created tag %s", tagData.Tag)
+		result.Tags = append(result.Tags, tagData.Tag)
+	}
+	return nil
+}
+
+// releaseTagPrefix returns the prefix of the release tags: the `git_tag_prefix`, or `<name>/<git_tag_prefix>` for the
+// packages released independently. The package name is empty in single package mode.
+func (p *Pipeline) releaseTagPrefix(packageName string) string {
+	tagPrefix := p.Config.GetString(config.PACKAGR_GIT_TAG_PREFIX)
+	if packageName == "" || p.Config.GetBool(config.PACKAGR_PACKAGES_LOCKSTEP) {
+		return tagPrefix
+	}
+	return fmt.Sprintf("%s/%s", packageName, tagPrefix)
+}
+
+// setDevelopmentVersion sets the development version that follows the release (eg. `1.2.4-SNAPSHOT` after `1.2.3`)
+// once the release is committed & tagged, for engines that implement engine.DevelopmentVersionManager. The change is
+// committed separately when `git_commit` is enabled.
+func (p *Pipeline) setDevelopmentVersion(result *Result) error {
+	changeSet, developmentVersion, err := p.stageDevelopmentVersion(nil, result.NextVersion)
+	if err != nil {
+		return err
+	} else if developmentVersion == "" {
+		return nil
+	}
+	if err := changeSet.Commit(); err != nil {
+		return newPipelineError(PIPELINE_STEP_WRITE_FILES, err)
+	}
+	log.Printf("Set the development version %s", developmentVersion)
+	result.DevelopmentVersion = developmentVersion
+
+	if !p.Config.GetBool(config.PACKAGR_GIT_COMMIT) {
+		return nil
+	}
+	author, _, err := p.gitReleaseSettings()
+	if err != nil {
+		return err
+	}
+	changedFiles := []string{}
+	for _, change := range changeSet.Changes() {
+		changedFiles = append(changedFiles, p.relativePath(change.Path))
+	}
+	message := fmt.Sprintf("chore(release): prepare for next development iteration %s", developmentVersion)
+	if result.DevelopmentCommitSha, err = git.GitCommit(p.Data.GitLocalPath, changedFiles, message, author); err != nil {
+		// the development version is reverted, the release itself is already committed & tagged.
+		result.DevelopmentVersion = ""
+		return p.rollbackRelease(nil, changeSet, newPipelineError(PIPELINE_STEP_GIT_COMMIT, err))
+	}
+	log.Printf("Committed %d file(s): %.8s %s", len(changedFiles), result.DevelopmentCommitSha, message)
+	return nil
+}
+
+// stageDevelopmentVersion stages the development version in a new change set, on top of base when set (the release
+// changes of a dry run). An empty version is returned when the engine doesn't set a development version.
+func (p *Pipeline) stageDevelopmentVersion(base *changeset.ChangeSet, releaseVersion string) (*changeset.ChangeSet, string, error) {
+	developmentVersionManager, ok := p.Engine.(engine.DevelopmentVersionManager)
+	if !ok {
+		return nil, "", nil
+	}
+	changeSet := changeset.New()
+	if base != nil {
+		changeSet = changeset.NewOverlay(base)
+	}
+	p.Engine.SetChangeSet(changeSet)
+	developmentVersion, err := developmentVersionManager.SetDevelopmentVersion(releaseVersion)
+	if err != nil {
+		return nil, "", newPipelineError(PIPELINE_STEP_DEVELOPMENT_VERSION, err)
+	}
+	return changeSet, developmentVersion, nil
+}
+
+// rollbackRelease reverts the committed changes of the change set, after deleting the tags & the commit created by
+// gitRelease (when result is set), so that the release can be retried. Returns the original error.
+func (p *Pipeline) rollbackRelease(result *Result, changeSet *changeset.ChangeSet, err error) error {
+	var rerr error
+	if result != nil {
+		rerr = p.undoGitRelease(result)
+	}
+	if rerr == nil {
+		rerr = changeSet.Revert()
+	}
+	if rerr != nil {
+		var pipelineErr *PipelineError
+		if errors.As(err, &pipelineErr) {
+			return newPipelineError(pipelineErr.Step, fmt.Errorf("%s (rollback failed: %s)", errors.Unwrap(err), rerr))
+		}
+		return fmt.Errorf("%s (rollback failed: %s)", err, rerr)
+	}
+	return err
+}
+
+// undoGitRelease deletes the tags & the release commit created by gitRelease.
+func (p *Pipeline) undoGitRelease(result *Result) error {
+	for _, tag := range result.Tags {
+		if err := git.GitDeleteTag(p.Data.GitLocalPath, tag); err != nil {
+			return err
+		}
+	}
+	result.Tags = nil
+	if result.CommitSha != "" {
+		if err := git.GitUndoCommit(p.Data.GitLocalPath, result.CommitSha); err != nil {
+			return err
+		}
+		result.CommitSha = ""
+	}
+	return nil
+}
+
+// checkGitRelease validates the git release settings, before any files are written, so that an invalid configuration
+// never leaves the repository bumped but not committed.
+func (p *Pipeline) checkGitRelease(result *Result) error {
+	gitCommit := p.Config.GetBool(config.PACKAGR_GIT_COMMIT)
+	gitTag := p.Config.GetBool(config.PACKAGR_GIT_TAG)
+	if !gitCommit && !gitTag {
+		return nil
+	}
+	if _, _, err := p.gitReleaseSettings(); err != nil {
+		return err
+	}
+	if !gitCommit && len(result.ChangedFiles) > 0 {
+		return newPipelineError(PIPELINE_STEP_PARSE_REPO_CONFIG, errors.New("`git_tag` requires `git_commit` when files are modified, the tag would not include the version changes"))
+	}
+	return nil
+}
+
+// gitReleaseSettings returns the git author & the parsed message template.
+func (p *Pipeline) gitReleaseSettings() (*object.Signature, *template.Template, error) {
+	name := p.Config.GetString(config.PACKAGR_ENGINE_GIT_AUTHOR_NAME)
+	email := p.Config.GetString(config.PACKAGR_ENGINE_GIT_AUTHOR_EMAIL)
+	if name == "" || email == "" {
+		return nil, nil, newPipelineError(PIPELINE_STEP_PARSE_REPO_CONFIG, errors.New("the git author is required to commit or tag, set PACKAGR_ENGINE_GIT_AUTHOR_NAME and PACKAGR_ENGINE_GIT_AUTHOR_EMAIL"))
+	}
+	messageTemplate, err := template.New("git_commit_message").Parse(p.Config.GetString(config.PACKAGR_GIT_COMMIT_MESSAGE))
+	if err != nil {
+		return nil, nil, newPipelineError(PIPELINE_STEP_PARSE_REPO_CONFIG, err)
+	}
+	return &object.Signature{Name: name, Email: email, When: time.Now()}, messageTemplate, nil
+}
+
+func renderReleaseMessage(messageTemplate *template.Template, data releaseMessageData) (string, error) {
+	var message strings.Builder
+	if err := messageTemplate.Execute(&message, data); err != nil {
+		return "", err
+	}
+	return message.String(), nil
+}
@@ -0,0 +1,71 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// printResult prints a human readable summary of the run.
+func printResult(out io.Writer, result *Result) {
+	if result.Skipped && len(result.Packages) == 0 {
+		fmt.Fprintln(out, "no commits require a version bump, skipping")
+		return
+	}
+
+	if len(result.Packages) == 0 {
+		if result.DryRun {
+			fmt.Fprintf(out, "dry run: version would be bumped from %s to %s\n", result.PreviousVersion, result.NextVersion)
+		} else {
+			fmt.Fprintf(out, "version bumped to %s\n", result.NextVersion)
+		}
+	}
+	for _, packageResult := range result.Packages {
+		switch {
+		case packageResult.Skipped:
+			fmt.Fprintf(out, "%s: %s, keeping version %s\n", packageResult.Name, packageResult.SkipReason, packageResult.NextVersion)
+		case result.DryRun:
+			fmt.Fprintf(out, "dry run: %s version would be bumped from %s to %s\n", packageResult.Name, packageResult.PreviousVersion, packageResult.NextVersion)
+		default:
+			fmt.Fprintf(out, "%s: version bumped to %s\n", packageResult.Name, packageResult.NextVersion)
+		}
+	}
+	if result.CommitSha != "" {
+		fmt.Fprintf(out, "committed version changes: %s\n", result.CommitSha)
+	}
+	for _, tag := range result.Tags {
+		fmt.Fprintf(out, "created tag: %s\n", tag)
+	}
+	if result.DryRun {
+		fmt.Fprint(out, result.Diff)
+	}
+}
+
+// printReport prints the JSON run report.
+func printReport(out io.Writer, result *Result) error {
+	report, err := marshalReport(result)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(report)
+	return err
+}
+
+// writeReport writes the JSON run report to a file (`report_path`), relative paths are resolved from the current working
+// directory.
+func writeReport(reportPath string, result *Result) error {
+	report, err := marshalReport(result)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(reportPath, report, 0644)
+}
+
+func marshalReport(result *Result) ([]byte, error) {
+	report, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(report, '\n'), nil
+}
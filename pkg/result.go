@@ -0,0 +1,171 @@
+package pkg
+
+import (
+	"github.com/packagrio/bumpr/pkg/hooks"
+	"time"
+)
+
+// Result describes the outcome of a Pipeline run. It is also the JSON run report (`--report`, `--output json`).
+type Result struct {
+	// the configured package type (eg. `auto`), and the engine that bumped the version
+	PackageType     string `json:"package_type"`
+	Engine          string `json:"engine"`
+	PreviousVersion string `json:"previous_version"`
+	NextVersion     string `json:"next_version"`
+	BumpType        string `json:"bump_type"`
+	// why the bump type was selected, eg. configured, or determined from the commits
+	BumpTypeReason string `json:"bump_type_reason"`
+
+	// files modified (or that would be modified during a dry run), relative to the working directory
+	ChangedFiles []string      `json:"changed_files"`
+	Files        []*FileResult `json:"files"`
+
+	// outputs set on the SCM, eg. `release_version`
+	Outputs map[string]string `json:"outputs"`
+
+	// the result of each package, only populated in monorepo mode (`packages`)
+	Packages []*PackageResult `json:"packages,omitempty"`
+
+	// true when no commits required a version bump (`version_bump_type: auto`), or every package was skipped (monorepo mode)
+	Skipped bool `json:"skipped"`
+
+	// the output of the pre_bump & post_bump hooks
+	Hooks []*hooks.Result `json:"hooks,omitempty"`
+
+	// the release commit & annotated tags created by `git_commit` & `git_tag`
+	CommitSha string   `json:"commit_sha,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+
+	// the development version set after the release (eg. the maven `-SNAPSHOT` version), and its commit
+	DevelopmentVersion   string `json:"development_version,omitempty"`
+	DevelopmentCommitSha string `json:"development_commit_sha,omitempty"`
+
+	// true when the run was a dry run, Diff contains a unified diff of the changes that would have been written. The
+	// development version changes follow the release changes, as a separate diff of the same files.
+	DryRun bool   `json:"dry_run"`
+	Diff   string `json:"diff,omitempty"`
+
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration_ns"`
+}
+
+// FileResult describes a modified file, the hashes are the hex encoded sha256 of the file content (the before hash is
+// empty if the file was created).
+type FileResult struct {
+	Path         string `json:"path"`
+	BeforeSha256 string `json:"before_sha256"`
+	AfterSha256  string `json:"after_sha256"`
+}
+
+// PackageResult describes the outcome of a single package bump in monorepo mode.
+type PackageResult struct {
+	Name            string `json:"name"`
+	Path            string `json:"path"`
+	PackageType     string `json:"package_type"`
+	Engine          string `json:"engine"`
+	PreviousVersion string `json:"previous_version"`
+	NextVersion     string `json:"next_version"`
+	BumpType        string `json:"bump_type"`
+	BumpTypeReason  string `json:"bump_type_reason"`
+
+	// true when the package was not bumped (NextVersion is the current version)
+	Skipped    bool   `json:"skipped"`
+	SkipReason string `json:"skip_reason,omitempty"`
+
+	// the internal packages this package depends on that were bumped, their new version was set in the package files
+	UpdatedDependencies []string `json:"updated_dependencies,omitempty"`
+}
+
+// VersionResult describes the current version of a package (`bumpr current`), and the version it would be bumped to
+// (`bumpr next`). In monorepo mode the version of each package is listed in Packages.
+type VersionResult struct {
+	Name           string `json:"name,omitempty"`
+	Path           string `json:"path,omitempty"`
+	PackageType    string `json:"package_type,omitempty"`
+	Engine         string `json:"engine,omitempty"`
+	CurrentVersion string `json:"current_version,omitempty"`
+
+	// only populated by NextVersion
+	NextVersion string `json:"next_version,omitempty"`
+	BumpType    string `json:"bump_type,omitempty"`
+
+	Packages []*VersionResult `json:"packages,omitempty"`
+}
+
+// VerifyResult describes the version of every version file, the primary version file of each package and its
+// `addl_version_metadata_paths` (`bumpr verify`).
+type VerifyResult struct {
+	Files []*VerifyFileResult `json:"files"`
+}
+
+// VerifyFileResult describes the version of a single version file.
+type VerifyFileResult struct {
+	// the package name, only populated in monorepo mode (`packages`)
+	Package string `json:"package,omitempty"`
+	// relative to the working directory
+	Path    string `json:"path"`
+	Engine  string `json:"engine"`
+	Version string `json:"version"`
+	Primary bool   `json:"primary"`
+
+	// the version of the primary version file, when the versions differ
+	ExpectedVersion string `json:"expected_version,omitempty"`
+	// true when the file was rewritten to the expected version (`--fix`)
+	Fixed bool `json:"fixed,omitempty"`
+}
+
+// CheckResult describes the version change between the base & head commits of a pull request (`bumpr check`). In
+// monorepo mode the result of each package is listed in Packages.
+type CheckResult struct {
+	Name        string `json:"name,omitempty"`
+	Path        string `json:"path,omitempty"`
+	PackageType string `json:"package_type,omitempty"`
+	BaseSha     string `json:"base_sha,omitempty"`
+	HeadSha     string `json:"head_sha,omitempty"`
+
+	PreviousVersion string `json:"previous_version,omitempty"`
+	NextVersion     string `json:"next_version,omitempty"`
+	// the size of the version change: major, minor, patch, prerelease or none
+	BumpType string `json:"bump_type,omitempty"`
+
+	// the bump type required by the commits & labels, only populated when `check_bump_type` is enabled
+	RequiredBumpType       string `json:"required_bump_type,omitempty"`
+	RequiredBumpTypeReason string `json:"required_bump_type_reason,omitempty"`
+
+	// true when the package was not checked, because none of its files changed (`packages_changed_only`)
+	Skipped bool `json:"skipped,omitempty"`
+	// true when the package does not exist at the base commit, the version of a new package is not compared
+	NewPackage bool `json:"new_package,omitempty"`
+
+	Packages []*CheckResult `json:"packages,omitempty"`
+}
+
+// InitResult describes the packagr.yml proposed for a repository (`bumpr init`).
+type InitResult struct {
+	// the repo config file path (`engine_repo_config_path`), relative to the working directory
+	ConfigPath string `json:"config_path"`
+
+	PackageType string `json:"package_type"`
+	// why the package type was selected, eg. the marker file found by the detection
+	PackageTypeReason string `json:"package_type_reason"`
+	// the other package types detected in the repository, in priority order
+	Candidates []string `json:"candidates,omitempty"`
+
+	// empty when the engine does not use `version_metadata_path` (eg. node reads the package.json file)
+	VersionMetadataPath string `json:"version_metadata_path,omitempty"`
+	// empty when the current version could not be read, no version files are proposed in that case
+	CurrentVersion string `json:"current_version,omitempty"`
+
+	// the other files that contain the current version
+	VersionFiles []*InitVersionFile `json:"version_files"`
+}
+
+// InitVersionFile describes a file that contains the current version.
+type InitVersionFile struct {
+	// relative to the working directory
+	Path string `json:"path"`
+	// the engine that reads & writes the version of the file, empty if no engine can update the file
+	Engine string `json:"engine,omitempty"`
+	// true when the file is added to `addl_version_metadata_paths`
+	Selected bool `json:"selected"`
+}
@@ -0,0 +1,164 @@
+package pkg
+
+import (
+	"fmt"
+	"github.com/Masterminds/semver"
+	"github.com/packagrio/bumpr/pkg/changeset"
+	"github.com/packagrio/bumpr/pkg/config"
+	"github.com/packagrio/bumpr/pkg/conventional"
+	"github.com/packagrio/bumpr/pkg/engine"
+	"github.com/packagrio/go-common/pipeline"
+	"log"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+// Verify checks that every `addl_version_metadata_paths` file has the same version as the primary version file of its
+// package (or of every package in monorepo mode). The versions are read by the engines, no SCM payload is required.
+// Returns a *VersionFileMismatchError (wrapped in a *PipelineError) if any version differs, unless fix is enabled, in
+// which case the lagging files are rewritten to the primary version.
+func (p *Pipeline) Verify(workingDir string, configData config.Interface, fix bool) (*VerifyResult, error) {
+	p.init(workingDir, configData)
+	if err := p.ParseRepoConfig(); err != nil {
+		return nil, newPipelineError(PIPELINE_STEP_PARSE_REPO_CONFIG, err)
+	}
+	p.Config.Set(config.PACKAGR_VERSION_BUMP_TYPE, conventional.BUMP_TYPE_NONE)
+
+	packages, err := p.LoadPackages()
+	if err != nil {
+		return nil, newPipelineError(PIPELINE_STEP_PARSE_REPO_CONFIG, err)
+	}
+
+	// fixes are only written once every file has been read successfully
+	changeSet := changeset.New()
+	result := &VerifyResult{Files: []*VerifyFileResult{}}
+	if len(packages) == 0 {
+		if err := p.verifyPackage("", p.Data, p.Config, changeSet, fix, result); err != nil {
+			return nil, err
+		}
+	} else {
+		packagesData, packagesSettings, err := p.packagesSettings(packages)
+		if err != nil {
+			return nil, err
+		}
+		for ndx, packageConfig := range packages {
+			if err := p.verifyPackage(packageConfig.Name, packagesData[ndx], packagesSettings[ndx], changeSet, fix, result); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := changeSet.Commit(); err != nil {
+		return nil, newPipelineError(PIPELINE_STEP_WRITE_FILES, err)
+	}
+
+	mismatchErr := &VersionFileMismatchError{}
+	for _, fileResult := range result.Files {
+		if fileResult.ExpectedVersion != "" && !fileResult.Fixed {
+			mismatchErr.Files = append(mismatchErr.Files, fileResult)
+		}
+	}
+	if len(mismatchErr.Files) > 0 {
+		return result, newPipelineError(PIPELINE_STEP_VERIFY, mismatchErr)
+	}
+	return result, nil
+}
+
+// verifyPackage reads the version of the primary version file & every additional version file of a package.
+func (p *Pipeline) verifyPackage(packageName string, packageData *pipeline.Data, packageConfig config.Interface, changeSet *changeset.ChangeSet, fix bool, result *VerifyResult) error {
+	readConfig, err := config.Clone(packageConfig, nil)
+	if err != nil {
+		return newPipelineError(PIPELINE_STEP_PARSE_REPO_CONFIG, err)
+	}
+	readData, cleanup, err := readOnlyData(packageData)
+	if err != nil {
+		return newPipelineError(PIPELINE_STEP_CREATE_ENGINE, err)
+	}
+	defer cleanup()
+
+	// the version is "bumped" to the current version, in a change set that is discarded.
+	primaryEngine, err := p.newEngine(readData, readConfig, changeset.New())
+	if err != nil {
+		return err
+	}
+	if err := primaryEngine.BumpVersion(); err != nil {
+		return newPipelineError(PIPELINE_STEP_BUMP_VERSION, err)
+	}
+	primaryVersion := primaryEngine.GetCurrentVersion()
+	result.Files = append(result.Files, &VerifyFileResult{
+		Package: packageName,
+		Path:    p.relativePath(path.Join(readData.GitLocalPath, readConfig.GetString(config.PACKAGR_VERSION_METADATA_PATH))),
+		Engine:  readConfig.GetString(config.PACKAGR_PACKAGE_TYPE),
+		Version: primaryVersion,
+		Primary: true,
+	})
+
+	addlMetadataPaths := readConfig.GetStringMap(config.PACKAGR_ADDL_VERSION_METADATA_PATHS)
+	engineTypes := []string{}
+	for engineType := range addlMetadataPaths {
+		engineTypes = append(engineTypes, engineType)
+	}
+	sort.Strings(engineTypes)
+
+	for _, engineType := range engineTypes {
+		addlMetadataEngine, err := engine.Create(engineType, readData, readConfig, p.Scm)
+		if err != nil {
+			return newPipelineError(PIPELINE_STEP_CREATE_ENGINE, err)
+		}
+		addlMetadataEngine.SetChangeSet(changeSet)
+		versionReader, ok := addlMetadataEngine.(engine.VersionReader)
+		if !ok {
+			return newPipelineError(PIPELINE_STEP_VERIFY, fmt.Errorf("the %s engine can't read versions from additional version files", engineType))
+		}
+
+		for _, metadataPath := range addlMetadataPaths[engineType].([]interface{}) {
+			metadataPathStr := path.Join(readData.GitLocalPath, metadataPath.(string))
+			version, err := versionReader.GetVersion(metadataPathStr)
+			if err != nil {
+				return newPipelineError(PIPELINE_STEP_VERIFY, fmt.Errorf("could not read the version of %s: %s", metadataPathStr, err))
+			}
+			fileResult := &VerifyFileResult{
+				Package: packageName,
+				Path:    p.relativePath(metadataPathStr),
+				Engine:  engineType,
+				Version: version,
+			}
+			if !versionsEqual(version, primaryVersion) {
+				fileResult.ExpectedVersion = primaryVersion
+				if fix {
+					log.Printf("Updating %s from %s to %s", fileResult.Path, version, primaryVersion)
+					if err := addlMetadataEngine.SetVersion(metadataPathStr, primaryVersion); err != nil {
+						return newPipelineError(PIPELINE_STEP_SET_VERSION, err)
+					}
+					fileResult.Fixed = true
+				}
+			}
+			result.Files = append(result.Files, fileResult)
+		}
+	}
+	return nil
+}
+
+// relativePath returns the path relative to the working directory, in slash form.
+func (p *Pipeline) relativePath(filePath string) string {
+	relPath, err := filepath.Rel(p.Data.GitLocalPath, filePath)
+	if err != nil {
+		relPath = filePath
+	}
+	return filepath.ToSlash(relPath)
+}
+
+// versionsEqual compares two versions by SemVer precedence (eg. `v1.2.3` equals `1.2.3`), falling back to a string
+// comparison if either version is invalid.
+func versionsEqual(version string, otherVersion string) bool {
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return version == otherVersion
+	}
+	otherV, err := semver.NewVersion(otherVersion)
+	if err != nil {
+		return version == otherVersion
+	}
+	return v.Equal(otherV)
+}
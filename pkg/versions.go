@@ -0,0 +1,124 @@
+package pkg
+
+import (
+	"github.com/packagrio/bumpr/pkg/changeset"
+	"github.com/packagrio/bumpr/pkg/config"
+	"github.com/packagrio/bumpr/pkg/conventional"
+	"github.com/packagrio/go-common/pipeline"
+	"os"
+)
+
+// CurrentVersion reads the current version of the package in workingDir (or of every package in monorepo mode). No SCM
+// payload is required, the engine tools are not validated, and no files are modified.
+func (p *Pipeline) CurrentVersion(workingDir string, configData config.Interface) (*VersionResult, error) {
+	return p.versions(workingDir, configData, false)
+}
+
+// NextVersion reads the current version of the package in workingDir (or of every package in monorepo mode), and
+// generates the version it would be bumped to with the configured bump type. When the bump type is `auto`, the commits
+// since the latest semver tag are classified. Like CurrentVersion, no files are modified.
+func (p *Pipeline) NextVersion(workingDir string, configData config.Interface) (*VersionResult, error) {
+	return p.versions(workingDir, configData, true)
+}
+
+func (p *Pipeline) versions(workingDir string, configData config.Interface, next bool) (*VersionResult, error) {
+	p.init(workingDir, configData)
+	if err := p.ParseRepoConfig(); err != nil {
+		return nil, newPipelineError(PIPELINE_STEP_PARSE_REPO_CONFIG, err)
+	}
+	if !next {
+		p.Config.Set(config.PACKAGR_VERSION_BUMP_TYPE, conventional.BUMP_TYPE_NONE)
+	}
+
+	packages, err := p.LoadPackages()
+	if err != nil {
+		return nil, newPipelineError(PIPELINE_STEP_PARSE_REPO_CONFIG, err)
+	}
+	if len(packages) == 0 {
+		return p.readVersion(p.Data, p.Config, next)
+	}
+
+	packagesData, packagesSettings, err := p.packagesSettings(packages)
+	if err != nil {
+		return nil, err
+	}
+	result := &VersionResult{}
+	// in lockstep mode every package is bumped to the same next version, the current version of each package is still
+	// reported as is.
+	lockstep := next && p.Config.GetBool(config.PACKAGR_PACKAGES_LOCKSTEP)
+	if lockstep {
+		skipReasons := map[string]string{}
+		if result.CurrentVersion, _, err = p.prepareLockstep(packages, packagesData, packagesSettings, skipReasons); err != nil {
+			return nil, err
+		}
+		if len(skipReasons) > 0 {
+			for _, packageSettings := range packagesSettings {
+				packageSettings.Set(config.PACKAGR_VERSION_BUMP_TYPE, conventional.BUMP_TYPE_NONE)
+			}
+		}
+	}
+	for ndx, packageConfig := range packages {
+		packageResult, err := p.readVersion(packagesData[ndx], packagesSettings[ndx], next)
+		if err != nil {
+			return nil, err
+		}
+		packageResult.Name = packageConfig.Name
+		packageResult.Path = packageConfig.Path
+		result.Packages = append(result.Packages, packageResult)
+		if lockstep {
+			result.NextVersion = packageResult.NextVersion
+			result.BumpType = packageResult.BumpType
+		}
+	}
+	return result, nil
+}
+
+// readVersion "bumps" the package version in a change set that is discarded, and returns the current & next versions.
+func (p *Pipeline) readVersion(packageData *pipeline.Data, packageConfig config.Interface, next bool) (*VersionResult, error) {
+	readConfig, err := config.Clone(packageConfig, nil)
+	if err != nil {
+		return nil, newPipelineError(PIPELINE_STEP_PARSE_REPO_CONFIG, err)
+	}
+	readData, cleanup, err := readOnlyData(packageData)
+	if err != nil {
+		return nil, newPipelineError(PIPELINE_STEP_CREATE_ENGINE, err)
+	}
+	defer cleanup()
+	result := &VersionResult{PackageType: readConfig.GetString(config.PACKAGR_PACKAGE_TYPE)}
+
+	if readConfig.GetString(config.PACKAGR_VERSION_BUMP_TYPE) == "auto" {
+		bumpTypeResult, err := p.determineBumpType(readData, readConfig)
+		if err != nil {
+			return nil, newPipelineError(PIPELINE_STEP_DETERMINE_BUMP_TYPE, err)
+		}
+		readConfig.Set(config.PACKAGR_VERSION_BUMP_TYPE, bumpTypeResult.BumpType)
+	}
+
+	readEngine, err := p.newEngine(readData, readConfig, changeset.New())
+	if err != nil {
+		return nil, err
+	}
+	result.Engine = readConfig.GetString(config.PACKAGR_PACKAGE_TYPE)
+	if err := readEngine.BumpVersion(); err != nil {
+		return nil, newPipelineError(PIPELINE_STEP_BUMP_VERSION, err)
+	}
+	result.CurrentVersion = previousVersion(readEngine, readConfig)
+	if next {
+		result.NextVersion = readData.ReleaseVersion
+		result.BumpType = readConfig.GetString(config.PACKAGR_VERSION_BUMP_TYPE)
+	}
+	return result, nil
+}
+
+// readOnlyData returns a copy of the package data, for engines that only read the version. Engines may create
+// directories next to the package during Init (eg. the golang GOPATH), so the parent path is replaced by a temporary
+// directory, which is removed by the returned cleanup function.
+func readOnlyData(packageData *pipeline.Data) (*pipeline.Data, func(), error) {
+	tempPath, err := os.MkdirTemp("", "bumpr-read")
+	if err != nil {
+		return nil, nil, err
+	}
+	readData := *packageData
+	readData.GitParentPath = tempPath
+	return &readData, func() { os.RemoveAll(tempPath) }, nil
+}